@@ -0,0 +1,33 @@
+package main
+
+import (
+	// Stdlib
+	"log"
+	"time"
+
+	// Vendor
+	"github.com/kelseyhightower/envconfig"
+)
+
+type Config struct {
+	// ShutdownTimeoutString specifies how long to wait for the requests
+	// and the deliveries being processed when shutting down.
+	ShutdownTimeoutString string `envconfig:"SHUTDOWN_TIMEOUT" default:"30s"`
+
+	// ShutdownTimeout contains the parsed duration string.
+	ShutdownTimeout time.Duration
+}
+
+var config Config
+
+func init() {
+	if err := envconfig.Process("SFD", &config); err != nil {
+		log.Fatalln("Fatal error while parsing daemon config:", err)
+	}
+
+	v, err := time.ParseDuration(config.ShutdownTimeoutString)
+	if err != nil {
+		log.Fatalln("Fatal error while parsing daemon config:", err)
+	}
+	config.ShutdownTimeout = v
+}
@@ -0,0 +1,27 @@
+package admin
+
+import (
+	// Stdlib
+	"log"
+
+	// Vendor
+	"github.com/kelseyhightower/envconfig"
+)
+
+type Config struct {
+	// Token is the bearer token required to access the admin API.
+	// The admin API is disabled unless the token is set.
+	Token string `envconfig:"ADMIN_TOKEN"`
+}
+
+var config Config
+
+func init() {
+	if err := envconfig.Process("SFD", &config); err != nil {
+		log.Fatalln("Fatal error while parsing admin config:", err)
+	}
+}
+
+func GetConfig() Config {
+	return config
+}
@@ -0,0 +1,255 @@
+package admin
+
+import (
+	// Stdlib
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	// Internal
+	httputil "github.com/salsaflow/salsaflow-daemon/internal/http"
+	"github.com/salsaflow/salsaflow-daemon/internal/log"
+	"github.com/salsaflow/salsaflow-daemon/internal/queue"
+)
+
+const defaultListLimit = 50
+
+// NewHandler returns the admin API handler. It is supposed to be mounted
+// at /admin/ and it serves the following resources:
+//
+//	GET  /admin/deliveries                      - list recent deliveries
+//	GET  /admin/deliveries/<module>/<id>        - get a delivery including the payload
+//	POST /admin/deliveries/<module>/<id>/replay - process a delivery again
+//
+// The list can be filtered using module and status query parameters,
+// the number of deliveries returned is controlled by the limit parameter.
+//
+// Every request must carry the configured token in the Authorization header
+// using the Bearer scheme.
+func NewHandler(q *queue.Queue, token string) http.Handler {
+	h := &handler{q}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/admin/deliveries", h.listDeliveries)
+	mux.HandleFunc("/admin/deliveries/", h.handleDelivery)
+
+	return newAuthHandler(token, mux)
+}
+
+func newAuthHandler(token string, next http.Handler) http.Handler {
+	expected := []byte("Bearer " + token)
+	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		got := []byte(r.Header.Get("Authorization"))
+		if subtle.ConstantTimeCompare(got, expected) != 1 {
+			log.Warn(r, "Admin API access denied")
+			httputil.Status(rw, http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(rw, r)
+	})
+}
+
+type handler struct {
+	queue *queue.Queue
+}
+
+// deliverySummary is what the list endpoint returns for every delivery.
+type deliverySummary struct {
+	Id            string       `json:"id"`
+	ModuleId      string       `json:"module_id"`
+	Event         string       `json:"event,omitempty"`
+	ReplayOf      string       `json:"replay_of,omitempty"`
+	Status        queue.Status `json:"status"`
+	Attempts      int          `json:"attempts"`
+	StatusCode    int          `json:"status_code,omitempty"`
+	ReceivedAt    time.Time    `json:"received_at"`
+	UpdatedAt     time.Time    `json:"updated_at"`
+	NextAttemptAt *time.Time   `json:"next_attempt_at,omitempty"`
+}
+
+func newDeliverySummary(d *queue.Delivery) *deliverySummary {
+	summary := &deliverySummary{
+		Id:         d.Id,
+		ModuleId:   d.ModuleId,
+		Event:      eventType(d),
+		ReplayOf:   d.ReplayOf,
+		Status:     d.Status,
+		Attempts:   d.Attempts,
+		StatusCode: d.StatusCode,
+		ReceivedAt: d.ReceivedAt,
+		UpdatedAt:  d.UpdatedAt,
+	}
+	if !d.Finished() {
+		next := d.NextAttemptAt
+		summary.NextAttemptAt = &next
+	}
+	return summary
+}
+
+// deliveryDetail is what the detail endpoint returns.
+// The payload is returned as a string since it is usually JSON anyway.
+type deliveryDetail struct {
+	*deliverySummary
+	Method  string      `json:"method"`
+	Path    string      `json:"path"`
+	Header  http.Header `json:"header"`
+	Payload string      `json:"payload"`
+	Outcome string      `json:"outcome,omitempty"`
+}
+
+// eventType returns the event type as specified by the webhook sender.
+func eventType(d *queue.Delivery) string {
+	for _, key := range []string{"X-GitHub-Event", "X-Gitlab-Event"} {
+		if v := d.Header.Get(key); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+func (h *handler) listDeliveries(rw http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		httputil.Status(rw, http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Parse the query.
+	var (
+		query    = r.URL.Query()
+		moduleId = query.Get("module")
+		status   = queue.Status(query.Get("status"))
+		limit    = defaultListLimit
+	)
+	if v := query.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			httputil.Status(rw, http.StatusBadRequest)
+			return
+		}
+		limit = n
+	}
+
+	// Get the relevant module IDs.
+	store := h.queue.Store()
+	var moduleIds []string
+	if moduleId != "" {
+		moduleIds = []string{moduleId}
+	} else {
+		ids, err := store.ModuleIds()
+		if err != nil {
+			httputil.Error(rw, r, err)
+			return
+		}
+		moduleIds = ids
+	}
+
+	// Collect the deliveries.
+	var deliveries []*queue.Delivery
+	for _, id := range moduleIds {
+		ds, err := store.List(id)
+		if err != nil {
+			if err == queue.ErrInvalidId {
+				httputil.Status(rw, http.StatusBadRequest)
+				return
+			}
+			httputil.Error(rw, r, err)
+			return
+		}
+		for _, d := range ds {
+			if status == "" || d.Status == status {
+				deliveries = append(deliveries, d)
+			}
+		}
+	}
+
+	// Return the most recent deliveries first.
+	sort.Sort(sort.Reverse(byId(deliveries)))
+	if len(deliveries) > limit {
+		deliveries = deliveries[:limit]
+	}
+
+	summaries := make([]*deliverySummary, len(deliveries))
+	for i, d := range deliveries {
+		summaries[i] = newDeliverySummary(d)
+	}
+	writeJSON(rw, r, http.StatusOK, map[string]interface{}{
+		"deliveries": summaries,
+	})
+}
+
+func (h *handler) handleDelivery(rw http.ResponseWriter, r *http.Request) {
+	// The path is /admin/deliveries/<module>/<id>[/replay]
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/admin/deliveries/"), "/")
+	switch {
+	case len(parts) == 2 && r.Method == "GET":
+		h.getDelivery(rw, r, parts[0], parts[1])
+	case len(parts) == 3 && parts[2] == "replay" && r.Method == "POST":
+		h.replayDelivery(rw, r, parts[0], parts[1])
+	case len(parts) == 2 || len(parts) == 3 && parts[2] == "replay":
+		httputil.Status(rw, http.StatusMethodNotAllowed)
+	default:
+		httputil.Status(rw, http.StatusNotFound)
+	}
+}
+
+func (h *handler) getDelivery(rw http.ResponseWriter, r *http.Request, moduleId, id string) {
+	d, err := h.queue.Store().Load(moduleId, id)
+	if err != nil {
+		h.loadError(rw, r, err)
+		return
+	}
+
+	// The deliveries stored by older versions may still contain the credentials.
+	d.Redact()
+
+	writeJSON(rw, r, http.StatusOK, &deliveryDetail{
+		deliverySummary: newDeliverySummary(d),
+		Method:          d.Method,
+		Path:            d.Path,
+		Header:          d.Header,
+		Payload:         string(d.Body),
+		Outcome:         d.Outcome,
+	})
+}
+
+func (h *handler) replayDelivery(rw http.ResponseWriter, r *http.Request, moduleId, id string) {
+	d, err := h.queue.Replay(moduleId, id)
+	if err != nil {
+		h.loadError(rw, r, err)
+		return
+	}
+
+	log.Info(r, "Delivery %v/%v replayed as %v", moduleId, id, d.Id)
+	writeJSON(rw, r, http.StatusAccepted, newDeliverySummary(d))
+}
+
+func (h *handler) loadError(rw http.ResponseWriter, r *http.Request, err error) {
+	if err == queue.ErrNotFound || err == queue.ErrInvalidId {
+		httputil.Status(rw, http.StatusNotFound)
+		return
+	}
+	httputil.Error(rw, r, err)
+}
+
+func writeJSON(rw http.ResponseWriter, r *http.Request, status int, v interface{}) {
+	body, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		httputil.Error(rw, r, err)
+		return
+	}
+
+	rw.Header().Set("Content-Type", "application/json; charset=utf-8")
+	rw.WriteHeader(status)
+	rw.Write(body)
+	rw.Write([]byte("\n"))
+}
+
+type byId []*queue.Delivery
+
+func (ds byId) Len() int           { return len(ds) }
+func (ds byId) Less(i, j int) bool { return ds[i].Id < ds[j].Id }
+func (ds byId) Swap(i, j int)      { ds[i], ds[j] = ds[j], ds[i] }
@@ -0,0 +1,143 @@
+package admin
+
+import (
+	// Stdlib
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	// Internal
+	"github.com/salsaflow/salsaflow-daemon/internal/idempotency"
+	"github.com/salsaflow/salsaflow-daemon/internal/queue"
+)
+
+const (
+	testingToken    = "s3cr3t"
+	testingModuleId = "salsaflow.modules.testing"
+)
+
+func TestAdminAPI(t *testing.T) {
+	dir, err := ioutil.TempDir("", "salsaflow-daemon-admin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	store, err := queue.NewFileStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	q := queue.New(store, queue.Config{
+		Workers:     1,
+		MaxAttempts: 1,
+		Backoff:     time.Millisecond,
+		MaxBackoff:  time.Millisecond,
+		Retention:   time.Hour,
+	})
+
+	replayed := make(chan bool, 2)
+	moduleHandler := q.Handler(testingModuleId, http.HandlerFunc(
+		func(rw http.ResponseWriter, r *http.Request) {
+			replayed <- idempotency.IsReplay(r.Context())
+			rw.WriteHeader(http.StatusAccepted)
+		}))
+
+	if err := q.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer q.Stop()
+
+	api := NewHandler(q, testingToken)
+
+	do := func(method, path, token string) *httptest.ResponseRecorder {
+		r, err := http.NewRequest(method, path, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if token != "" {
+			r.Header.Set("Authorization", "Bearer "+token)
+		}
+		rw := httptest.NewRecorder()
+		api.ServeHTTP(rw, r)
+		return rw
+	}
+
+	// Store a delivery.
+	r, _ := http.NewRequest("POST", "/events", strings.NewReader(`{"action":"opened"}`))
+	r.Header.Set("X-GitHub-Event", "issues")
+	rw := httptest.NewRecorder()
+	moduleHandler.ServeHTTP(rw, r)
+	id := rw.Header().Get("X-SalsaFlow-Delivery")
+
+	if replay := <-replayed; replay {
+		t.Error("original delivery marked as a replay")
+	}
+
+	// Authentication.
+	if rw := do("GET", "/admin/deliveries", ""); rw.Code != http.StatusUnauthorized {
+		t.Errorf("missing token: expected 401, got %v", rw.Code)
+	}
+	if rw := do("GET", "/admin/deliveries", "wrong"); rw.Code != http.StatusUnauthorized {
+		t.Errorf("wrong token: expected 401, got %v", rw.Code)
+	}
+
+	// List.
+	rw = do("GET", "/admin/deliveries?module="+testingModuleId, testingToken)
+	if rw.Code != http.StatusOK {
+		t.Fatalf("list: expected 200, got %v", rw.Code)
+	}
+	var list struct {
+		Deliveries []struct {
+			Id    string `json:"id"`
+			Event string `json:"event"`
+		} `json:"deliveries"`
+	}
+	if err := json.Unmarshal(rw.Body.Bytes(), &list); err != nil {
+		t.Fatal(err)
+	}
+	if len(list.Deliveries) != 1 || list.Deliveries[0].Id != id || list.Deliveries[0].Event != "issues" {
+		t.Errorf("list: unexpected response: %v", rw.Body.String())
+	}
+
+	// Detail.
+	rw = do("GET", "/admin/deliveries/"+testingModuleId+"/"+id, testingToken)
+	if rw.Code != http.StatusOK {
+		t.Fatalf("detail: expected 200, got %v", rw.Code)
+	}
+	var detail struct {
+		Payload string `json:"payload"`
+	}
+	if err := json.Unmarshal(rw.Body.Bytes(), &detail); err != nil {
+		t.Fatal(err)
+	}
+	if detail.Payload != `{"action":"opened"}` {
+		t.Errorf("detail: unexpected payload: %q", detail.Payload)
+	}
+
+	if rw := do("GET", "/admin/deliveries/"+testingModuleId+"/nope", testingToken); rw.Code != http.StatusNotFound {
+		t.Errorf("detail: expected 404, got %v", rw.Code)
+	}
+	if rw := do("GET", "/admin/deliveries/"+testingModuleId+"/.tmp-123", testingToken); rw.Code != http.StatusNotFound {
+		t.Errorf("detail: expected 404, got %v", rw.Code)
+	}
+
+	// Replay.
+	rw = do("POST", "/admin/deliveries/"+testingModuleId+"/"+id+"/replay", testingToken)
+	if rw.Code != http.StatusAccepted {
+		t.Fatalf("replay: expected 202, got %v", rw.Code)
+	}
+
+	select {
+	case replay := <-replayed:
+		if !replay {
+			t.Error("replayed delivery not marked as a replay")
+		}
+	case <-time.After(5 * time.Second):
+		t.Error("replayed delivery not processed")
+	}
+}
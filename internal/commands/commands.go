@@ -0,0 +1,313 @@
+// Package commands implements the chat-ops commands that can be used
+// in comments posted into GitHub, GitLab and so on.
+//
+// A command is always placed at the beginning of a line and it is prefixed
+// with '!', e.g.
+//
+//	!fixed 2 f00ba47
+//	!wontfix 3 "Not worth it"
+//
+// Arguments are separated by whitespace. An argument containing whitespace
+// can be enclosed in double or single quotes. Within double quotes,
+// backslash can be used to escape a double quote or a backslash.
+//
+// Every module defines a Registry for every context it processes comments in,
+// e.g. commit comments or review issue comments. The registry always contains
+// the !help command listing the commands available in the given context.
+package commands
+
+import (
+	// Stdlib
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// HelpCommandName is the name of the command listing the available commands.
+const HelpCommandName = "help"
+
+// Command describes a single command.
+type Command struct {
+	// Name is the command name without the leading '!'.
+	Name string
+
+	// Usage describes the command arguments, e.g. "<blocker> [commit SHA]".
+	Usage string
+
+	// Description is a short description of what the command does.
+	Description string
+
+	// MinArgs is the minimum number of arguments.
+	MinArgs int
+
+	// MaxArgs is the maximum number of arguments, -1 meaning no limit.
+	MaxArgs int
+
+	// Rest makes the last argument take the rest of the line as it is,
+	// so that free text does not need to be quoted. MaxArgs must be set.
+	Rest bool
+
+	// Validate can be used to check the arguments further.
+	// It is called once the number of arguments is checked.
+	Validate func(args []string) error
+}
+
+// Invocation represents a command found in a comment.
+type Invocation struct {
+	*Command
+
+	// Args contains the parsed command arguments.
+	Args []string
+}
+
+// Arg returns the i-th argument or an empty string when there is no such argument.
+func (inv *Invocation) Arg(i int) string {
+	if i < len(inv.Args) {
+		return inv.Args[i]
+	}
+	return ""
+}
+
+// IsHelp returns true when this is the !help command.
+func (inv *Invocation) IsHelp() bool {
+	return inv.Name == HelpCommandName
+}
+
+func (inv *Invocation) String() string {
+	return "!" + strings.TrimSpace(inv.Name+" "+strings.Join(inv.Args, " "))
+}
+
+// Registry is a set of commands available in a certain context.
+type Registry struct {
+	commands []*Command
+}
+
+// NewRegistry returns a registry containing the given commands and !help.
+// It panics in case the command names are not unique.
+func NewRegistry(cmds ...*Command) *Registry {
+	help := &Command{
+		Name:        HelpCommandName,
+		Description: "List the commands available here.",
+	}
+
+	reg := &Registry{}
+	for _, cmd := range append(cmds, help) {
+		if reg.Lookup(cmd.Name) != nil {
+			panic(fmt.Errorf("command registered twice: !%v", cmd.Name))
+		}
+		reg.commands = append(reg.commands, cmd)
+	}
+	return reg
+}
+
+// Lookup returns the command with the given name or nil when there is no such command.
+func (reg *Registry) Lookup(name string) *Command {
+	for _, cmd := range reg.commands {
+		if cmd.Name == name {
+			return cmd
+		}
+	}
+	return nil
+}
+
+// Commands returns the registered commands, !help being the last one.
+func (reg *Registry) Commands() []*Command {
+	return reg.commands
+}
+
+// A command line starts with '!' followed by the command name.
+var cmdLineRegexp = regexp.MustCompile(`^[!]([a-zA-Z]+)(?:[ \t]+(.*))?$`)
+
+// Parse returns the commands found in the given comment body.
+//
+// Lines that look like a command that is not registered are ignored,
+// so that the commands meant for other modules do not cause any errors.
+// Malformed commands are returned as *ErrInvalidCommand.
+func (reg *Registry) Parse(body string) (invs []*Invocation, errs []error) {
+	scanner := bufio.NewScanner(strings.NewReader(body))
+	for scanner.Scan() {
+		// Check whether this is a command and continue if not.
+		line := strings.TrimRight(scanner.Text(), " \t\r")
+		match := cmdLineRegexp.FindStringSubmatch(line)
+		if len(match) == 0 {
+			continue
+		}
+		name, arg := match[1], match[2]
+
+		// Skip unknown commands.
+		cmd := reg.Lookup(name)
+		if cmd == nil {
+			continue
+		}
+
+		args, err := cmd.parseArgs(arg)
+		if err != nil {
+			errs = append(errs, &ErrInvalidCommand{cmd, line, err})
+			continue
+		}
+		invs = append(invs, &Invocation{cmd, args})
+	}
+	if err := scanner.Err(); err != nil {
+		errs = append(errs, err)
+	}
+	return invs, errs
+}
+
+func (cmd *Command) parseArgs(arg string) ([]string, error) {
+	var (
+		args []string
+		rest = arg
+	)
+	for {
+		// Let the last argument take the rest of the line when requested.
+		// The argument is only unquoted when it is a single quoted string.
+		if cmd.Rest && len(args) == cmd.MaxArgs-1 {
+			if rest = strings.TrimSpace(rest); rest != "" {
+				token, remaining, _, err := nextToken(rest)
+				if err != nil || remaining != "" {
+					token = rest
+				}
+				args = append(args, token)
+			}
+			break
+		}
+
+		token, remaining, ok, err := nextToken(rest)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			break
+		}
+		args, rest = append(args, token), remaining
+	}
+
+	switch {
+	case len(args) < cmd.MinArgs:
+		return nil, errors.New("not enough arguments")
+	case cmd.MaxArgs >= 0 && len(args) > cmd.MaxArgs:
+		return nil, errors.New("too many arguments")
+	}
+
+	if cmd.Validate != nil {
+		if err := cmd.Validate(args); err != nil {
+			return nil, err
+		}
+	}
+	return args, nil
+}
+
+// nextToken returns the next argument from the given string
+// and the part of the string that follows the argument.
+// ok is false when there are no more arguments.
+func nextToken(s string) (token, rest string, ok bool, err error) {
+	s = strings.TrimLeft(s, " \t")
+	if s == "" {
+		return "", "", false, nil
+	}
+
+	var (
+		buffer bytes.Buffer
+		quote  rune
+		escape bool
+	)
+	for i, c := range s {
+		switch {
+		case escape:
+			buffer.WriteRune(c)
+			escape = false
+
+		case quote == '"' && c == '\\':
+			escape = true
+
+		case quote != 0 && c == quote:
+			quote = 0
+
+		case quote != 0:
+			buffer.WriteRune(c)
+
+		case c == '"' || c == '\'':
+			quote = c
+
+		case c == ' ' || c == '\t':
+			return buffer.String(), s[i:], true, nil
+
+		default:
+			buffer.WriteRune(c)
+		}
+	}
+	if quote != 0 || escape {
+		return "", "", false, errors.New("unterminated quoted string")
+	}
+	return buffer.String(), "", true, nil
+}
+
+// ErrInvalidCommand is returned by Registry.Parse for malformed commands.
+type ErrInvalidCommand struct {
+	Command *Command
+	Line    string
+	Err     error
+}
+
+func (err *ErrInvalidCommand) Error() string {
+	return fmt.Sprintf("%v: %v", err.Line, err.Err)
+}
+
+// UsageLine returns the usage line for the given command, e.g. "!fixed <blocker> [commit SHA]".
+func (cmd *Command) UsageLine() string {
+	return strings.TrimSpace("!" + cmd.Name + " " + cmd.Usage)
+}
+
+// HelpMessage returns the reply to be posted for !help.
+func (reg *Registry) HelpMessage() string {
+	var buffer bytes.Buffer
+	fmt.Fprintln(&buffer, "The following commands are available here:")
+	for _, cmd := range reg.commands {
+		fmt.Fprintf(&buffer, "- `%v`: %v\n", cmd.UsageLine(), cmd.Description)
+	}
+	fmt.Fprintln(&buffer)
+	fmt.Fprintln(&buffer, "A command must be placed at the beginning of a line. "+
+		"Arguments containing spaces can be quoted.")
+	return buffer.String()
+}
+
+// Reply returns the reply to be posted for the given comment
+// containing the given commands, i.e. the help message in case !help
+// is present and the list of errors in case there are any malformed commands.
+// Empty string is returned when there is no need to reply.
+func (reg *Registry) Reply(author string, invs []*Invocation, errs []error) string {
+	var help bool
+	for _, inv := range invs {
+		if inv.IsHelp() {
+			help = true
+			break
+		}
+	}
+	if !help && len(errs) == 0 {
+		return ""
+	}
+
+	var buffer bytes.Buffer
+	fmt.Fprintf(&buffer, "@%v ", author)
+	if len(errs) != 0 {
+		fmt.Fprintln(&buffer, "The following commands could not be processed:")
+		for _, err := range errs {
+			if ex, ok := err.(*ErrInvalidCommand); ok {
+				fmt.Fprintf(&buffer, "- `%v`: %v (usage: `%v`)\n",
+					ex.Line, ex.Err, ex.Command.UsageLine())
+			} else {
+				fmt.Fprintf(&buffer, "- %v\n", err)
+			}
+		}
+		fmt.Fprintln(&buffer)
+	}
+	if help {
+		buffer.WriteString(reg.HelpMessage())
+	} else {
+		fmt.Fprintf(&buffer, "Use `!%v` to list the available commands.\n", HelpCommandName)
+	}
+	return buffer.String()
+}
@@ -0,0 +1,157 @@
+package commands
+
+import (
+	// Stdlib
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var (
+	testingFixedCommand = &Command{
+		Name:        "fixed",
+		Usage:       "<blocker> [commit SHA]",
+		Description: "Mark the blocker as fixed.",
+		MinArgs:     1,
+		MaxArgs:     2,
+	}
+
+	testingMustfixCommand = &Command{
+		Name:        "mustfix",
+		Usage:       "<summary>",
+		Description: "Open a blocker.",
+		MinArgs:     1,
+		MaxArgs:     1,
+		Rest:        true,
+	}
+
+	testingReviewedCommand = &Command{
+		Name:        "reviewed",
+		Usage:       "[commit SHA...]",
+		Description: "Mark commits as reviewed.",
+		MaxArgs:     -1,
+		Validate: func(args []string) error {
+			for _, arg := range args {
+				if arg == "HEAD" {
+					return errors.New("HEAD is not a commit SHA")
+				}
+			}
+			return nil
+		},
+	}
+)
+
+func newTestingRegistry() *Registry {
+	return NewRegistry(testingFixedCommand, testingMustfixCommand, testingReviewedCommand)
+}
+
+func TestRegistry_Parse(t *testing.T) {
+	data := []struct {
+		body     string
+		expected []string
+		errs     int
+	}{
+		// Commands and their arguments.
+		{"!fixed 1", []string{"fixed|1"}, 0},
+		{"!fixed 1 abc123", []string{"fixed|1|abc123"}, 0},
+		{"!reviewed", []string{"reviewed"}, 0},
+		{"!reviewed  a   b\tc ", []string{"reviewed|a|b|c"}, 0},
+		{"!help", []string{"help"}, 0},
+
+		// Quoting.
+		{`!reviewed "a b" 'c d'`, []string{"reviewed|a b|c d"}, 0},
+		{`!reviewed "a \"b\" \\c"`, []string{`reviewed|a "b" \c`}, 0},
+		{`!reviewed a"b c"d`, []string{"reviewed|ab cd"}, 0},
+		{`!reviewed "a b`, nil, 1},
+
+		// The rest of the line.
+		{"!mustfix Fix the typo, it's wrong.", []string{"mustfix|Fix the typo, it's wrong."}, 0},
+		{`!mustfix "Fix the typo"`, []string{"mustfix|Fix the typo"}, 0},
+		{`!mustfix "Fix" the "typo"`, []string{`mustfix|"Fix" the "typo"`}, 0},
+
+		// Malformed commands.
+		{"!fixed", nil, 1},
+		{"!fixed 1 2 3", nil, 1},
+		{"!mustfix", nil, 1},
+		{"!help me", nil, 1},
+		{"!reviewed a HEAD", nil, 1},
+
+		// Not commands.
+		{"Text !fixed 1", nil, 0},
+		{" !fixed 1", nil, 0},
+		{"!unknown 1", nil, 0},
+		{"!fixed: 1", nil, 0},
+
+		// Multiple lines.
+		{"Some text.\n!fixed 1\n\n!mustfix Fix it\r\n!fixed\n", []string{"fixed|1", "mustfix|Fix it"}, 1},
+	}
+
+	reg := newTestingRegistry()
+	for _, d := range data {
+		invs, errs := reg.Parse(d.body)
+
+		var got []string
+		for _, inv := range invs {
+			got = append(got, strings.Join(append([]string{inv.Name}, inv.Args...), "|"))
+		}
+		if !reflect.DeepEqual(got, d.expected) {
+			t.Errorf("%q: expected %q, got %q", d.body, d.expected, got)
+		}
+		if len(errs) != d.errs {
+			t.Errorf("%q: expected %v error(s), got %v", d.body, d.errs, errs)
+		}
+		for _, err := range errs {
+			if _, ok := err.(*ErrInvalidCommand); !ok {
+				t.Errorf("%q: unexpected error type: %T", d.body, err)
+			}
+		}
+	}
+}
+
+func TestNewRegistry_Duplicate(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("registering a command twice did not panic")
+		}
+	}()
+	NewRegistry(testingFixedCommand, &Command{Name: "fixed"})
+}
+
+func TestRegistry_Reply(t *testing.T) {
+	reg := newTestingRegistry()
+
+	// No reply is needed.
+	invs, errs := reg.Parse("!fixed 1")
+	if reply := reg.Reply("author", invs, errs); reply != "" {
+		t.Errorf("unexpected reply: %q", reply)
+	}
+
+	// Help.
+	invs, errs = reg.Parse("!help")
+	reply := reg.Reply("author", invs, errs)
+	for _, s := range []string{
+		"@author ",
+		"- `!fixed <blocker> [commit SHA]`: Mark the blocker as fixed.\n",
+		"- `!mustfix <summary>`: Open a blocker.\n",
+		"- `!reviewed [commit SHA...]`: Mark commits as reviewed.\n",
+		"- `!help`: ",
+	} {
+		if !strings.Contains(reply, s) {
+			t.Errorf("help reply does not contain %q:\n%v", s, reply)
+		}
+	}
+
+	// Errors.
+	invs, errs = reg.Parse("!fixed 1 2 3")
+	reply = reg.Reply("author", invs, errs)
+	for _, s := range []string{
+		"@author ",
+		"- `!fixed 1 2 3`: too many arguments (usage: `!fixed <blocker> [commit SHA]`)\n",
+		"Use `!help`",
+	} {
+		if !strings.Contains(reply, s) {
+			t.Errorf("error reply does not contain %q:\n%v", s, reply)
+		}
+	}
+}
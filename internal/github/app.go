@@ -0,0 +1,297 @@
+package github
+
+import (
+	// Stdlib
+	"bytes"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	// Internal
+	"github.com/salsaflow/salsaflow-daemon/internal/errs"
+
+	// Vendor
+	"github.com/google/go-github/github"
+	"golang.org/x/oauth2"
+)
+
+const (
+	defaultAPIURL = "https://api.github.com/"
+
+	// GitHub accepts JWTs valid for 10 minutes at most.
+	jwtLifetime = 9 * time.Minute
+
+	// Installation tokens are valid for an hour,
+	// they are refreshed some time before they expire.
+	tokenRefreshMargin = 5 * time.Minute
+
+	// The installation IDs looked up by account are cached for a short time only
+	// since the app reinstalled for the account gets a new installation ID.
+	installationIdTTL = 10 * time.Minute
+)
+
+// App authenticates as a GitHub App. It signs JWTs using the app private key
+// and exchanges them for installation access tokens, which are cached.
+type App struct {
+	id     int
+	key    *rsa.PrivateKey
+	apiURL string
+
+	// HTTPClient is the client used to talk to the app API endpoints.
+	HTTPClient *http.Client
+
+	// now can be replaced in tests.
+	now func() time.Time
+
+	mu            sync.Mutex
+	installations map[string]*cachedInstallation
+	tokens        map[int]*installationToken
+}
+
+type cachedInstallation struct {
+	id        int
+	expiresAt time.Time
+}
+
+type installationToken struct {
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// NewApp returns an App for the given app ID and PEM-encoded private key.
+func NewApp(appId int, privateKeyPEM []byte) (*App, error) {
+	key, err := parsePrivateKey(privateKeyPEM)
+	if err != nil {
+		return nil, err
+	}
+
+	return &App{
+		id:            appId,
+		key:           key,
+		apiURL:        defaultAPIURL,
+		HTTPClient:    http.DefaultClient,
+		now:           time.Now,
+		installations: make(map[string]*cachedInstallation),
+		tokens:        make(map[int]*installationToken),
+	}, nil
+}
+
+func parsePrivateKey(privateKeyPEM []byte) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode(privateKeyPEM)
+	if block == nil {
+		return nil, errors.New("GitHub App: private key is not PEM-encoded")
+	}
+
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("GitHub App: failed to parse private key: %v", err)
+	}
+	rsaKey, ok := key.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("GitHub App: private key is not an RSA key")
+	}
+	return rsaKey, nil
+}
+
+// JWT returns a new JSON Web Token authenticating the app itself.
+func (app *App) JWT() (string, error) {
+	now := app.now()
+
+	header, _ := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT"})
+	claims, _ := json.Marshal(map[string]interface{}{
+		// Allow for some clock drift.
+		"iat": now.Add(-time.Minute).Unix(),
+		"exp": now.Add(jwtLifetime).Unix(),
+		"iss": app.id,
+	})
+
+	enc := base64.RawURLEncoding
+	unsigned := enc.EncodeToString(header) + "." + enc.EncodeToString(claims)
+
+	hash := sha256.Sum256([]byte(unsigned))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, app.key, crypto.SHA256, hash[:])
+	if err != nil {
+		return "", err
+	}
+	return unsigned + "." + enc.EncodeToString(signature), nil
+}
+
+// InstallationId returns the ID of the app installation for the given account.
+// The ID is looked up using the API and cached for installationIdTTL.
+// The webhook handlers use the installation ID sent in the payload instead,
+// see NewClientForRequest.
+func (app *App) InstallationId(owner string) (int, error) {
+	key := strings.ToLower(owner)
+
+	app.mu.Lock()
+	cached, ok := app.installations[key]
+	app.mu.Unlock()
+	if ok && app.now().Before(cached.expiresAt) {
+		return cached.id, nil
+	}
+
+	// The account can be both an organization or a user.
+	var installation struct {
+		Id int `json:"id"`
+	}
+	err := app.do("GET", "orgs/"+owner+"/installation", &installation)
+	if isNotFound(err) {
+		err = app.do("GET", "users/"+owner+"/installation", &installation)
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	app.mu.Lock()
+	app.installations[key] = &cachedInstallation{installation.Id, app.now().Add(installationIdTTL)}
+	app.mu.Unlock()
+	return installation.Id, nil
+}
+
+// InstallationToken returns an access token for the given installation.
+// The token is cached and refreshed shortly before it expires.
+func (app *App) InstallationToken(installationId int) (string, error) {
+	app.mu.Lock()
+	token, ok := app.tokens[installationId]
+	app.mu.Unlock()
+	if ok && app.now().Add(tokenRefreshMargin).Before(token.ExpiresAt) {
+		return token.Token, nil
+	}
+
+	token = &installationToken{}
+	path := fmt.Sprintf("app/installations/%v/access_tokens", installationId)
+	if err := app.do("POST", path, token); err != nil {
+		return "", err
+	}
+
+	app.mu.Lock()
+	app.tokens[installationId] = token
+	app.mu.Unlock()
+	return token.Token, nil
+}
+
+// NewClient returns a client authenticated as the app installation
+// for the given account.
+func (app *App) NewClient(owner string) (*github.Client, error) {
+	installationId, err := app.InstallationId(owner)
+	if err != nil {
+		return nil, err
+	}
+	return app.NewClientForInstallation(installationId), nil
+}
+
+// NewClientForInstallation returns a client authenticated as the given app installation.
+func (app *App) NewClientForInstallation(installationId int) *github.Client {
+	ts := &installationTokenSource{app, installationId}
+	return github.NewClient(oauth2.NewClient(oauth2.NoContext, ts))
+}
+
+type errHTTP struct {
+	method     string
+	path       string
+	statusCode int
+	body       string
+}
+
+func (err *errHTTP) Error() string {
+	return fmt.Sprintf("GitHub App: %v %v: %v %v", err.method, err.path, err.statusCode, err.body)
+}
+
+func isNotFound(err error) bool {
+	httpErr, ok := err.(*errHTTP)
+	return ok && httpErr.statusCode == http.StatusNotFound
+}
+
+// do sends an API request authenticated using a fresh JWT.
+func (app *App) do(method, path string, v interface{}) error {
+	jwt, err := app.JWT()
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(method, app.apiURL+path, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+jwt)
+	req.Header.Set("Accept", "application/vnd.github+json")
+
+	resp, err := app.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return &errHTTP{method, path, resp.StatusCode, string(bytes.TrimSpace(body))}
+	}
+	return json.Unmarshal(body, v)
+}
+
+type installationTokenSource struct {
+	app            *App
+	installationId int
+}
+
+// Token implements oauth2.TokenSource interface.
+func (ts *installationTokenSource) Token() (*oauth2.Token, error) {
+	token, err := ts.app.InstallationToken(ts.installationId)
+	if err != nil {
+		return nil, err
+	}
+	return &oauth2.Token{AccessToken: token}, nil
+}
+
+// Default app ------------------------------------------------------------------
+
+var (
+	defaultApp     *App
+	defaultAppErr  error
+	defaultAppOnce sync.Once
+)
+
+// GetApp returns the App configured using the environment.
+// nil is returned when the daemon is not configured to run as an app.
+func GetApp() (*App, error) {
+	defaultAppOnce.Do(func() {
+		c := GetConfig()
+		if c.AppId == 0 {
+			return
+		}
+
+		privateKey := []byte(c.AppPrivateKey)
+		if len(privateKey) == 0 {
+			if c.AppPrivateKeyFile == "" {
+				defaultAppErr = &errs.ErrVarNotSet{VariableName: "SFD_GITHUB_APP_PRIVATE_KEY"}
+				return
+			}
+			privateKey, defaultAppErr = ioutil.ReadFile(c.AppPrivateKeyFile)
+			if defaultAppErr != nil {
+				return
+			}
+		}
+
+		defaultApp, defaultAppErr = NewApp(c.AppId, privateKey)
+	})
+	return defaultApp, defaultAppErr
+}
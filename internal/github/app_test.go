@@ -0,0 +1,194 @@
+package github
+
+import (
+	// Stdlib
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+const testingAppId = 42
+
+// testingAppAPI is a GitHub stand-in implementing the app endpoints.
+// It verifies the JWTs and counts the issued installation tokens.
+type testingAppAPI struct {
+	*httptest.Server
+
+	key *rsa.PublicKey
+	now func() time.Time
+
+	mu     sync.Mutex
+	issued int
+
+	// acmeInstallation is the ID of the installation for acme,
+	// it changes when the app is reinstalled.
+	acmeInstallation int
+}
+
+func newTestingApp(t *testing.T) (*App, *testingAppAPI) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyPEM := pem.EncodeToMemory(&pem.Block{
+		Type:  "RSA PRIVATE KEY",
+		Bytes: x509.MarshalPKCS1PrivateKey(key),
+	})
+
+	app, err := NewApp(testingAppId, keyPEM)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	api := &testingAppAPI{key: &key.PublicKey, now: time.Now, acmeInstallation: 1}
+	api.Server = httptest.NewServer(api)
+	app.apiURL = api.URL + "/"
+	return app, api
+}
+
+func (api *testingAppAPI) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
+	api.mu.Lock()
+	defer api.mu.Unlock()
+
+	if err := api.verifyJWT(strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")); err != nil {
+		http.Error(rw, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	switch {
+	case r.Method == "GET" && r.URL.Path == "/orgs/acme/installation":
+		json.NewEncoder(rw).Encode(map[string]int{"id": api.acmeInstallation})
+
+	case r.Method == "GET" && r.URL.Path == "/users/john/installation":
+		json.NewEncoder(rw).Encode(map[string]int{"id": 2})
+
+	case r.Method == "POST" && strings.HasPrefix(r.URL.Path, "/app/installations/"):
+		api.issued++
+		json.NewEncoder(rw).Encode(map[string]interface{}{
+			"token":      fmt.Sprintf("token-%v", api.issued),
+			"expires_at": api.now().Add(time.Hour),
+		})
+
+	default:
+		http.Error(rw, `{"message":"Not Found"}`, http.StatusNotFound)
+	}
+}
+
+func (api *testingAppAPI) verifyJWT(jwt string) error {
+	parts := strings.Split(jwt, ".")
+	if len(parts) != 3 {
+		return fmt.Errorf("malformed JWT: %v", jwt)
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return err
+	}
+	hash := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if err := rsa.VerifyPKCS1v15(api.key, crypto.SHA256, hash[:], signature); err != nil {
+		return err
+	}
+
+	claimsJSON, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return err
+	}
+	var claims struct {
+		Iss int   `json:"iss"`
+		Iat int64 `json:"iat"`
+		Exp int64 `json:"exp"`
+	}
+	if err := json.Unmarshal(claimsJSON, &claims); err != nil {
+		return err
+	}
+	if claims.Iss != testingAppId {
+		return fmt.Errorf("unexpected issuer: %v", claims.Iss)
+	}
+	if claims.Exp-claims.Iat > 600 {
+		return fmt.Errorf("JWT valid for too long: %v seconds", claims.Exp-claims.Iat)
+	}
+	return nil
+}
+
+func TestApp_InstallationToken(t *testing.T) {
+	app, api := newTestingApp(t)
+	defer api.Close()
+
+	// The token is cached.
+	for i := 0; i < 2; i++ {
+		token, err := app.InstallationToken(1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if token != "token-1" {
+			t.Errorf("expected token-1, got %v", token)
+		}
+	}
+
+	// The token is refreshed shortly before it expires.
+	app.now = func() time.Time {
+		return time.Now().Add(time.Hour - time.Minute)
+	}
+	token, err := app.InstallationToken(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token != "token-2" {
+		t.Errorf("expected token-2, got %v", token)
+	}
+}
+
+func TestApp_InstallationId(t *testing.T) {
+	app, api := newTestingApp(t)
+	defer api.Close()
+
+	data := []struct {
+		owner string
+		id    int
+	}{
+		{"acme", 1},
+		{"john", 2},
+	}
+	for _, d := range data {
+		id, err := app.InstallationId(d.owner)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if id != d.id {
+			t.Errorf("owner %v: expected installation %v, got %v", d.owner, d.id, id)
+		}
+	}
+
+	// The IDs are cached for a while.
+	api.mu.Lock()
+	api.acmeInstallation = 3
+	api.mu.Unlock()
+	if id, err := app.InstallationId("Acme"); err != nil || id != 1 {
+		t.Errorf("expected cached installation 1, got %v (err=%v)", id, err)
+	}
+
+	// The app reinstalled for the account is picked up once the cached ID expires.
+	app.now = func() time.Time {
+		return time.Now().Add(installationIdTTL)
+	}
+	if id, err := app.InstallationId("acme"); err != nil || id != 3 {
+		t.Errorf("expected installation 3, got %v (err=%v)", id, err)
+	}
+
+	// Unknown accounts result in an error.
+	if _, err := app.InstallationId("initech"); err == nil {
+		t.Error("expected an error for an account without installation")
+	}
+}
@@ -1,8 +1,13 @@
 package github
 
 import (
+	// Stdlib
+	"fmt"
+	"net/http"
+
 	// Internal
 	"github.com/salsaflow/salsaflow-daemon/internal/errs"
+	"github.com/salsaflow/salsaflow-daemon/internal/tenants"
 
 	// Vendor
 	"github.com/google/go-github/github"
@@ -15,8 +20,90 @@ func NewClient() (*github.Client, error) {
 		return nil, &errs.ErrVarNotSet{"SFD_GITHUB_TOKEN"}
 	}
 
+	return newClient(token), nil
+}
+
+// NewClientForOwner returns a client to be used for the repositories
+// of the given owner. The token of the tenant the owner belongs to is used,
+// then the GitHub App installation for the owner in case the daemon runs
+// as a GitHub App. The client returned by NewClient is used otherwise.
+func NewClientForOwner(owner string) (*github.Client, error) {
+	return newClientForOwner(owner, 0)
+}
+
+// NewClientForRequest returns a client to be used when handling the given webhook
+// sent for the repositories of the given owner. It is the same as NewClientForOwner
+// except that the GitHub App installation the webhook was sent for is used,
+// i.e. the one recorded by the installation middleware.
+func NewClientForRequest(r *http.Request, owner string) (*github.Client, error) {
+	return newClientForOwner(owner, installationFromRequest(r))
+}
+
+func newClientForOwner(owner string, installationId int) (*github.Client, error) {
+	if t := tenants.ForGitHubOwner(owner); t != nil && t.GitHub.Token != "" {
+		return newClient(t.GitHub.Token), nil
+	}
+
+	app, err := GetApp()
+	if err != nil {
+		return nil, err
+	}
+	if app != nil {
+		if installationId != 0 {
+			return app.NewClientForInstallation(installationId), nil
+		}
+		return app.NewClient(owner)
+	}
+
+	return NewClient()
+}
+
+// EnsureCredentials returns an error in case there are no GitHub credentials
+// configured, neither in the environment nor for any of the tenants.
+func EnsureCredentials() error {
+	app, err := GetApp()
+	if err != nil {
+		return err
+	}
+	if app != nil || GetConfig().Token != "" {
+		return nil
+	}
+	for _, t := range tenants.All() {
+		if t.GitHub != nil && t.GitHub.Token != "" {
+			return nil
+		}
+	}
+	return &errs.ErrVarNotSet{"SFD_GITHUB_TOKEN"}
+}
+
+// WebhookSecretsForOwner returns the webhook secrets accepted when verifying
+// the webhooks sent for the repositories of the given owner.
+// ok is false when the webhooks are to be rejected, see webhookSecrets.
+func WebhookSecretsForOwner(owner string) (secrets []string, ok bool) {
+	return webhookSecrets(GetConfig(), tenants.ForGitHubOwner(owner), tenants.GitHubConfigured())
+}
+
+// webhookSecrets returns the secrets of the given tenant, falling back to the secrets
+// read from the environment. The webhooks are rejected in case the tenant is known
+// and there is no secret to verify them, the tenant token would be used otherwise,
+// or in case there are tenants configured and the owner belongs to none of them.
+func webhookSecrets(c Config, t *tenants.Tenant, tenantsConfigured bool) (secrets []string, ok bool) {
+	switch {
+	case t != nil:
+		if secrets = t.GitHub.Secrets(); len(secrets) == 0 {
+			secrets = c.WebhookSecrets()
+		}
+		return secrets, len(secrets) != 0
+	case tenantsConfigured:
+		return nil, false
+	default:
+		return c.WebhookSecrets(), true
+	}
+}
+
+func newClient(token string) *github.Client {
 	httpClient := oauth2.NewClient(oauth2.NoContext, &tokenSource{token})
-	return github.NewClient(httpClient), nil
+	return github.NewClient(httpClient)
 }
 
 type tokenSource struct {
@@ -27,3 +114,45 @@ type tokenSource struct {
 func (ts *tokenSource) Token() (*oauth2.Token, error) {
 	return &oauth2.Token{AccessToken: ts.token}, nil
 }
+
+// CredentialChecks returns the checks verifying the configured credentials
+// against the GitHub API, one check for every credentials source, i.e. the token
+// read from the environment, the tenant tokens and the GitHub App, so that
+// the credentials of every tenant are reported separately.
+func CredentialChecks() map[string]func() error {
+	checks := make(map[string]func() error)
+
+	tokenCheck := func(source, token string) func() error {
+		return func() error {
+			if _, _, err := newClient(token).RateLimits(); err != nil {
+				return fmt.Errorf("GitHub token (%v): %v", source, err)
+			}
+			return nil
+		}
+	}
+
+	if token := GetConfig().Token; token != "" {
+		checks["github"] = tokenCheck("SFD_GITHUB_TOKEN", token)
+	}
+	for _, t := range tenants.All() {
+		if t.GitHub != nil && t.GitHub.Token != "" {
+			checks["github/tenant/"+t.Id] = tokenCheck("tenant "+t.Id, t.GitHub.Token)
+		}
+	}
+
+	if app, err := GetApp(); err != nil || app != nil {
+		checks["github/app"] = func() error {
+			if err != nil {
+				return err
+			}
+			var v struct {
+				Id int `json:"id"`
+			}
+			if err := app.do("GET", "app", &v); err != nil {
+				return fmt.Errorf("GitHub App: %v", err)
+			}
+			return nil
+		}
+	}
+	return checks
+}
@@ -3,14 +3,24 @@ package github
 import (
 	// Stdlib
 	"log"
+	"strings"
 
 	// Vendor
 	"github.com/kelseyhightower/envconfig"
 )
 
 type Config struct {
+	// WebhookSecret can contain a comma-separated list of secrets,
+	// all of them being accepted. This makes it possible to rotate secrets.
 	WebhookSecret string `envconfig:"WEBHOOK_SECRET"`
 	Token         string `envconfig:"TOKEN"`
+
+	// GitHub App credentials. When set, the daemon authenticates as the app
+	// installation instead of using the personal access token above.
+	// The private key can be passed either directly or as a path to a PEM file.
+	AppId             int    `envconfig:"APP_ID"`
+	AppPrivateKey     string `envconfig:"APP_PRIVATE_KEY"`
+	AppPrivateKeyFile string `envconfig:"APP_PRIVATE_KEY_FILE"`
 }
 
 var config Config
@@ -24,3 +34,14 @@ func init() {
 func GetConfig() Config {
 	return config
 }
+
+// WebhookSecrets returns the list of accepted webhook secrets.
+func (c Config) WebhookSecrets() []string {
+	var secrets []string
+	for _, secret := range strings.Split(c.WebhookSecret, ",") {
+		if secret = strings.TrimSpace(secret); secret != "" {
+			secrets = append(secrets, secret)
+		}
+	}
+	return secrets
+}
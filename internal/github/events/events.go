@@ -21,9 +21,71 @@ type IssueCommentEvent struct {
 }
 
 type IssuesEvent struct {
-	Action *string            `json:"action,omitempty"`
-	Issue  *github.Issue      `json:"issue,omitempty"`
-	Label  *github.Label      `json:"label,omitempty"`
-	Repo   *github.Repository `json:"repository,omitempty"`
-	Sender *github.User       `json:"sender,omitempty"`
+	Action  *string            `json:"action,omitempty"`
+	Issue   *github.Issue      `json:"issue,omitempty"`
+	Label   *github.Label      `json:"label,omitempty"`
+	Changes *IssueChanges      `json:"changes,omitempty"`
+	Repo    *github.Repository `json:"repository,omitempty"`
+	Sender  *github.User       `json:"sender,omitempty"`
 }
+
+// IssueChanges represents the previous values sent along with edited events.
+type IssueChanges struct {
+	Title *Change `json:"title,omitempty"`
+	Body  *Change `json:"body,omitempty"`
+}
+
+type Change struct {
+	From *string `json:"from,omitempty"`
+}
+
+type PullRequestEvent struct {
+	Action      *string             `json:"action,omitempty"`
+	Number      *int                `json:"number,omitempty"`
+	PullRequest *github.PullRequest `json:"pull_request,omitempty"`
+	Repo        *github.Repository  `json:"repository,omitempty"`
+	Sender      *github.User        `json:"sender,omitempty"`
+}
+
+// PullRequestReview represents a pull request review as sent in webhook payloads.
+// The vendored go-github package does not know about reviews yet.
+type PullRequestReview struct {
+	ID       *int         `json:"id,omitempty"`
+	User     *github.User `json:"user,omitempty"`
+	Body     *string      `json:"body,omitempty"`
+	CommitID *string      `json:"commit_id,omitempty"`
+	State    *string      `json:"state,omitempty"`
+	HTMLURL  *string      `json:"html_url,omitempty"`
+}
+
+type PullRequestReviewEvent struct {
+	Action      *string             `json:"action,omitempty"`
+	Review      *PullRequestReview  `json:"review,omitempty"`
+	PullRequest *github.PullRequest `json:"pull_request,omitempty"`
+	Repo        *github.Repository  `json:"repository,omitempty"`
+	Sender      *github.User        `json:"sender,omitempty"`
+}
+
+// PullRequestReviewComment represents an inline pull request comment
+// as sent in webhook payloads. It is defined here since
+// github.PullRequestComment is missing the html_url field.
+type PullRequestReviewComment struct {
+	ID       *int         `json:"id,omitempty"`
+	Body     *string      `json:"body,omitempty"`
+	Path     *string      `json:"path,omitempty"`
+	CommitID *string      `json:"commit_id,omitempty"`
+	User     *github.User `json:"user,omitempty"`
+	HTMLURL  *string      `json:"html_url,omitempty"`
+}
+
+type PullRequestReviewCommentEvent struct {
+	Action      *string                   `json:"action,omitempty"`
+	Comment     *PullRequestReviewComment `json:"comment,omitempty"`
+	PullRequest *github.PullRequest       `json:"pull_request,omitempty"`
+	Repo        *github.Repository        `json:"repository,omitempty"`
+	Sender      *github.User              `json:"sender,omitempty"`
+}
+
+// PushEvent is the push event payload. The vendored go-github package
+// calls it WebHookPayload since it used to be the only webhook payload.
+type PushEvent github.WebHookPayload
@@ -16,3 +16,19 @@ type IssueCommentEventHandler interface {
 type IssuesEventHandler interface {
 	HandleIssuesEvent(rw http.ResponseWriter, r *http.Request, e *IssuesEvent)
 }
+
+type PullRequestEventHandler interface {
+	HandlePullRequestEvent(rw http.ResponseWriter, r *http.Request, e *PullRequestEvent)
+}
+
+type PullRequestReviewEventHandler interface {
+	HandlePullRequestReviewEvent(rw http.ResponseWriter, r *http.Request, e *PullRequestReviewEvent)
+}
+
+type PullRequestReviewCommentEventHandler interface {
+	HandlePullRequestReviewCommentEvent(rw http.ResponseWriter, r *http.Request, e *PullRequestReviewCommentEvent)
+}
+
+type PushEventHandler interface {
+	HandlePushEvent(rw http.ResponseWriter, r *http.Request, e *PushEvent)
+}
@@ -9,6 +9,8 @@ import (
 	// Internal
 	"github.com/salsaflow/salsaflow-daemon/internal/github/events"
 	httputil "github.com/salsaflow/salsaflow-daemon/internal/http"
+	"github.com/salsaflow/salsaflow-daemon/internal/idempotency"
+	"github.com/salsaflow/salsaflow-daemon/internal/metrics"
 
 	// Vendor
 	"github.com/codegangsta/negroni"
@@ -27,6 +29,9 @@ import (
 //
 // In case the event handler does not implement the method for the event type
 // received, WebhookHandler simply returns 202 Accepted and does nothing.
+//
+// Every delivery is processed at most once. The delivery IDs are recorded
+// once the event handler succeeds, redelivered webhooks are skipped.
 type WebhookHandler struct {
 	// Embedded http.Handler
 	http.Handler
@@ -35,28 +40,54 @@ type WebhookHandler struct {
 	eventHandler interface{}
 }
 
-func NewWebhookHandler(eventHandler interface{}) *WebhookHandler {
+// idempotencyNamespace is the idempotency.Store namespace used for GitHub delivery IDs.
+const idempotencyNamespace = "github"
+
+func NewWebhookHandler(eventHandler interface{}) (*WebhookHandler, error) {
 	// Create the handler.
 	handler := &WebhookHandler{
 		eventHandler: eventHandler,
 	}
 
+	// Get the store used to skip redelivered webhooks.
+	store, err := idempotency.Default()
+	if err != nil {
+		return nil, err
+	}
+
 	// Set up the middleware chain.
 	n := negroni.New()
+	n.Use(httputil.NewBodyMiddleware())
+	n.Use(newLogFieldsMiddleware())
 
-	if secret := GetConfig().WebhookSecret; secret != "" {
-		n.Use(newSecretMiddleware(secret))
-	} else {
-		stdLog.Println("WARNING: SFD_GITHUB_WEBHOOK_SECRET is not set")
+	// Use the app installations the webhooks are sent for.
+	app, err := GetApp()
+	if err != nil {
+		return nil, err
+	}
+	if app != nil {
+		n.Use(newInstallationMiddleware())
 	}
 
+	n.Use(idempotency.NewMiddleware(
+		store, idempotencyNamespace, idempotency.HeaderKey("X-GitHub-Delivery")))
 	n.UseHandlerFunc(handler.handleEvent)
 
 	// Set the Negroni instance to be THE handler.
 	handler.Handler = n
 
 	// Return the new handler.
-	return handler
+	return handler, nil
+}
+
+// NewAuthMiddleware returns the middleware verifying the webhook signatures.
+// The signatures are verified before the webhooks are queued,
+// so WebhookHandler itself does not verify them again.
+func NewAuthMiddleware() negroni.Handler {
+	if GetConfig().WebhookSecret == "" {
+		stdLog.Println("WARNING: SFD_GITHUB_WEBHOOK_SECRET is not set")
+	}
+	return newSecretMiddleware(WebhookSecretsForOwner)
 }
 
 func (handler *WebhookHandler) handleEvent(rw http.ResponseWriter, r *http.Request) {
@@ -123,6 +154,74 @@ var specs = map[string]*eventHandlerSpec{
 			})
 		},
 	},
+	"pull_request": &eventHandlerSpec{
+		func(eventHandler interface{}) bool {
+			_, ok := eventHandler.(events.PullRequestEventHandler)
+			return ok
+		},
+		func(eventHandler interface{}) http.Handler {
+			return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+				var event events.PullRequestEvent
+				if err := json.NewDecoder(r.Body).Decode(&event); err != nil {
+					httputil.Error(rw, r, err)
+					return
+				}
+
+				eventHandler.(events.PullRequestEventHandler).HandlePullRequestEvent(rw, r, &event)
+			})
+		},
+	},
+	"pull_request_review": &eventHandlerSpec{
+		func(eventHandler interface{}) bool {
+			_, ok := eventHandler.(events.PullRequestReviewEventHandler)
+			return ok
+		},
+		func(eventHandler interface{}) http.Handler {
+			return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+				var event events.PullRequestReviewEvent
+				if err := json.NewDecoder(r.Body).Decode(&event); err != nil {
+					httputil.Error(rw, r, err)
+					return
+				}
+
+				eventHandler.(events.PullRequestReviewEventHandler).HandlePullRequestReviewEvent(rw, r, &event)
+			})
+		},
+	},
+	"pull_request_review_comment": &eventHandlerSpec{
+		func(eventHandler interface{}) bool {
+			_, ok := eventHandler.(events.PullRequestReviewCommentEventHandler)
+			return ok
+		},
+		func(eventHandler interface{}) http.Handler {
+			return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+				var event events.PullRequestReviewCommentEvent
+				if err := json.NewDecoder(r.Body).Decode(&event); err != nil {
+					httputil.Error(rw, r, err)
+					return
+				}
+
+				eventHandler.(events.PullRequestReviewCommentEventHandler).HandlePullRequestReviewCommentEvent(rw, r, &event)
+			})
+		},
+	},
+	"push": &eventHandlerSpec{
+		func(eventHandler interface{}) bool {
+			_, ok := eventHandler.(events.PushEventHandler)
+			return ok
+		},
+		func(eventHandler interface{}) http.Handler {
+			return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+				var event events.PushEvent
+				if err := json.NewDecoder(r.Body).Decode(&event); err != nil {
+					httputil.Error(rw, r, err)
+					return
+				}
+
+				eventHandler.(events.PushEventHandler).HandlePushEvent(rw, r, &event)
+			})
+		},
+	},
 }
 
 func getEventHandler(eventType string, eventHandler interface{}) http.Handler {
@@ -144,5 +243,6 @@ func getEventHandler(eventType string, eventHandler interface{}) http.Handler {
 }
 
 func accepted(rw http.ResponseWriter, r *http.Request) {
+	metrics.Skip(r)
 	httputil.Status(rw, http.StatusAccepted)
 }
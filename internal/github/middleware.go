@@ -2,14 +2,17 @@ package github
 
 import (
 	// Stdlib
-	"bytes"
+	"context"
 	"crypto/hmac"
 	"crypto/sha1"
+	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
-	"io"
-	"io/ioutil"
+	"errors"
+	"fmt"
+	"hash"
 	"net/http"
+	"strings"
 
 	// Internal
 	httputil "github.com/salsaflow/salsaflow-daemon/internal/http"
@@ -20,46 +23,205 @@ import (
 	"github.com/google/go-github/github"
 )
 
-func newSecretMiddleware(secret string) negroni.HandlerFunc {
+// newSecretMiddleware verifies the webhook signature. The secrets are chosen
+// according to the repository owner since every tenant can use its own secrets.
+// The owner is read from repository.owner.login, the field the event handlers use
+// to pick the API client, so the webhook is always handled with the credentials
+// of the tenant whose secret was used to sign it.
+// The request is accepted when the signature matches any of the secrets.
+func newSecretMiddleware(secretsForOwner func(owner string) ([]string, bool)) negroni.HandlerFunc {
 	return negroni.HandlerFunc(
 		func(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
-			// Read the request body into a buffer.
-			bodyBytes, err := ioutil.ReadAll(r.Body)
+			bodyBytes, err := httputil.ReadBody(r)
 			if err != nil {
 				httputil.Error(rw, r, err)
 				return
 			}
 
-			// Fill the request body again so that it is available in the next handler.
-			r.Body.Close()
-			r.Body = ioutil.NopCloser(bytes.NewBuffer(bodyBytes))
+			// Get the repository owner.
+			repo := getRepoFullName(bodyBytes)
+			owner, err := getRepoOwner(bodyBytes)
+			if err != nil {
+				log.Warn(r, "Invalid webhook payload (repo='%v'): %v", repo, err)
+				httputil.Status(rw, http.StatusUnauthorized)
+				return
+			}
 
-			// Compute the hash.
-			mac := hmac.New(sha1.New, []byte(secret))
-			if _, err := io.Copy(mac, bytes.NewReader(bodyBytes)); err != nil {
-				httputil.Error(rw, r, err)
+			// Get the secrets. There is nothing to verify when no secret is configured.
+			secrets, ok := secretsForOwner(owner)
+			if !ok {
+				log.Warn(r, "No webhook secret available for repository owner '%v'", owner)
+				httputil.Status(rw, http.StatusUnauthorized)
+				return
+			}
+			if len(secrets) == 0 {
+				next(rw, r)
 				return
 			}
 
-			// Compare with the header provided in the request.
-			secretHeader := r.Header.Get("X-Hub-Signature")
-			expected := "sha1=" + hex.EncodeToString(mac.Sum(nil))
-			if secretHeader != expected {
-				repo := getRepoFullName(bodyBytes)
-				log.Warn(r, "HMAC mismatch detected (repo='%v'): expected='%v', got='%v'\n",
-					repo, expected, secretHeader)
+			// Get the signature, SHA-256 is preferred when available.
+			hashFunc, signature, err := parseSignature(r.Header)
+			if err != nil {
+				log.Warn(r, "Invalid webhook signature (repo='%v'): %v", repo, err)
 				httputil.Status(rw, http.StatusUnauthorized)
 				return
 			}
 
+			// Compare with the signatures computed using the secrets.
+			for _, secret := range secrets {
+				mac := hmac.New(hashFunc, []byte(secret))
+				mac.Write(bodyBytes)
+				if hmac.Equal(mac.Sum(nil), signature) {
+					// Call the next handler.
+					next(rw, r)
+					return
+				}
+			}
+
+			log.Warn(r, "HMAC mismatch detected (repo='%v')", repo)
+			httputil.Status(rw, http.StatusUnauthorized)
+		})
+}
+
+// parseSignature returns the signature sent in the request headers together
+// with the hash function that was used to compute it.
+// X-Hub-Signature-256 is used when present, X-Hub-Signature otherwise.
+func parseSignature(header http.Header) (hashFunc func() hash.Hash, signature []byte, err error) {
+	var (
+		value  string
+		prefix string
+	)
+	if value = header.Get("X-Hub-Signature-256"); value != "" {
+		hashFunc, prefix = sha256.New, "sha256="
+	} else if value = header.Get("X-Hub-Signature"); value != "" {
+		hashFunc, prefix = sha1.New, "sha1="
+	} else {
+		return nil, nil, errors.New("signature header missing")
+	}
+
+	if !strings.HasPrefix(value, prefix) {
+		return nil, nil, fmt.Errorf("unexpected signature format: %v", value)
+	}
+	signature, err = hex.DecodeString(strings.TrimPrefix(value, prefix))
+	if err != nil {
+		return nil, nil, fmt.Errorf("malformed signature: %v", err)
+	}
+	return hashFunc, signature, nil
+}
+
+// newInstallationMiddleware records the app installation ID sent in the webhook
+// payload so that the installation the webhook was sent for is used
+// when handling the webhook, see NewClientForRequest.
+func newInstallationMiddleware() negroni.HandlerFunc {
+	return negroni.HandlerFunc(
+		func(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+			bodyBytes, err := httputil.ReadBody(r)
+			if err != nil {
+				httputil.Error(rw, r, err)
+				return
+			}
+
+			var payload struct {
+				Installation *struct {
+					Id int `json:"id"`
+				} `json:"installation"`
+			}
+			if err := json.Unmarshal(bodyBytes, &payload); err == nil && payload.Installation != nil {
+				r = withInstallation(r, payload.Installation.Id)
+			}
+
 			// Call the next handler.
 			next(rw, r)
 		})
 }
 
-func getRepoFullName(body []byte) string {
+type installationKey struct{}
+
+// withInstallation returns a copy of r carrying the given app installation ID.
+func withInstallation(r *http.Request, installationId int) *http.Request {
+	return r.WithContext(context.WithValue(r.Context(), installationKey{}, installationId))
+}
+
+// installationFromRequest returns the app installation ID recorded for r,
+// 0 is returned when there is none.
+func installationFromRequest(r *http.Request) int {
+	id, _ := r.Context().Value(installationKey{}).(int)
+	return id
+}
+
+// newLogFieldsMiddleware annotates the log records for the request
+// with the event type, the repository and the issue or pull request number.
+func newLogFieldsMiddleware() negroni.HandlerFunc {
+	return negroni.HandlerFunc(
+		func(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+			bodyBytes, err := httputil.ReadBody(r)
+			if err != nil {
+				httputil.Error(rw, r, err)
+				return
+			}
+
+			// Add the fields.
+			fields := log.Fields{
+				log.FieldEvent: r.Header.Get("X-GitHub-Event"),
+			}
+			if repo := getRepoFullName(bodyBytes); repo != "" {
+				fields[log.FieldRepository] = repo
+			}
+
+			var payload struct {
+				Issue *struct {
+					Number int `json:"number"`
+				} `json:"issue"`
+				PullRequest *struct {
+					Number int `json:"number"`
+				} `json:"pull_request"`
+			}
+			if err := json.Unmarshal(bodyBytes, &payload); err == nil {
+				switch {
+				case payload.Issue != nil:
+					fields[log.FieldIssue] = payload.Issue.Number
+				case payload.PullRequest != nil:
+					fields[log.FieldIssue] = payload.PullRequest.Number
+				}
+			}
+
+			log.AddFields(r, fields)
+
+			// Call the next handler.
+			next(rw, r)
+		})
+}
+
+// getRepoOwner returns the repository owner login. An error is returned in case
+// the login does not match the owner in the repository full name so that
+// the code using either of the fields gets the same owner.
+// An empty string is returned for payloads not related to any repository.
+func getRepoOwner(body []byte) (string, error) {
 	var payload github.WebHookPayload
 	if err := json.Unmarshal(body, &payload); err != nil {
+		return "", err
+	}
+	if payload.Repo == nil {
+		return "", nil
+	}
+
+	var owner string
+	if payload.Repo.Owner != nil && payload.Repo.Owner.Login != nil {
+		owner = *payload.Repo.Owner.Login
+	}
+	var fullName string
+	if payload.Repo.FullName != nil {
+		fullName = *payload.Repo.FullName
+	}
+	if !strings.EqualFold(strings.SplitN(fullName, "/", 2)[0], owner) {
+		return "", fmt.Errorf("repository owner '%v' does not match repository '%v'", owner, fullName)
+	}
+	return owner, nil
+}
+
+func getRepoFullName(body []byte) string {
+	var payload github.WebHookPayload
+	if err := json.Unmarshal(body, &payload); err != nil || payload.Repo == nil || payload.Repo.FullName == nil {
 		return ""
 	}
 	return *payload.Repo.FullName
@@ -0,0 +1,227 @@
+package github
+
+import (
+	// Stdlib
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"hash"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	// Internal
+	"github.com/salsaflow/salsaflow-daemon/internal/tenants"
+
+	// Vendor
+	"github.com/codegangsta/negroni"
+)
+
+const testingPayload = `{"repository":{"full_name":"acme/repo","owner":{"login":"acme"}}}`
+
+func sign(hashFunc func() hash.Hash, secret string) string {
+	return signPayload(hashFunc, secret, testingPayload)
+}
+
+func signPayload(hashFunc func() hash.Hash, secret, payload string) string {
+	mac := hmac.New(hashFunc, []byte(secret))
+	mac.Write([]byte(payload))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// testingSecretsForOwner simulates two tenants, acme and initech.
+func testingSecretsForOwner(owner string) ([]string, bool) {
+	switch owner {
+	case "acme":
+		return []string{"old", "new"}, true
+	case "initech":
+		return []string{"initech"}, true
+	default:
+		return nil, false
+	}
+}
+
+func newTestingSecretMiddleware() *negroni.Negroni {
+	n := negroni.New()
+	n.Use(newSecretMiddleware(testingSecretsForOwner))
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.WriteHeader(http.StatusAccepted)
+	})
+	return n
+}
+
+func TestSecretMiddleware(t *testing.T) {
+	n := newTestingSecretMiddleware()
+
+	data := []struct {
+		name    string
+		headers map[string]string
+		status  int
+	}{
+		{
+			"sha256 signature, current secret",
+			map[string]string{"X-Hub-Signature-256": "sha256=" + sign(sha256.New, "new")},
+			http.StatusAccepted,
+		},
+		{
+			"sha256 signature, previous secret",
+			map[string]string{"X-Hub-Signature-256": "sha256=" + sign(sha256.New, "old")},
+			http.StatusAccepted,
+		},
+		{
+			"sha1 signature",
+			map[string]string{"X-Hub-Signature": "sha1=" + sign(sha1.New, "new")},
+			http.StatusAccepted,
+		},
+		{
+			"sha256 signature preferred",
+			map[string]string{
+				"X-Hub-Signature-256": "sha256=" + sign(sha256.New, "unknown"),
+				"X-Hub-Signature":     "sha1=" + sign(sha1.New, "new"),
+			},
+			http.StatusUnauthorized,
+		},
+		{
+			"unknown secret",
+			map[string]string{"X-Hub-Signature": "sha1=" + sign(sha1.New, "unknown")},
+			http.StatusUnauthorized,
+		},
+		{
+			"malformed signature",
+			map[string]string{"X-Hub-Signature-256": "sha1=" + sign(sha1.New, "new")},
+			http.StatusUnauthorized,
+		},
+		{
+			"missing signature",
+			nil,
+			http.StatusUnauthorized,
+		},
+	}
+
+	for _, d := range data {
+		r := httptest.NewRequest("POST", "/events", strings.NewReader(testingPayload))
+		for k, v := range d.headers {
+			r.Header.Set(k, v)
+		}
+		rw := httptest.NewRecorder()
+		n.ServeHTTP(rw, r)
+
+		if rw.Code != d.status {
+			t.Errorf("%v: expected status %v, got %v", d.name, d.status, rw.Code)
+		}
+	}
+}
+
+func TestSecretMiddleware_Owner(t *testing.T) {
+	n := newTestingSecretMiddleware()
+
+	data := []struct {
+		name    string
+		payload string
+		secret  string
+		status  int
+	}{
+		{
+			"owner secret",
+			`{"repository":{"full_name":"initech/repo","owner":{"login":"initech"}}}`,
+			"initech",
+			http.StatusAccepted,
+		},
+		{
+			"another owner secret",
+			`{"repository":{"full_name":"initech/repo","owner":{"login":"initech"}}}`,
+			"new",
+			http.StatusUnauthorized,
+		},
+		{
+			"owner login not matching the full name",
+			`{"repository":{"full_name":"acme/repo","owner":{"login":"initech"}}}`,
+			"new",
+			http.StatusUnauthorized,
+		},
+		{
+			"unknown owner",
+			`{"repository":{"full_name":"globex/repo","owner":{"login":"globex"}}}`,
+			"",
+			http.StatusUnauthorized,
+		},
+		{
+			"no repository",
+			`{"zen":"Keep it logically awesome."}`,
+			"",
+			http.StatusUnauthorized,
+		},
+	}
+
+	for _, d := range data {
+		r := httptest.NewRequest("POST", "/events", strings.NewReader(d.payload))
+		r.Header.Set("X-Hub-Signature-256", "sha256="+signPayload(sha256.New, d.secret, d.payload))
+		rw := httptest.NewRecorder()
+		n.ServeHTTP(rw, r)
+
+		if rw.Code != d.status {
+			t.Errorf("%v: expected status %v, got %v", d.name, d.status, rw.Code)
+		}
+	}
+}
+
+func TestWebhookSecrets(t *testing.T) {
+	var (
+		c       = Config{WebhookSecret: "global"}
+		noEnv   = Config{}
+		acme    = &tenants.Tenant{Id: "acme", GitHub: &tenants.GitHub{WebhookSecret: "acme"}}
+		initech = &tenants.Tenant{Id: "initech", GitHub: &tenants.GitHub{}}
+	)
+
+	data := []struct {
+		name       string
+		c          Config
+		t          *tenants.Tenant
+		configured bool
+		secrets    []string
+		ok         bool
+	}{
+		{"tenant secret", c, acme, true, []string{"acme"}, true},
+		{"tenant falling back to the environment", c, initech, true, []string{"global"}, true},
+		{"tenant without any secret", noEnv, initech, true, nil, false},
+		{"unknown owner", c, nil, true, nil, false},
+		{"single-tenant mode", c, nil, false, []string{"global"}, true},
+		{"single-tenant mode without secret", noEnv, nil, false, nil, true},
+	}
+
+	for _, d := range data {
+		secrets, ok := webhookSecrets(d.c, d.t, d.configured)
+		if !reflect.DeepEqual(secrets, d.secrets) || ok != d.ok {
+			t.Errorf("%v: expected (%v, %v), got (%v, %v)", d.name, d.secrets, d.ok, secrets, ok)
+		}
+	}
+}
+
+func TestInstallationMiddleware(t *testing.T) {
+	data := []struct {
+		payload string
+		id      int
+	}{
+		{`{"installation":{"id":7},"repository":{"full_name":"acme/repo"}}`, 7},
+		{`{"repository":{"full_name":"acme/repo"}}`, 0},
+	}
+
+	for _, d := range data {
+		var id int
+		n := negroni.New()
+		n.Use(newInstallationMiddleware())
+		n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+			id = installationFromRequest(r)
+			rw.WriteHeader(http.StatusAccepted)
+		})
+
+		r := httptest.NewRequest("POST", "/events", strings.NewReader(d.payload))
+		n.ServeHTTP(httptest.NewRecorder(), r)
+		if id != d.id {
+			t.Errorf("%v: expected installation %v, got %v", d.payload, d.id, id)
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package repoconfig
+
+import (
+	// Stdlib
+	"log"
+	"time"
+
+	// Vendor
+	"github.com/kelseyhightower/envconfig"
+)
+
+type Config struct {
+	// TrunkBranch is the branch the repository configuration is looked for first.
+	// The trunk branch specified in the configuration file takes precedence.
+	TrunkBranch string `envconfig:"TRUNK_BRANCH" default:"develop"`
+
+	// The repository configuration is re-fetched after the TTL expires
+	// even when no push event invalidating the cache is received.
+	RawCacheTTL string `envconfig:"REPO_CONFIG_TTL" default:"1h"`
+
+	// CacheTTL contains parsed RawCacheTTL.
+	CacheTTL time.Duration
+}
+
+var config Config
+
+func init() {
+	if err := envconfig.Process("SFD_GITHUB", &config); err != nil {
+		log.Fatalln("Fatal error while parsing GitHub repository config:", err)
+	}
+
+	ttl, err := time.ParseDuration(config.RawCacheTTL)
+	if err != nil {
+		log.Fatalln("Fatal error while parsing SFD_GITHUB_REPO_CONFIG_TTL:", err)
+	}
+	config.CacheTTL = ttl
+
+	defaultCache = NewCache(config.TrunkBranch, config.CacheTTL)
+}
+
+func GetConfig() Config {
+	return config
+}
@@ -0,0 +1,274 @@
+// Package repoconfig provides access to the SalsaFlow configuration
+// committed in the repositories, i.e. to .salsaflow/config.json.
+//
+// The file is fetched from the trunk branch using the contents API
+// and it is cached until a push event touching the file is received.
+// The trunk branch is the one specified in the file, salsaflow.core.git.trunk_branch,
+// falling back to SFD_GITHUB_TRUNK_BRANCH.
+package repoconfig
+
+import (
+	// Stdlib
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	// Internal
+	"github.com/salsaflow/salsaflow-daemon/internal/github/events"
+	httputil "github.com/salsaflow/salsaflow-daemon/internal/http"
+	sfLog "github.com/salsaflow/salsaflow-daemon/internal/log"
+
+	// Vendor
+	"github.com/google/go-github/github"
+)
+
+// Path is the path of the configuration file relative to the repository root.
+const Path = ".salsaflow/config.json"
+
+// GitSectionKey is the key of the section containing the core git configuration.
+const GitSectionKey = "salsaflow.core.git"
+
+// File represents the repository configuration file.
+//
+// The configuration is split into sections under the configuration key,
+// every module decodes its own section, which is keyed by the module ID:
+//
+//	{
+//	  "configuration": {
+//	    "salsaflow.core.git": {"trunk_branch": "develop"},
+//	    "salsaflow.modules.issuetracking.github": {"story_labels": ["bug"]}
+//	  }
+//	}
+type File struct {
+	sections map[string]json.RawMessage
+}
+
+// Section decodes the section of the given key into v.
+// ok is false when the file or the section is missing.
+func (f *File) Section(key string, v interface{}) (ok bool, err error) {
+	if f == nil {
+		return false, nil
+	}
+	raw, ok := f.sections[key]
+	if !ok {
+		return false, nil
+	}
+	if err := json.Unmarshal(raw, v); err != nil {
+		return false, fmt.Errorf("%v: invalid section %v: %v", Path, key, err)
+	}
+	return true, nil
+}
+
+// TrunkBranch returns the trunk branch as specified in the file,
+// an empty string in case it is not specified.
+func (f *File) TrunkBranch() string {
+	var git struct {
+		TrunkBranch string `json:"trunk_branch"`
+	}
+	if ok, err := f.Section(GitSectionKey, &git); !ok || err != nil {
+		return ""
+	}
+	return git.TrunkBranch
+}
+
+// Cache ------------------------------------------------------------------------
+
+type cacheEntry struct {
+	file        *File
+	trunkBranch string
+	fetchedAt   time.Time
+}
+
+// Cache loads the repository configuration and keeps it in memory.
+type Cache struct {
+	// trunkBranch is the branch the file is looked for first.
+	// The file is then loaded from the trunk branch it specifies.
+	trunkBranch string
+	ttl         time.Duration
+
+	mu      sync.Mutex
+	entries map[string]*cacheEntry
+}
+
+func NewCache(trunkBranch string, ttl time.Duration) *Cache {
+	return &Cache{
+		trunkBranch: trunkBranch,
+		ttl:         ttl,
+		entries:     make(map[string]*cacheEntry),
+	}
+}
+
+// Load returns the configuration for the given repository. The file is fetched
+// in case it is not cached yet or the cache entry has expired.
+//
+// A missing file is not an error, an empty File is returned in that case
+// so that the modules fall back to their environment configuration.
+func (cache *Cache) Load(client *github.Client, owner, repo string) (*File, error) {
+	key := cacheKey(owner, repo)
+
+	cache.mu.Lock()
+	entry, ok := cache.entries[key]
+	cache.mu.Unlock()
+	if ok && time.Since(entry.fetchedAt) < cache.ttl {
+		return entry.file, nil
+	}
+
+	entry, err := cache.fetch(client, owner, repo)
+	if err != nil {
+		return nil, err
+	}
+
+	cache.mu.Lock()
+	cache.entries[key] = entry
+	cache.mu.Unlock()
+	return entry.file, nil
+}
+
+// Invalidate drops the cached configuration for the given repository.
+func (cache *Cache) Invalidate(owner, repo string) {
+	cache.mu.Lock()
+	delete(cache.entries, cacheKey(owner, repo))
+	cache.mu.Unlock()
+}
+
+// InvalidateOnPush invalidates the cache entry for the repository the push
+// event belongs to in case the push modifies the configuration file
+// in the trunk branch. It returns true when the cache entry was invalidated.
+func (cache *Cache) InvalidateOnPush(event *events.PushEvent) bool {
+	if event.Ref == nil || event.Repo == nil || event.Repo.FullName == nil {
+		return false
+	}
+	parts := strings.SplitN(*event.Repo.FullName, "/", 2)
+	if len(parts) != 2 {
+		return false
+	}
+
+	// Only the cached repositories can be invalidated.
+	cache.mu.Lock()
+	entry, ok := cache.entries[cacheKey(parts[0], parts[1])]
+	cache.mu.Unlock()
+	if !ok {
+		return false
+	}
+
+	// The file could have been modified in the branch it was looked for first,
+	// possibly changing the trunk branch, so that branch counts as well.
+	branch := strings.TrimPrefix(*event.Ref, "refs/heads/")
+	if branch != entry.trunkBranch && branch != cache.trunkBranch {
+		return false
+	}
+
+	touched := func(paths []string) bool {
+		for _, path := range paths {
+			if path == Path {
+				return true
+			}
+		}
+		return false
+	}
+
+	for _, commit := range event.Commits {
+		if touched(commit.Added) || touched(commit.Modified) || touched(commit.Removed) {
+			cache.Invalidate(parts[0], parts[1])
+			return true
+		}
+	}
+	return false
+}
+
+// fetch fetches the file from the default trunk branch first. In case the file
+// specifies a different trunk branch, the file is fetched again from that branch.
+func (cache *Cache) fetch(client *github.Client, owner, repo string) (*cacheEntry, error) {
+	file, err := fetchFile(client, owner, repo, cache.trunkBranch)
+	if err != nil {
+		return nil, err
+	}
+
+	trunkBranch := cache.trunkBranch
+	if branch := file.TrunkBranch(); branch != "" && branch != trunkBranch {
+		file, err = fetchFile(client, owner, repo, branch)
+		if err != nil {
+			return nil, err
+		}
+		trunkBranch = branch
+	}
+
+	return &cacheEntry{file, trunkBranch, time.Now()}, nil
+}
+
+func fetchFile(client *github.Client, owner, repo, branch string) (*File, error) {
+	content, _, resp, err := client.Repositories.GetContents(owner, repo, Path,
+		&github.RepositoryContentGetOptions{Ref: branch})
+	if err != nil {
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
+			return &File{}, nil
+		}
+		return nil, err
+	}
+	if content == nil {
+		return nil, fmt.Errorf("%v/%v: %v is not a file", owner, repo, Path)
+	}
+
+	raw, err := content.Decode()
+	if err != nil {
+		return nil, err
+	}
+
+	var file struct {
+		Configuration map[string]json.RawMessage `json:"configuration"`
+	}
+	if err := json.Unmarshal(raw, &file); err != nil {
+		return nil, fmt.Errorf("%v/%v: failed to parse %v: %v", owner, repo, Path, err)
+	}
+	return &File{file.Configuration}, nil
+}
+
+func cacheKey(owner, repo string) string {
+	return strings.ToLower(owner + "/" + repo)
+}
+
+// Default cache ----------------------------------------------------------------
+
+// defaultCache is set up in init once the configuration is parsed.
+var defaultCache *Cache
+
+// Load loads the repository configuration using the default cache.
+//
+// In case the file cannot be fetched, the error is logged and an empty File
+// is returned so that the modules fall back to their environment configuration.
+// The file is fetched again next time then.
+func Load(client *github.Client, owner, repo string) *File {
+	file, err := defaultCache.Load(client, owner, repo)
+	if err != nil {
+		log.Printf("WARNING: %v/%v: failed to load %v, using the default configuration: %v\n",
+			owner, repo, Path, err)
+		return &File{}
+	}
+	return file
+}
+
+// InvalidateOnPush calls InvalidateOnPush on the default cache.
+func InvalidateOnPush(event *events.PushEvent) bool {
+	return defaultCache.InvalidateOnPush(event)
+}
+
+// PushEventHandler implements events.PushEventHandler. It drops the cached
+// configuration when a push touches it. It is to be embedded into the event
+// handlers of the modules using the repository configuration.
+type PushEventHandler struct{}
+
+// HandlePushEvent implements events.PushEventHandler.
+func (PushEventHandler) HandlePushEvent(
+	rw http.ResponseWriter,
+	r *http.Request,
+	event *events.PushEvent,
+) {
+	if InvalidateOnPush(event) {
+		sfLog.Info(r, "%v modified in %v, cached configuration dropped", Path, *event.Repo.FullName)
+	}
+	httputil.Status(rw, http.StatusAccepted)
+}
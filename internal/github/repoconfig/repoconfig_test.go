@@ -0,0 +1,257 @@
+package repoconfig
+
+import (
+	// Stdlib
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	// Internal
+	"github.com/salsaflow/salsaflow-daemon/internal/github/events"
+
+	// Vendor
+	"github.com/google/go-github/github"
+)
+
+// testingContent is a copy of a real .salsaflow/config.json.
+const testingContent = `{
+  "salsaflow_enabled_timestamp": "2015-06-08T08:40:45.923692728+02:00",
+  "active_modules": {
+    "issue_tracking": "salsaflow.modules.issuetracking.github",
+    "code_review": "salsaflow.modules.codereview.github",
+    "release_notes": "salsaflow.modules.releasenotes.github"
+  },
+  "configuration": {
+    "salsaflow.core.git": {
+      "trunk_branch": "develop",
+      "release_branch": "release",
+      "staging_branch": "stage",
+      "stable_branch": "master"
+    },
+    "salsaflow.core.versioning": {
+      "trunk_suffix": "dev",
+      "testing_suffix": "qa",
+      "staging_suffix": "stage"
+    },
+    "salsaflow.modules.codereview.github": {
+      "review_issue_label": "review",
+      "story_implemented_label": "implemented"
+    },
+    "salsaflow.modules.issuetracking.github": {
+      "story_labels": [
+        "enhancement",
+        "bug"
+      ],
+      "state_labels": {
+        "approved": "approved",
+        "being_implemented": "being implemented",
+        "implemented": "implemented",
+        "reviewed": "reviewed",
+        "skip_review": "no review",
+        "passed_testing": "qa+",
+        "failed_testing": "qa-",
+        "skip_testing": "no qa",
+        "staged_for_acceptance": "staged",
+        "client_rejected": "rejected"
+      },
+      "skip_release_check_labels": [
+        "duplicate",
+        "invalid"
+      ]
+    },
+    "salsaflow.modules.releasenotes.github": {}
+  }
+}`
+
+const testingSectionKey = "salsaflow.modules.issuetracking.github"
+
+// testingFiles contains the configuration files being served,
+// the keys being "repository@branch".
+var testingFiles = map[string]string{
+	"repo@develop": testingContent,
+
+	// The file in the default trunk branch points to another trunk branch.
+	"trunk@develop": strings.Replace(testingContent, `"trunk_branch": "develop"`, `"trunk_branch": "main"`, 1),
+	"trunk@main": strings.Replace(strings.Replace(testingContent,
+		`"trunk_branch": "develop"`, `"trunk_branch": "main"`, 1),
+		`"enhancement"`, `"feature"`, 1),
+}
+
+// newTestingClient returns a client talking to a GitHub stand-in that serves
+// testingFiles for owner/* and 404 for anything else.
+// The number of content requests is counted in requests.
+func newTestingClient(t *testing.T, requests *int) (*github.Client, func()) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		path := strings.TrimPrefix(r.URL.Path, "/repos/owner/")
+		repo := strings.TrimSuffix(path, "/contents/"+Path)
+		content, ok := testingFiles[repo+"@"+r.URL.Query().Get("ref")]
+		if !ok {
+			http.Error(rw, `{"message":"Not Found"}`, http.StatusNotFound)
+			return
+		}
+
+		*requests++
+		rw.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(rw).Encode(map[string]string{
+			"type":     "file",
+			"encoding": "base64",
+			"content":  base64.StdEncoding.EncodeToString([]byte(content)),
+		})
+	}))
+
+	client := github.NewClient(nil)
+	client.BaseURL, _ = url.Parse(server.URL + "/")
+	return client, server.Close
+}
+
+func storyLabels(t *testing.T, file *File) []string {
+	var section struct {
+		StoryLabels []string `json:"story_labels"`
+	}
+	ok, err := file.Section(testingSectionKey, &section)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ok {
+		t.Fatalf("section %v not found", testingSectionKey)
+	}
+	return section.StoryLabels
+}
+
+func TestCache_Load(t *testing.T) {
+	var requests int
+	client, closeServer := newTestingClient(t, &requests)
+	defer closeServer()
+
+	cache := NewCache("develop", time.Hour)
+
+	// The cache key is case-insensitive the same way GitHub repository names are.
+	for _, repo := range []string{"repo", "Repo"} {
+		file, err := cache.Load(client, "owner", repo)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		expected := []string{"enhancement", "bug"}
+		if labels := storyLabels(t, file); !reflect.DeepEqual(labels, expected) {
+			t.Errorf("expected story labels %q, got %q", expected, labels)
+		}
+		if branch := file.TrunkBranch(); branch != "develop" {
+			t.Errorf("expected trunk branch develop, got %v", branch)
+		}
+	}
+	if requests != 1 {
+		t.Errorf("expected the file to be fetched once, fetched %v times", requests)
+	}
+}
+
+func TestCache_Load_TrunkBranch(t *testing.T) {
+	var requests int
+	client, closeServer := newTestingClient(t, &requests)
+	defer closeServer()
+
+	// The file is loaded from the trunk branch it specifies.
+	file, err := NewCache("develop", time.Hour).Load(client, "owner", "trunk")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{"feature", "bug"}
+	if labels := storyLabels(t, file); !reflect.DeepEqual(labels, expected) {
+		t.Errorf("expected story labels %q, got %q", expected, labels)
+	}
+	if requests != 2 {
+		t.Errorf("expected the file to be fetched twice, fetched %v times", requests)
+	}
+}
+
+func TestCache_Load_MissingFile(t *testing.T) {
+	var requests int
+	client, closeServer := newTestingClient(t, &requests)
+	defer closeServer()
+
+	file, err := NewCache("develop", time.Hour).Load(client, "owner", "other")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var v interface{}
+	if ok, err := file.Section(testingSectionKey, &v); ok || err != nil {
+		t.Errorf("expected no section, got ok=%v, err=%v", ok, err)
+	}
+}
+
+func TestCache_InvalidateOnPush(t *testing.T) {
+	var requests int
+	client, closeServer := newTestingClient(t, &requests)
+	defer closeServer()
+
+	cache := NewCache("develop", time.Hour)
+	for _, repo := range []string{"repo", "trunk"} {
+		if _, err := cache.Load(client, "owner", repo); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	push := func(repo, ref string, modified ...string) bool {
+		return cache.InvalidateOnPush(&events.PushEvent{
+			Ref: github.String(ref),
+			Repo: &github.Repository{
+				FullName: github.String("owner/" + repo),
+			},
+			Commits: []github.WebHookCommit{
+				{Modified: modified},
+			},
+		})
+	}
+
+	if push("repo", "refs/heads/develop", "README.md") {
+		t.Error("invalidated on a push not touching the configuration file")
+	}
+	if push("repo", "refs/heads/feature", Path) {
+		t.Error("invalidated on a push into a non-trunk branch")
+	}
+	if push("other", "refs/heads/develop", Path) {
+		t.Error("invalidated a repository that is not cached")
+	}
+	if !push("repo", "refs/heads/develop", "README.md", Path) {
+		t.Error("not invalidated on a push touching the configuration file")
+	}
+	if !push("trunk", "refs/heads/main", Path) {
+		t.Error("not invalidated on a push into the trunk branch specified in the file")
+	}
+
+	requests = 0
+	for _, repo := range []string{"repo", "trunk"} {
+		if _, err := cache.Load(client, "owner", repo); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if requests != 3 {
+		t.Errorf("expected the files to be fetched 3 times, fetched %v times", requests)
+	}
+}
+
+func TestLoad_FetchError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		http.Error(rw, `{"message":"Server Error"}`, http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	client := github.NewClient(nil)
+	client.BaseURL, _ = url.Parse(server.URL + "/")
+
+	// The error is only logged, the modules fall back to the environment.
+	file := Load(client, "owner", "broken")
+
+	var v interface{}
+	if ok, err := file.Section(testingSectionKey, &v); ok || err != nil {
+		t.Errorf("expected no section, got ok=%v, err=%v", ok, err)
+	}
+}
@@ -0,0 +1,60 @@
+package gitlab
+
+import (
+	// Stdlib
+	"net/http"
+	"net/url"
+	"strings"
+
+	// Internal
+	"github.com/salsaflow/salsaflow-daemon/internal/errs"
+	"github.com/salsaflow/salsaflow-daemon/internal/jsonapi"
+)
+
+// Client is a client for the tiny subset of the GitLab REST API v4
+// that is needed by the GitLab modules.
+type Client struct {
+	*jsonapi.Client
+
+	Commits       *CommitsService
+	Issues        *IssuesService
+	MergeRequests *MergeRequestsService
+}
+
+// NewClient returns a new GitLab API client
+// that uses the access token read from the environment.
+func NewClient() (*Client, error) {
+	c := GetConfig()
+	if c.Token == "" {
+		return nil, &errs.ErrVarNotSet{VariableName: "SFD_GITLAB_TOKEN"}
+	}
+	return NewClientWithToken(c.BaseURL, c.Token)
+}
+
+// NewClientWithToken returns a client for the GitLab instance
+// running at baseURL that uses the given access token.
+func NewClientWithToken(baseURL, token string) (*Client, error) {
+	apiClient, err := jsonapi.NewClient(
+		"GitLab", strings.TrimSuffix(baseURL, "/")+"/api/v4/", func(req *http.Request) {
+			req.Header.Set("PRIVATE-TOKEN", token)
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	client := &Client{Client: apiClient}
+	client.Commits = &CommitsService{client}
+	client.Issues = &IssuesService{client}
+	client.MergeRequests = &MergeRequestsService{client}
+	return client, nil
+}
+
+// projectPath returns the API path for the given project.
+// The project can be specified using its numeric ID or its full path.
+func projectPath(project string, rest ...string) string {
+	path := "projects/" + url.PathEscape(project)
+	for _, part := range rest {
+		path += "/" + part
+	}
+	return path
+}
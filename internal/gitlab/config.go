@@ -0,0 +1,27 @@
+package gitlab
+
+import (
+	// Stdlib
+	"log"
+
+	// Vendor
+	"github.com/kelseyhightower/envconfig"
+)
+
+type Config struct {
+	BaseURL      string `envconfig:"BASE_URL" default:"https://gitlab.com"`
+	WebhookToken string `envconfig:"WEBHOOK_TOKEN"`
+	Token        string `envconfig:"TOKEN"`
+}
+
+var config Config
+
+func init() {
+	if err := envconfig.Process("SFD_GITLAB", &config); err != nil {
+		log.Fatalln("Fatal error while parsing GitLab config:", err)
+	}
+}
+
+func GetConfig() Config {
+	return config
+}
@@ -0,0 +1,84 @@
+package events
+
+// The types in this package follow the GitLab webhook payloads,
+// which differ slightly from the resources returned by the REST API.
+
+type User struct {
+	Id       int    `json:"id"`
+	Name     string `json:"name"`
+	Username string `json:"username"`
+}
+
+type Project struct {
+	Id                int    `json:"id"`
+	Name              string `json:"name"`
+	PathWithNamespace string `json:"path_with_namespace"`
+	WebURL            string `json:"web_url"`
+}
+
+type Label struct {
+	Id    int    `json:"id"`
+	Title string `json:"title"`
+}
+
+type Commit struct {
+	Id  string `json:"id"`
+	URL string `json:"url"`
+}
+
+type MergeRequest struct {
+	Id          int     `json:"id"`
+	Iid         int     `json:"iid"`
+	Title       string  `json:"title"`
+	Description string  `json:"description"`
+	State       string  `json:"state"`
+	Action      string  `json:"action"`
+	URL         string  `json:"url"`
+	LastCommit  *Commit `json:"last_commit"`
+}
+
+type Issue struct {
+	Id          int    `json:"id"`
+	Iid         int    `json:"iid"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	State       string `json:"state"`
+	Action      string `json:"action"`
+	URL         string `json:"url"`
+}
+
+type Note struct {
+	Id           int    `json:"id"`
+	Note         string `json:"note"`
+	NoteableType string `json:"noteable_type"`
+	CommitId     string `json:"commit_id"`
+	URL          string `json:"url"`
+}
+
+type MergeRequestEvent struct {
+	ObjectKind       string        `json:"object_kind"`
+	User             *User         `json:"user"`
+	Project          *Project      `json:"project"`
+	ObjectAttributes *MergeRequest `json:"object_attributes"`
+	Labels           []*Label      `json:"labels"`
+}
+
+type IssueEvent struct {
+	ObjectKind       string   `json:"object_kind"`
+	User             *User    `json:"user"`
+	Project          *Project `json:"project"`
+	ObjectAttributes *Issue   `json:"object_attributes"`
+	Labels           []*Label `json:"labels"`
+}
+
+// NoteEvent is sent when a comment is created. Depending on NoteableType,
+// either MergeRequest, Issue or Commit is set.
+type NoteEvent struct {
+	ObjectKind       string        `json:"object_kind"`
+	User             *User         `json:"user"`
+	Project          *Project      `json:"project"`
+	ObjectAttributes *Note         `json:"object_attributes"`
+	MergeRequest     *MergeRequest `json:"merge_request"`
+	Issue            *Issue        `json:"issue"`
+	Commit           *Commit       `json:"commit"`
+}
@@ -0,0 +1,18 @@
+package events
+
+import (
+	// Stdlib
+	"net/http"
+)
+
+type MergeRequestEventHandler interface {
+	HandleMergeRequestEvent(rw http.ResponseWriter, r *http.Request, e *MergeRequestEvent)
+}
+
+type IssueEventHandler interface {
+	HandleIssueEvent(rw http.ResponseWriter, r *http.Request, e *IssueEvent)
+}
+
+type NoteEventHandler interface {
+	HandleNoteEvent(rw http.ResponseWriter, r *http.Request, e *NoteEvent)
+}
@@ -0,0 +1,184 @@
+package gitlab
+
+import (
+	// Stdlib
+	"encoding/json"
+	stdLog "log"
+	"net/http"
+
+	// Internal
+	"github.com/salsaflow/salsaflow-daemon/internal/gitlab/events"
+	httputil "github.com/salsaflow/salsaflow-daemon/internal/http"
+	"github.com/salsaflow/salsaflow-daemon/internal/idempotency"
+	"github.com/salsaflow/salsaflow-daemon/internal/metrics"
+
+	// Vendor
+	"github.com/codegangsta/negroni"
+)
+
+// WebhookHandler --------------------------------------------------------------
+
+// WebhookHandler provides a framework for handling GitLab webhooks,
+// it is the GitLab counterpart of github.WebhookHandler.
+//
+// The incoming webhooks are routed according to the X-Gitlab-Event header
+// into the matching methods of the event handler object, e.g. a merge request
+// hook is passed into HandleMergeRequestEvent. All available event handling
+// methods can be found in the events package.
+//
+// In case the event handler does not implement the method for the event type
+// received, WebhookHandler simply returns 202 Accepted and does nothing.
+type WebhookHandler struct {
+	// Embedded http.Handler
+	http.Handler
+
+	// The event handler being used for this webhook handler.
+	eventHandler interface{}
+}
+
+// idempotencyNamespace is the idempotency.Store namespace used for GitLab event UUIDs.
+// Older GitLab versions do not send the event UUID, such events are always processed.
+const idempotencyNamespace = "gitlab"
+
+func NewWebhookHandler(eventHandler interface{}) (*WebhookHandler, error) {
+	// Create the handler.
+	handler := &WebhookHandler{
+		eventHandler: eventHandler,
+	}
+
+	// Get the store used to skip redelivered webhooks.
+	store, err := idempotency.Default()
+	if err != nil {
+		return nil, err
+	}
+
+	// Set up the middleware chain.
+	n := negroni.New()
+	n.Use(httputil.NewBodyMiddleware())
+	n.Use(newLogFieldsMiddleware())
+	n.Use(idempotency.NewMiddleware(
+		store, idempotencyNamespace, idempotency.HeaderKey("X-Gitlab-Event-UUID")))
+	n.UseHandlerFunc(handler.handleEvent)
+
+	// Set the Negroni instance to be THE handler.
+	handler.Handler = n
+
+	// Return the new handler.
+	return handler, nil
+}
+
+// NewAuthMiddleware returns the middleware checking the webhook token.
+// The token is checked before the webhooks are queued,
+// so WebhookHandler itself does not check it again.
+// nil is returned in case no token is configured.
+func NewAuthMiddleware() negroni.Handler {
+	token := GetConfig().WebhookToken
+	if token == "" {
+		stdLog.Println("WARNING: SFD_GITLAB_WEBHOOK_TOKEN is not set")
+		return nil
+	}
+	// GitLab does not sign webhooks, it sends the configured secret token
+	// in the X-Gitlab-Token header instead.
+	return httputil.NewTokenMiddleware("GitLab", token, httputil.HeaderToken("X-Gitlab-Token"))
+}
+
+func (handler *WebhookHandler) handleEvent(rw http.ResponseWriter, r *http.Request) {
+	// Get the right event handler and execute it.
+	getEventHandler(r.Header.Get("X-Gitlab-Event"), handler.eventHandler).ServeHTTP(rw, r)
+}
+
+// Event handlers --------------------------------------------------------------
+
+type eventHandlerSpec struct {
+	isHandlerCompatible func(eventHandler interface{}) bool
+	newHandler          func(eventHandler interface{}) http.Handler
+}
+
+var (
+	mergeRequestHookSpec = &eventHandlerSpec{
+		func(eventHandler interface{}) bool {
+			_, ok := eventHandler.(events.MergeRequestEventHandler)
+			return ok
+		},
+		func(eventHandler interface{}) http.Handler {
+			return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+				var event events.MergeRequestEvent
+				if err := json.NewDecoder(r.Body).Decode(&event); err != nil {
+					httputil.Error(rw, r, err)
+					return
+				}
+
+				eventHandler.(events.MergeRequestEventHandler).HandleMergeRequestEvent(rw, r, &event)
+			})
+		},
+	}
+
+	issueHookSpec = &eventHandlerSpec{
+		func(eventHandler interface{}) bool {
+			_, ok := eventHandler.(events.IssueEventHandler)
+			return ok
+		},
+		func(eventHandler interface{}) http.Handler {
+			return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+				var event events.IssueEvent
+				if err := json.NewDecoder(r.Body).Decode(&event); err != nil {
+					httputil.Error(rw, r, err)
+					return
+				}
+
+				eventHandler.(events.IssueEventHandler).HandleIssueEvent(rw, r, &event)
+			})
+		},
+	}
+
+	noteHookSpec = &eventHandlerSpec{
+		func(eventHandler interface{}) bool {
+			_, ok := eventHandler.(events.NoteEventHandler)
+			return ok
+		},
+		func(eventHandler interface{}) http.Handler {
+			return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+				var event events.NoteEvent
+				if err := json.NewDecoder(r.Body).Decode(&event); err != nil {
+					httputil.Error(rw, r, err)
+					return
+				}
+
+				eventHandler.(events.NoteEventHandler).HandleNoteEvent(rw, r, &event)
+			})
+		},
+	}
+)
+
+// Confidential issues and notes are delivered using separate event types,
+// but the payloads are the same, so they are handled the same way.
+var specs = map[string]*eventHandlerSpec{
+	"Merge Request Hook":      mergeRequestHookSpec,
+	"Issue Hook":              issueHookSpec,
+	"Confidential Issue Hook": issueHookSpec,
+	"Note Hook":               noteHookSpec,
+	"Confidential Note Hook":  noteHookSpec,
+}
+
+func getEventHandler(eventType string, eventHandler interface{}) http.Handler {
+	// Get the spec for the given event type.
+	spec, ok := specs[eventType]
+	if !ok {
+		return http.HandlerFunc(accepted)
+	}
+
+	// Check whether eventHandler implements the right interface.
+	// In case this is not the case, we simply return 202 Accepted.
+	if !spec.isHandlerCompatible(eventHandler) {
+		return http.HandlerFunc(accepted)
+	}
+
+	// In case eventHandler implements the right interface,
+	// we use eventHandler to handle the request.
+	return spec.newHandler(eventHandler)
+}
+
+func accepted(rw http.ResponseWriter, r *http.Request) {
+	metrics.Skip(r)
+	httputil.Status(rw, http.StatusAccepted)
+}
@@ -0,0 +1,63 @@
+package gitlab
+
+import (
+	// Stdlib
+	"encoding/json"
+	"net/http"
+
+	// Internal
+	httputil "github.com/salsaflow/salsaflow-daemon/internal/http"
+	"github.com/salsaflow/salsaflow-daemon/internal/log"
+
+	// Vendor
+	"github.com/codegangsta/negroni"
+)
+
+// newLogFieldsMiddleware annotates the log records for the request
+// with the event type, the project and the issue or merge request IID.
+func newLogFieldsMiddleware() negroni.HandlerFunc {
+	return negroni.HandlerFunc(
+		func(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+			bodyBytes, err := httputil.ReadBody(r)
+			if err != nil {
+				httputil.Error(rw, r, err)
+				return
+			}
+
+			// Add the fields.
+			eventType := r.Header.Get("X-Gitlab-Event")
+			fields := log.Fields{
+				log.FieldEvent: eventType,
+			}
+
+			type object struct {
+				Iid int `json:"iid"`
+			}
+			var payload struct {
+				Project *struct {
+					PathWithNamespace string `json:"path_with_namespace"`
+				} `json:"project"`
+				ObjectAttributes *object `json:"object_attributes"`
+				MergeRequest     *object `json:"merge_request"`
+				Issue            *object `json:"issue"`
+			}
+			if err := json.Unmarshal(bodyBytes, &payload); err == nil {
+				if payload.Project != nil {
+					fields[log.FieldRepository] = payload.Project.PathWithNamespace
+				}
+				switch {
+				case payload.MergeRequest != nil:
+					fields[log.FieldIssue] = payload.MergeRequest.Iid
+				case payload.Issue != nil:
+					fields[log.FieldIssue] = payload.Issue.Iid
+				case payload.ObjectAttributes != nil && payload.ObjectAttributes.Iid != 0:
+					fields[log.FieldIssue] = payload.ObjectAttributes.Iid
+				}
+			}
+
+			log.AddFields(r, fields)
+
+			// Call the next handler.
+			next(rw, r)
+		})
+}
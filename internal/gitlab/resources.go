@@ -0,0 +1,182 @@
+package gitlab
+
+import (
+	// Stdlib
+	"net/http"
+	"strconv"
+)
+
+type User struct {
+	Id       int    `json:"id"`
+	Name     string `json:"name"`
+	Username string `json:"username"`
+}
+
+type Project struct {
+	Id                int    `json:"id"`
+	Name              string `json:"name"`
+	PathWithNamespace string `json:"path_with_namespace"`
+	WebURL            string `json:"web_url"`
+}
+
+type MergeRequest struct {
+	Id          int    `json:"id"`
+	Iid         int    `json:"iid"`
+	ProjectId   int    `json:"project_id"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	State       string `json:"state"`
+	WebURL      string `json:"web_url"`
+}
+
+type Issue struct {
+	Id          int      `json:"id"`
+	Iid         int      `json:"iid"`
+	ProjectId   int      `json:"project_id"`
+	Title       string   `json:"title"`
+	Description string   `json:"description"`
+	State       string   `json:"state"`
+	Labels      []string `json:"labels"`
+	WebURL      string   `json:"web_url"`
+}
+
+type Note struct {
+	Id   int    `json:"id,omitempty"`
+	Body string `json:"body"`
+}
+
+// CommitsService ---------------------------------------------------------------
+
+type CommitsService struct {
+	client *Client
+}
+
+// CreateComment posts a comment for the given commit.
+// Commit comments are not notes in the API, hence the different payload.
+func (srv *CommitsService) CreateComment(project, sha, comment string) (*http.Response, error) {
+	body := map[string]string{"note": comment}
+	req, err := srv.client.NewRequest("POST", projectPath(project, "repository", "commits", sha, "comments"), body)
+	if err != nil {
+		return nil, err
+	}
+	return srv.client.Do(req, nil)
+}
+
+// MergeRequestsService ---------------------------------------------------------
+
+type MergeRequestsService struct {
+	client *Client
+}
+
+func (srv *MergeRequestsService) Get(project string, iid int) (*MergeRequest, *http.Response, error) {
+	req, err := srv.client.NewRequest("GET", projectPath(project, "merge_requests", strconv.Itoa(iid)), nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var mr MergeRequest
+	resp, err := srv.client.Do(req, &mr)
+	if err != nil {
+		return nil, resp, err
+	}
+	return &mr, resp, nil
+}
+
+// ListByCommit returns the merge requests the given commit is part of.
+func (srv *MergeRequestsService) ListByCommit(project, sha string) ([]*MergeRequest, *http.Response, error) {
+	req, err := srv.client.NewRequest("GET", projectPath(project, "repository", "commits", sha, "merge_requests"), nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var mrs []*MergeRequest
+	resp, err := srv.client.Do(req, &mrs)
+	if err != nil {
+		return nil, resp, err
+	}
+	return mrs, resp, nil
+}
+
+func (srv *MergeRequestsService) UpdateDescription(
+	project string,
+	iid int,
+	description string,
+) (*MergeRequest, *http.Response, error) {
+
+	body := map[string]string{"description": description}
+	req, err := srv.client.NewRequest("PUT", projectPath(project, "merge_requests", strconv.Itoa(iid)), body)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var mr MergeRequest
+	resp, err := srv.client.Do(req, &mr)
+	if err != nil {
+		return nil, resp, err
+	}
+	return &mr, resp, nil
+}
+
+func (srv *MergeRequestsService) CreateNote(project string, iid int, note *Note) (*Note, *http.Response, error) {
+	path := projectPath(project, "merge_requests", strconv.Itoa(iid), "notes")
+	return srv.client.createNote(path, note)
+}
+
+// IssuesService ----------------------------------------------------------------
+
+type IssuesService struct {
+	client *Client
+}
+
+func (srv *IssuesService) Get(project string, iid int) (*Issue, *http.Response, error) {
+	req, err := srv.client.NewRequest("GET", projectPath(project, "issues", strconv.Itoa(iid)), nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var issue Issue
+	resp, err := srv.client.Do(req, &issue)
+	if err != nil {
+		return nil, resp, err
+	}
+	return &issue, resp, nil
+}
+
+// IssueRequest represents the fields that can be updated using IssuesService.Edit.
+type IssueRequest struct {
+	Labels     *string `json:"labels,omitempty"`
+	StateEvent *string `json:"state_event,omitempty"`
+}
+
+func (srv *IssuesService) Edit(project string, iid int, issueReq *IssueRequest) (*Issue, *http.Response, error) {
+	req, err := srv.client.NewRequest("PUT", projectPath(project, "issues", strconv.Itoa(iid)), issueReq)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var issue Issue
+	resp, err := srv.client.Do(req, &issue)
+	if err != nil {
+		return nil, resp, err
+	}
+	return &issue, resp, nil
+}
+
+func (srv *IssuesService) CreateNote(project string, iid int, note *Note) (*Note, *http.Response, error) {
+	path := projectPath(project, "issues", strconv.Itoa(iid), "notes")
+	return srv.client.createNote(path, note)
+}
+
+func (c *Client) createNote(path string, note *Note) (*Note, *http.Response, error) {
+	req, err := c.NewRequest("POST", path, note)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var n Note
+	resp, err := c.Do(req, &n)
+	if err != nil {
+		return nil, resp, err
+	}
+	return &n, resp, nil
+}
@@ -0,0 +1,10 @@
+package gitlab
+
+func LabeledWith(issue *Issue, labelName string) bool {
+	for _, label := range issue.Labels {
+		if label == labelName {
+			return true
+		}
+	}
+	return false
+}
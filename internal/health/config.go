@@ -0,0 +1,50 @@
+package health
+
+import (
+	// Stdlib
+	"log"
+	"time"
+
+	// Vendor
+	"github.com/kelseyhightower/envconfig"
+)
+
+type Config struct {
+	// CheckTTLString specifies how long the readiness check results are cached,
+	// so that frequent probes do not exhaust the API rate limits.
+	CheckTTLString string `envconfig:"HEALTH_CHECK_TTL" default:"5m"`
+
+	// CheckTTL contains the parsed duration string.
+	CheckTTL time.Duration
+
+	// CheckFailureTTLString specifies how long the failed check results are cached,
+	// it is shorter so that the daemon recovers soon once the problem is fixed.
+	CheckFailureTTLString string `envconfig:"HEALTH_CHECK_FAILURE_TTL" default:"30s"`
+
+	// CheckFailureTTL contains the parsed duration string.
+	CheckFailureTTL time.Duration
+}
+
+var config Config
+
+func init() {
+	if err := envconfig.Process("SFD", &config); err != nil {
+		log.Fatalln("Fatal error while parsing health config:", err)
+	}
+
+	v, err := time.ParseDuration(config.CheckTTLString)
+	if err != nil {
+		log.Fatalln("Fatal error while parsing health config:", err)
+	}
+	config.CheckTTL = v
+
+	v, err = time.ParseDuration(config.CheckFailureTTLString)
+	if err != nil {
+		log.Fatalln("Fatal error while parsing health config:", err)
+	}
+	config.CheckFailureTTL = v
+}
+
+func GetConfig() Config {
+	return config
+}
@@ -0,0 +1,151 @@
+package health
+
+import (
+	// Stdlib
+	"encoding/json"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+// CheckFunc returns an error in case the daemon is not ready.
+type CheckFunc func() error
+
+// Checker runs the registered readiness checks. The results are cached
+// since the checks usually call external APIs. The failed results are cached
+// for a shorter time so that the daemon recovers soon once the problem is fixed.
+//
+// There are two kinds of checks. The daemon is not ready when a critical check fails.
+// When a non-critical check fails, e.g. the credentials of one of the tenants
+// are not valid, the daemon is still ready, but it is reported as degraded.
+type Checker struct {
+	ttl        time.Duration
+	failureTTL time.Duration
+	now        func() time.Time
+
+	mu     sync.Mutex
+	checks map[string]*check
+}
+
+type check struct {
+	fn        CheckFunc
+	critical  bool
+	checked   bool
+	err       error
+	checkedAt time.Time
+}
+
+func NewChecker(ttl, failureTTL time.Duration) *Checker {
+	return &Checker{
+		ttl:        ttl,
+		failureTTL: failureTTL,
+		now:        time.Now,
+		checks:     make(map[string]*check),
+	}
+}
+
+// Add registers a new critical check.
+func (checker *Checker) Add(name string, fn CheckFunc) {
+	checker.add(name, fn, true)
+}
+
+// AddNonCritical registers a new non-critical check.
+func (checker *Checker) AddNonCritical(name string, fn CheckFunc) {
+	checker.add(name, fn, false)
+}
+
+func (checker *Checker) add(name string, fn CheckFunc, critical bool) {
+	checker.mu.Lock()
+	defer checker.mu.Unlock()
+	checker.checks[name] = &check{fn: fn, critical: critical}
+}
+
+// Result is the result of a single check.
+type Result struct {
+	Err      error
+	Critical bool
+}
+
+// Check runs the checks that have no cached result available.
+// It returns the result for every check, nil error meaning the check passed.
+func (checker *Checker) Check() map[string]Result {
+	checker.mu.Lock()
+	defer checker.mu.Unlock()
+
+	now := checker.now()
+	results := make(map[string]Result, len(checker.checks))
+	for name, c := range checker.checks {
+		ttl := checker.ttl
+		if c.err != nil {
+			ttl = checker.failureTTL
+		}
+		if !c.checked || now.Sub(c.checkedAt) >= ttl {
+			c.err = c.fn()
+			c.checked = true
+			c.checkedAt = now
+		}
+		results[name] = Result{c.err, c.critical}
+	}
+	return results
+}
+
+// LivenessHandler returns a handler responding with 200 OK
+// as long as the process is able to serve requests.
+func LivenessHandler() http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		writeJSON(rw, http.StatusOK, map[string]string{"status": "ok"})
+	})
+}
+
+type checkResult struct {
+	Name     string `json:"name"`
+	Status   string `json:"status"`
+	Critical bool   `json:"critical"`
+	Error    string `json:"error,omitempty"`
+}
+
+// ReadinessHandler returns a handler running the checks. It responds with
+// 200 OK unless any of the critical checks fails, 503 Service Unavailable otherwise.
+// The status is degraded when only non-critical checks fail.
+func ReadinessHandler(checker *Checker) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		var (
+			status  = "ok"
+			results = checker.Check()
+			checks  = make([]checkResult, 0, len(results))
+		)
+		for name, res := range results {
+			result := checkResult{Name: name, Status: "ok", Critical: res.Critical}
+			if res.Err != nil {
+				switch {
+				case res.Critical:
+					status = "unavailable"
+				case status == "ok":
+					status = "degraded"
+				}
+				result.Status = "failed"
+				result.Error = res.Err.Error()
+			}
+			checks = append(checks, result)
+		}
+		sort.Slice(checks, func(i, j int) bool {
+			return checks[i].Name < checks[j].Name
+		})
+
+		statusCode := http.StatusOK
+		if status == "unavailable" {
+			statusCode = http.StatusServiceUnavailable
+		}
+		writeJSON(rw, statusCode, map[string]interface{}{
+			"status": status,
+			"checks": checks,
+		})
+	})
+}
+
+func writeJSON(rw http.ResponseWriter, statusCode int, v interface{}) {
+	rw.Header().Set("Content-Type", "application/json")
+	rw.WriteHeader(statusCode)
+	json.NewEncoder(rw).Encode(v)
+}
@@ -0,0 +1,117 @@
+package health
+
+import (
+	// Stdlib
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestChecker_Check(t *testing.T) {
+	var (
+		calls int
+		err   error
+	)
+	checker := NewChecker(time.Minute, 10*time.Second)
+	checker.Add("api", func() error {
+		calls++
+		return err
+	})
+
+	now := time.Now()
+	checker.now = func() time.Time { return now }
+
+	// The results are cached.
+	checker.Check()
+	checker.Check()
+	if calls != 1 {
+		t.Errorf("expected the check to run once, ran %v times", calls)
+	}
+
+	// The checks run again once the results expire.
+	err = errors.New("invalid token")
+	now = now.Add(time.Minute)
+	if results := checker.Check(); results["api"].Err != err {
+		t.Errorf("expected %v, got %v", err, results["api"].Err)
+	}
+	if calls != 2 {
+		t.Errorf("expected the check to run twice, ran %v times", calls)
+	}
+
+	// The failures are cached for a shorter time.
+	err = nil
+	now = now.Add(10 * time.Second)
+	if results := checker.Check(); results["api"].Err != nil {
+		t.Errorf("expected the check to pass, got %v", results["api"].Err)
+	}
+	if calls != 3 {
+		t.Errorf("expected the check to run three times, ran %v times", calls)
+	}
+}
+
+func TestReadinessHandler(t *testing.T) {
+	var (
+		ready       bool
+		tenantValid bool
+	)
+	checker := NewChecker(0, 0)
+	checker.Add("ok", func() error { return nil })
+	checker.Add("startup", func() error {
+		if !ready {
+			return errors.New("starting")
+		}
+		return nil
+	})
+	checker.AddNonCritical("tenant", func() error {
+		if !tenantValid {
+			return errors.New("invalid token")
+		}
+		return nil
+	})
+
+	get := func() (int, map[string]interface{}) {
+		rw := httptest.NewRecorder()
+		ReadinessHandler(checker).ServeHTTP(rw, httptest.NewRequest("GET", "/readyz", nil))
+		var body map[string]interface{}
+		if err := json.Unmarshal(rw.Body.Bytes(), &body); err != nil {
+			t.Fatal(err)
+		}
+		return rw.Code, body
+	}
+
+	// A critical check fails.
+	if code, body := get(); code != http.StatusServiceUnavailable || body["status"] != "unavailable" {
+		t.Errorf("expected the daemon not to be ready, got %v %v", code, body)
+	}
+
+	// A non-critical check fails.
+	ready = true
+	if code, body := get(); code != http.StatusOK || body["status"] != "degraded" {
+		t.Errorf("expected the daemon to be degraded, got %v %v", code, body)
+	}
+
+	// All checks pass.
+	tenantValid = true
+	if code, body := get(); code != http.StatusOK || body["status"] != "ok" {
+		t.Errorf("expected the daemon to be ready, got %v %v", code, body)
+	}
+}
+
+func TestVersionHandler(t *testing.T) {
+	GitCommit = "abcdef"
+	defer func() { GitCommit = "" }()
+
+	rw := httptest.NewRecorder()
+	VersionHandler().ServeHTTP(rw, httptest.NewRequest("GET", "/version", nil))
+
+	var info versionInfo
+	if err := json.Unmarshal(rw.Body.Bytes(), &info); err != nil {
+		t.Fatal(err)
+	}
+	if info.Version == "" || info.GitCommit != "abcdef" || info.GoVersion == "" {
+		t.Errorf("unexpected version info: %+v", info)
+	}
+}
@@ -0,0 +1,55 @@
+package health
+
+import (
+	// Stdlib
+	"net/http"
+	"runtime"
+	"runtime/debug"
+
+	// Internal
+	"github.com/salsaflow/salsaflow-daemon/app/metadata"
+)
+
+// Build information, to be set using the linker, e.g.
+//
+//	go install -ldflags "-X github.com/salsaflow/salsaflow-daemon/internal/health.GitCommit=$(git rev-parse HEAD)"
+var (
+	GitCommit string
+	BuildTime string
+)
+
+type versionInfo struct {
+	Version   string `json:"version"`
+	GitCommit string `json:"git_commit,omitempty"`
+	BuildTime string `json:"build_time,omitempty"`
+	GoVersion string `json:"go_version"`
+}
+
+func getVersionInfo() versionInfo {
+	info := versionInfo{
+		Version:   metadata.Version,
+		GitCommit: GitCommit,
+		BuildTime: BuildTime,
+		GoVersion: runtime.Version(),
+	}
+
+	// Fall back to the VCS information embedded by the Go tool.
+	if buildInfo, ok := debug.ReadBuildInfo(); ok {
+		for _, setting := range buildInfo.Settings {
+			switch {
+			case setting.Key == "vcs.revision" && info.GitCommit == "":
+				info.GitCommit = setting.Value
+			case setting.Key == "vcs.time" && info.BuildTime == "":
+				info.BuildTime = setting.Value
+			}
+		}
+	}
+	return info
+}
+
+// VersionHandler returns a handler serving the version and build information.
+func VersionHandler() http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		writeJSON(rw, http.StatusOK, getVersionInfo())
+	})
+}
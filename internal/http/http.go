@@ -2,10 +2,18 @@ package http
 
 import (
 	// Stdlib
+	"bytes"
+	"context"
+	"crypto/subtle"
+	"io/ioutil"
 	"net/http"
 
 	// Internal
 	"github.com/salsaflow/salsaflow-daemon/internal/log"
+	"github.com/salsaflow/salsaflow-daemon/internal/metrics"
+
+	// Vendor
+	"github.com/codegangsta/negroni"
 )
 
 const (
@@ -14,6 +22,8 @@ const (
 )
 
 func Status(rw http.ResponseWriter, status int) {
+	metrics.ObserveResponse(status)
+
 	switch status {
 	case StatusUnprocessableEntity:
 		http.Error(rw, StatusUnprocessableEntityText, StatusUnprocessableEntity)
@@ -29,3 +39,89 @@ func Error(rw http.ResponseWriter, r *http.Request, err error) {
 	// hence we have to increase the number of skipped callers.
 	log.NewLogger().IncreaseSkippedCallers().Error(r, err)
 }
+
+type bodyKey struct{}
+
+// WithBody reads the request body and it returns a copy of r carrying the body
+// so that the middlewares using the body do not need to read it again, see ReadBody.
+// The body is filled again so that it is still available to the next handler.
+func WithBody(r *http.Request) (*http.Request, error) {
+	body, err := ReadBody(r)
+	if err != nil {
+		return nil, err
+	}
+	return r.WithContext(context.WithValue(r.Context(), bodyKey{}, body)), nil
+}
+
+// ReadBody returns the request body. The body stored by WithBody is returned
+// when available. The body is read otherwise and it is filled again
+// so that it is still available to the next handler.
+func ReadBody(r *http.Request) ([]byte, error) {
+	if body, ok := r.Context().Value(bodyKey{}).([]byte); ok {
+		return body, nil
+	}
+
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return nil, err
+	}
+	r.Body.Close()
+	r.Body = ioutil.NopCloser(bytes.NewReader(body))
+	return body, nil
+}
+
+// ReplaceBody returns a copy of r with the body replaced by the given one.
+// The body stored by WithBody is replaced as well.
+func ReplaceBody(r *http.Request, body []byte) *http.Request {
+	r = r.WithContext(context.WithValue(r.Context(), bodyKey{}, body))
+	r.Body = ioutil.NopCloser(bytes.NewReader(body))
+	return r
+}
+
+// NewBodyMiddleware returns a middleware reading the request body once
+// for all the middlewares that follow, see WithBody. The middlewares
+// replacing the request body must use ReplaceBody.
+func NewBodyMiddleware() negroni.HandlerFunc {
+	return negroni.HandlerFunc(
+		func(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+			withBody, err := WithBody(r)
+			if err != nil {
+				Error(rw, r, err)
+				return
+			}
+
+			// Call the next handler.
+			next(rw, withBody)
+		})
+}
+
+// NewTokenMiddleware returns a middleware authenticating the webhooks
+// of the services that send a shared secret token instead of signing the payload.
+// The token sent with the request is read using sentToken.
+func NewTokenMiddleware(service, token string, sentToken func(r *http.Request) string) negroni.HandlerFunc {
+	return negroni.HandlerFunc(
+		func(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+			if subtle.ConstantTimeCompare([]byte(sentToken(r)), []byte(token)) != 1 {
+				log.Warn(r, "%v webhook token mismatch", service)
+				Status(rw, http.StatusUnauthorized)
+				return
+			}
+
+			// Call the next handler.
+			next(rw, r)
+		})
+}
+
+// HeaderToken returns a function reading the token from the given request header.
+func HeaderToken(header string) func(r *http.Request) string {
+	return func(r *http.Request) string {
+		return r.Header.Get(header)
+	}
+}
+
+// QueryToken returns a function reading the token from the given query parameter.
+func QueryToken(param string) func(r *http.Request) string {
+	return func(r *http.Request) string {
+		return r.URL.Query().Get(param)
+	}
+}
@@ -0,0 +1,39 @@
+package idempotency
+
+import (
+	// Stdlib
+	"log"
+	"time"
+
+	// Vendor
+	"github.com/kelseyhightower/envconfig"
+)
+
+type Config struct {
+	// Dir is the directory where the processed delivery IDs are recorded.
+	Dir string `envconfig:"IDEMPOTENCY_DIR" default:"data/processed"`
+
+	// Retention specifies how long the processed delivery IDs are remembered.
+	RetentionString string `envconfig:"IDEMPOTENCY_RETENTION" default:"720h"`
+
+	// Retention contains parsed RetentionString.
+	Retention time.Duration
+}
+
+var config Config
+
+func init() {
+	if err := envconfig.Process("SFD", &config); err != nil {
+		log.Fatalln("Fatal error while parsing idempotency config:", err)
+	}
+
+	retention, err := time.ParseDuration(config.RetentionString)
+	if err != nil {
+		log.Fatalln("Fatal error while parsing idempotency config:", err)
+	}
+	config.Retention = retention
+}
+
+func GetConfig() Config {
+	return config
+}
@@ -0,0 +1,22 @@
+package idempotency
+
+import (
+	// Stdlib
+	"context"
+)
+
+type contextKey int
+
+const replayKey contextKey = 0
+
+// WithReplay returns a context marking the request as an explicit replay.
+// Replays are processed even when the delivery has been processed already.
+func WithReplay(ctx context.Context) context.Context {
+	return context.WithValue(ctx, replayKey, true)
+}
+
+// IsReplay returns true when the context was created using WithReplay.
+func IsReplay(ctx context.Context) bool {
+	replay, _ := ctx.Value(replayKey).(bool)
+	return replay
+}
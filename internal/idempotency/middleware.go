@@ -0,0 +1,85 @@
+package idempotency
+
+import (
+	// Stdlib
+	"net/http"
+
+	// Internal
+	httputil "github.com/salsaflow/salsaflow-daemon/internal/http"
+	"github.com/salsaflow/salsaflow-daemon/internal/log"
+	"github.com/salsaflow/salsaflow-daemon/internal/metrics"
+
+	// Vendor
+	"github.com/codegangsta/negroni"
+)
+
+// KeyFunc returns the key identifying the given request, e.g. the delivery ID.
+// An empty key means that the request cannot be deduplicated,
+// in which case the request is simply processed.
+type KeyFunc func(r *http.Request) (string, error)
+
+// HeaderKey returns a KeyFunc reading the key from the given request header.
+func HeaderKey(header string) KeyFunc {
+	return func(r *http.Request) (string, error) {
+		return r.Header.Get(header), nil
+	}
+}
+
+// NewMiddleware returns a middleware skipping the requests processed already.
+//
+// The key is recorded once the next handler succeeds, i.e. it responds
+// with a status code lower than 400. The key is released in any other case,
+// including a panic, so that the request is processed again when retried.
+// Explicit replays are always processed, see WithReplay.
+func NewMiddleware(store Store, namespace string, keyFunc KeyFunc) negroni.HandlerFunc {
+	return negroni.HandlerFunc(
+		func(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+			// Requests without a key cannot be deduplicated, just process them.
+			key, err := keyFunc(r)
+			if err != nil {
+				httputil.Error(rw, r, err)
+				return
+			}
+			if key == "" {
+				next(rw, r)
+				return
+			}
+
+			// Skip the requests that were processed already.
+			if IsReplay(r.Context()) {
+				log.Info(r, "Delivery %v is being replayed", key)
+			} else {
+				ok, err := store.Begin(namespace, key)
+				if err != nil {
+					httputil.Error(rw, r, err)
+					return
+				}
+				if !ok {
+					metrics.Skip(r)
+					log.Info(r, "Delivery %v already processed, skipping", key)
+					httputil.Status(rw, http.StatusAccepted)
+					return
+				}
+			}
+
+			var committed bool
+			defer func() {
+				if !committed {
+					store.Abort(namespace, key)
+				}
+			}()
+
+			// Call the next handler.
+			next(rw, r)
+
+			// Record the key unless the handler failed.
+			if status := rw.(negroni.ResponseWriter).Status(); status >= 400 {
+				return
+			}
+			if err := store.Commit(namespace, key); err != nil {
+				log.Error(r, err)
+				return
+			}
+			committed = true
+		})
+}
@@ -0,0 +1,80 @@
+package idempotency
+
+import (
+	// Stdlib
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	// Vendor
+	"github.com/codegangsta/negroni"
+)
+
+func TestMiddleware(t *testing.T) {
+	dir, err := ioutil.TempDir("", "salsaflow-daemon-idempotency")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	store, err := NewFileStore(dir, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The handler panics on the first call and fails on the second one.
+	var calls int
+	n := negroni.New()
+	n.Use(NewMiddleware(store, "testing", HeaderKey("X-Delivery")))
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		calls++
+		switch calls {
+		case 1:
+			panic("boom")
+		case 2:
+			rw.WriteHeader(http.StatusInternalServerError)
+		default:
+			rw.WriteHeader(http.StatusAccepted)
+		}
+	})
+
+	deliver := func(deliveryId string, replay bool) {
+		defer func() {
+			recover()
+		}()
+		r := httptest.NewRequest("POST", "/events", nil)
+		if deliveryId != "" {
+			r.Header.Set("X-Delivery", deliveryId)
+		}
+		if replay {
+			r = r.WithContext(WithReplay(r.Context()))
+		}
+		n.ServeHTTP(httptest.NewRecorder(), r)
+	}
+
+	expectCalls := func(expected int) {
+		t.Helper()
+		if calls != expected {
+			t.Errorf("expected the handler to be called %v times, got %v", expected, calls)
+		}
+	}
+
+	// The delivery is processed again after a panic or a failure,
+	// but only until it succeeds.
+	for i := 0; i < 5; i++ {
+		deliver("1", false)
+	}
+	expectCalls(3)
+
+	// Replays are always processed.
+	deliver("1", true)
+	expectCalls(4)
+
+	// Deliveries without a key are always processed.
+	deliver("", false)
+	deliver("", false)
+	expectCalls(6)
+}
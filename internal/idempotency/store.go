@@ -0,0 +1,154 @@
+package idempotency
+
+import (
+	// Stdlib
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Store keeps track of the deliveries that have already been processed,
+// so that redelivered webhooks do not trigger the same actions again.
+//
+// The keys are namespaced so that delivery IDs coming from different
+// services can never collide.
+type Store interface {
+
+	// Begin marks the key as being processed. It returns false in case
+	// the key has already been processed or it is being processed right now.
+	Begin(namespace, key string) (ok bool, err error)
+
+	// Commit marks the key as processed. It must follow a successful Begin.
+	Commit(namespace, key string) error
+
+	// Abort releases the key so that it can be processed again.
+	// It must follow a successful Begin.
+	Abort(namespace, key string)
+}
+
+// fileStore records every processed key as an empty file.
+// The keys being processed right now are only tracked in memory.
+type fileStore struct {
+	dir       string
+	retention time.Duration
+
+	mu       sync.Mutex
+	inFlight map[string]struct{}
+	pruned   time.Time
+}
+
+// NewFileStore returns a Store that records the processed keys in the given directory.
+// Keys older than retention are forgotten.
+func NewFileStore(dir string, retention time.Duration) (Store, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	return &fileStore{
+		dir:       dir,
+		retention: retention,
+		inFlight:  make(map[string]struct{}),
+	}, nil
+}
+
+var (
+	defaultStore    Store
+	defaultStoreErr error
+	defaultOnce     sync.Once
+)
+
+// Default returns the Store configured using the environment.
+func Default() (Store, error) {
+	defaultOnce.Do(func() {
+		defaultStore, defaultStoreErr = NewFileStore(config.Dir, config.Retention)
+	})
+	return defaultStore, defaultStoreErr
+}
+
+func (store *fileStore) Begin(namespace, key string) (bool, error) {
+	path := store.path(namespace, key)
+
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	store.pruneIfNecessary()
+
+	if _, ok := store.inFlight[path]; ok {
+		return false, nil
+	}
+
+	info, err := os.Stat(path)
+	switch {
+	case err == nil:
+		if time.Since(info.ModTime()) < store.retention {
+			return false, nil
+		}
+	case !os.IsNotExist(err):
+		return false, err
+	}
+
+	store.inFlight[path] = struct{}{}
+	return true, nil
+}
+
+func (store *fileStore) Commit(namespace, key string) error {
+	path := store.path(namespace, key)
+
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	delete(store.inFlight, path)
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, nil, 0600)
+}
+
+func (store *fileStore) Abort(namespace, key string) {
+	path := store.path(namespace, key)
+
+	store.mu.Lock()
+	delete(store.inFlight, path)
+	store.mu.Unlock()
+}
+
+// path returns the file representing the given key.
+// The key is hashed since it is coming from the outside world.
+func (store *fileStore) path(namespace, key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(store.dir, filepath.Base(namespace), hex.EncodeToString(sum[:]))
+}
+
+// pruneIfNecessary removes expired keys, but at most once an hour.
+// It must be called with the store lock held.
+func (store *fileStore) pruneIfNecessary() {
+	now := time.Now()
+	if now.Sub(store.pruned) < time.Hour {
+		return
+	}
+	store.pruned = now
+
+	namespaces, err := ioutil.ReadDir(store.dir)
+	if err != nil {
+		return
+	}
+	for _, ns := range namespaces {
+		if !ns.IsDir() {
+			continue
+		}
+		nsDir := filepath.Join(store.dir, ns.Name())
+		infos, err := ioutil.ReadDir(nsDir)
+		if err != nil {
+			continue
+		}
+		for _, info := range infos {
+			if now.Sub(info.ModTime()) >= store.retention {
+				os.Remove(filepath.Join(nsDir, info.Name()))
+			}
+		}
+	}
+}
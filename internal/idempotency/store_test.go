@@ -0,0 +1,57 @@
+package idempotency
+
+import (
+	// Stdlib
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestFileStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "salsaflow-daemon-idempotency")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	store, err := NewFileStore(dir, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectBegin := func(namespace, key string, expected bool) {
+		ok, err := store.Begin(namespace, key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ok != expected {
+			t.Errorf("Begin(%q, %q): expected %v, got %v", namespace, key, expected, ok)
+		}
+	}
+
+	// A key being processed cannot be processed concurrently.
+	expectBegin("github", "delivery-1", true)
+	expectBegin("github", "delivery-1", false)
+
+	// An aborted key can be processed again.
+	store.Abort("github", "delivery-1")
+	expectBegin("github", "delivery-1", true)
+
+	// A committed key is never processed again.
+	if err := store.Commit("github", "delivery-1"); err != nil {
+		t.Fatal(err)
+	}
+	expectBegin("github", "delivery-1", false)
+
+	// Namespaces are independent.
+	expectBegin("pivotaltracker", "delivery-1", true)
+
+	// Processed keys survive restarts.
+	store, err = NewFileStore(dir, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectBegin("github", "delivery-1", false)
+	expectBegin("github", "delivery-2", true)
+}
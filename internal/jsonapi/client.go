@@ -0,0 +1,107 @@
+// Package jsonapi implements the parts shared by the clients
+// of the plain JSON REST APIs, i.e. the GitLab and Jira API clients.
+package jsonapi
+
+import (
+	// Stdlib
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Client sends JSON requests to the API available at the base URL.
+type Client struct {
+	// Service is the name of the service used in the error messages.
+	Service string
+
+	baseURL      *url.URL
+	authenticate func(req *http.Request)
+
+	// HTTPClient is the client used to send API requests.
+	HTTPClient *http.Client
+}
+
+// NewClient returns a client for the API available at baseURL.
+// authenticate is called to add the credentials to every request.
+func NewClient(service, baseURL string, authenticate func(req *http.Request)) (*Client, error) {
+	u, err := url.Parse(strings.TrimSuffix(baseURL, "/") + "/")
+	if err != nil {
+		return nil, err
+	}
+
+	return &Client{
+		Service:      service,
+		baseURL:      u,
+		authenticate: authenticate,
+		HTTPClient:   http.DefaultClient,
+	}, nil
+}
+
+// NewRequest creates an API request. The path is relative to the base URL,
+// body is encoded as JSON unless it is nil.
+func (c *Client) NewRequest(method, path string, body interface{}) (*http.Request, error) {
+	u, err := c.baseURL.Parse(strings.TrimPrefix(path, "/"))
+	if err != nil {
+		return nil, err
+	}
+
+	var bodyReader io.Reader
+	if body != nil {
+		content, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		bodyReader = bytes.NewReader(content)
+	}
+
+	req, err := http.NewRequest(method, u.String(), bodyReader)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	c.authenticate(req)
+	return req, nil
+}
+
+// Do sends the request and decodes the response body into v unless v is nil.
+// *ErrAPI is returned in case the response status code is not 2xx.
+func (c *Client) Do(req *http.Request, v interface{}) (*http.Response, error) {
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 4096))
+		return resp, &ErrAPI{c.Service, req, resp, strings.TrimSpace(string(body))}
+	}
+
+	if v != nil {
+		if err := json.NewDecoder(resp.Body).Decode(v); err != nil && err != io.EOF {
+			return resp, err
+		}
+	}
+	return resp, nil
+}
+
+type ErrAPI struct {
+	Service  string
+	Request  *http.Request
+	Response *http.Response
+	Body     string
+}
+
+func (err *ErrAPI) Error() string {
+	return fmt.Sprintf("%v: %v %v: %v %v",
+		err.Service, err.Request.Method, err.Request.URL.Path, err.Response.Status, err.Body)
+}
@@ -0,0 +1,62 @@
+package jsonapi
+
+import (
+	// Stdlib
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClient(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer t0k3n" {
+			http.Error(rw, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/api/things/1":
+			rw.Header().Set("Content-Type", "application/json")
+			rw.Write([]byte(`{"name":"thing"}`))
+		default:
+			http.Error(rw, `{"message":"404 Not Found"}`, http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client, err := NewClient("Testing", server.URL+"/api/", func(req *http.Request) {
+		req.Header.Set("Authorization", "Bearer t0k3n")
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The response is decoded.
+	req, err := client.NewRequest("GET", "/things/1", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var thing struct {
+		Name string `json:"name"`
+	}
+	if _, err := client.Do(req, &thing); err != nil {
+		t.Fatal(err)
+	}
+	if thing.Name != "thing" {
+		t.Errorf("unexpected response decoded: %+v", thing)
+	}
+
+	// Error responses are turned into *ErrAPI.
+	req, err = client.NewRequest("GET", "things/2", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = client.Do(req, nil)
+	errAPI, ok := err.(*ErrAPI)
+	if !ok {
+		t.Fatalf("expected *ErrAPI, got %v", err)
+	}
+	expected := `Testing: GET /api/things/2: 404 Not Found {"message":"404 Not Found"}`
+	if errAPI.Error() != expected {
+		t.Errorf("expected error %q, got %q", expected, errAPI.Error())
+	}
+}
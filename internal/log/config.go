@@ -0,0 +1,45 @@
+package log
+
+import (
+	// Stdlib
+	"log"
+	"strings"
+
+	// Vendor
+	"github.com/kelseyhightower/envconfig"
+)
+
+type Config struct {
+	// Level is the minimum level being logged: debug, info, warning or error.
+	Level string `envconfig:"LOG_LEVEL" default:"info"`
+
+	// Format is the output format, either text or json.
+	Format string `envconfig:"LOG_FORMAT" default:"text"`
+}
+
+var (
+	config   Config
+	minLevel Level
+)
+
+func init() {
+	if err := envconfig.Process("SFD", &config); err != nil {
+		log.Fatalln("Fatal error while parsing log config:", err)
+	}
+
+	level, ok := parseLevel(config.Level)
+	if !ok {
+		log.Fatalln("Fatal error while parsing log config: unknown log level:", config.Level)
+	}
+	minLevel = level
+
+	switch strings.ToLower(config.Format) {
+	case "text", "json":
+	default:
+		log.Fatalln("Fatal error while parsing log config: unknown log format:", config.Format)
+	}
+}
+
+func GetConfig() Config {
+	return config
+}
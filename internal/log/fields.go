@@ -0,0 +1,133 @@
+package log
+
+import (
+	// Stdlib
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"sync"
+
+	// Vendor
+	"github.com/codegangsta/negroni"
+)
+
+// Fields are key-value pairs attached to log records.
+type Fields map[string]interface{}
+
+// Well-known field keys.
+const (
+	FieldRequestId  = "request_id"
+	FieldModuleId   = "module"
+	FieldEvent      = "event"
+	FieldRepository = "repository"
+	FieldIssue      = "issue"
+	FieldStoryTag   = "story_tag"
+)
+
+// RequestIdHeader is the header used to pass the request ID around.
+const RequestIdHeader = "X-Request-Id"
+
+// requestIdHeaders are the headers the request ID is taken from, in this order.
+// The webhook delivery IDs are used so that the log records can be matched
+// with the deliveries as shown by the webhook senders.
+var requestIdHeaders = []string{
+	"X-GitHub-Delivery",
+	"X-Gitlab-Event-UUID",
+	"X-Atlassian-Webhook-Identifier",
+	RequestIdHeader,
+}
+
+type fieldSet struct {
+	mu     sync.Mutex
+	fields Fields
+}
+
+type contextKey struct{}
+
+// WithRequest returns a copy of r carrying a field set the log records
+// for the request are enriched with. The request ID field is set right away.
+// r is returned as it is when it already carries a field set.
+func WithRequest(r *http.Request) *http.Request {
+	if _, ok := r.Context().Value(contextKey{}).(*fieldSet); ok {
+		return r
+	}
+	fs := &fieldSet{fields: Fields{FieldRequestId: RequestId(r)}}
+	return r.WithContext(context.WithValue(r.Context(), contextKey{}, fs))
+}
+
+// AddFields adds the given fields to all subsequent log records for r.
+// It is a no-op when r was not passed through WithRequest.
+func AddFields(r *http.Request, fields Fields) {
+	fs, ok := r.Context().Value(contextKey{}).(*fieldSet)
+	if !ok {
+		return
+	}
+	fs.mu.Lock()
+	for k, v := range fields {
+		fs.fields[k] = v
+	}
+	fs.mu.Unlock()
+}
+
+// AddField is a shortcut for AddFields with a single field.
+func AddField(r *http.Request, key string, value interface{}) {
+	AddFields(r, Fields{key: value})
+}
+
+func requestFields(r *http.Request) Fields {
+	fields := make(Fields)
+	if r == nil {
+		return fields
+	}
+	if fs, ok := r.Context().Value(contextKey{}).(*fieldSet); ok {
+		fs.mu.Lock()
+		for k, v := range fs.fields {
+			fields[k] = v
+		}
+		fs.mu.Unlock()
+	}
+	return fields
+}
+
+// RequestId returns the ID of the given request. The delivery ID headers
+// are checked first, a new random ID is generated when there is none.
+func RequestId(r *http.Request) string {
+	if fs, ok := r.Context().Value(contextKey{}).(*fieldSet); ok {
+		fs.mu.Lock()
+		id, ok := fs.fields[FieldRequestId].(string)
+		fs.mu.Unlock()
+		if ok {
+			return id
+		}
+	}
+	for _, key := range requestIdHeaders {
+		if id := r.Header.Get(key); id != "" {
+			return id
+		}
+	}
+	return newRequestId()
+}
+
+func newRequestId() string {
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		return "unknown"
+	}
+	return hex.EncodeToString(buf)
+}
+
+// NewMiddleware returns a middleware that makes the request ID known
+// to the rest of the request handling chain. The ID is stored
+// in the X-Request-Id header so that it is persisted with the request
+// when the request is queued, and it is also sent back in the response.
+func NewMiddleware() negroni.HandlerFunc {
+	return negroni.HandlerFunc(
+		func(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+			r = WithRequest(r)
+			id := RequestId(r)
+			r.Header.Set(RequestIdHeader, id)
+			rw.Header().Set(RequestIdHeader, id)
+			next(rw, r)
+		})
+}
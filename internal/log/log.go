@@ -1,12 +1,52 @@
 package log
 
 import (
+	// Stdlib
+	"encoding/json"
 	"fmt"
+	"io"
 	"log"
 	"net/http"
+	"os"
 	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
 )
 
+type Level int
+
+const (
+	LevelDebug Level = iota
+	LevelInfo
+	LevelWarning
+	LevelError
+)
+
+var levelNames = map[Level]string{
+	LevelDebug:   "DEBUG",
+	LevelInfo:    "INFO",
+	LevelWarning: "WARNING",
+	LevelError:   "ERROR",
+}
+
+func (level Level) String() string {
+	return levelNames[level]
+}
+
+func parseLevel(name string) (Level, bool) {
+	if strings.EqualFold(name, "warn") {
+		return LevelWarning, true
+	}
+	for level, levelName := range levelNames {
+		if strings.EqualFold(name, levelName) {
+			return level, true
+		}
+	}
+	return 0, false
+}
+
 const defaultSkippedCallers = 4
 
 // We need to increase because this logger is called from exported functions
@@ -14,6 +54,10 @@ const defaultSkippedCallers = 4
 // the function name above in the call stack.
 var defaultLogger = NewLogger().IncreaseSkippedCallers()
 
+func Debug(req *http.Request, format string, v ...interface{}) {
+	defaultLogger.Debug(req, format, v...)
+}
+
 func Info(req *http.Request, format string, v ...interface{}) {
 	defaultLogger.Info(req, format, v...)
 }
@@ -42,33 +86,100 @@ func (logger *Logger) DecreaseSkippedCallers() *Logger {
 	return &Logger{logger.skipCallers - 1}
 }
 
+func (logger *Logger) Debug(req *http.Request, format string, v ...interface{}) {
+	logger.printRecord(LevelDebug, req, fmt.Sprintf(format, v...))
+}
+
 func (logger *Logger) Info(req *http.Request, format string, v ...interface{}) {
-	logger.printRecord("INFO", req, format, v...)
+	logger.printRecord(LevelInfo, req, fmt.Sprintf(format, v...))
 }
 
 func (logger *Logger) Warn(req *http.Request, format string, v ...interface{}) {
-	logger.printRecord("WARNING", req, format, v...)
+	logger.printRecord(LevelWarning, req, fmt.Sprintf(format, v...))
 }
 
 func (logger *Logger) Error(req *http.Request, err error) error {
-	logger.printRecord("ERROR", req, err.Error())
+	logger.printRecord(LevelError, req, err.Error())
 	return err
 }
 
-func (logger *Logger) printRecord(
-	kind string,
-	req *http.Request,
-	format string,
-	v ...interface{},
-) {
-	log.Printf("%v [request = %v %v] [position = %v]: %v\n",
-		kind, req.Method, req.URL.Path, logger.trace(), fmt.Sprintf(format, v...))
+// output is where the JSON records are written.
+var (
+	outputMu sync.Mutex
+	output   io.Writer = os.Stderr
+)
+
+func (logger *Logger) printRecord(level Level, req *http.Request, msg string) {
+	if level < minLevel {
+		return
+	}
+
+	fields := requestFields(req)
+	if req != nil {
+		fields["method"] = req.Method
+		fields["path"] = req.URL.Path
+	}
+	fields["position"] = logger.trace()
+
+	if strings.EqualFold(config.Format, "json") {
+		writeJSON(level, msg, fields)
+	} else {
+		writeText(level, msg, fields)
+	}
+}
+
+func writeJSON(level Level, msg string, fields Fields) {
+	record := make(map[string]interface{}, len(fields)+3)
+	for k, v := range fields {
+		record[k] = v
+	}
+	record["time"] = time.Now().UTC().Format(time.RFC3339Nano)
+	record["level"] = strings.ToLower(level.String())
+	record["msg"] = msg
+
+	line, err := json.Marshal(record)
+	if err != nil {
+		log.Println("ERROR: failed to encode log record:", err)
+		return
+	}
+
+	outputMu.Lock()
+	defer outputMu.Unlock()
+	output.Write(append(line, '\n'))
+}
+
+func writeText(level Level, msg string, fields Fields) {
+	var b strings.Builder
+	b.WriteString(level.String())
+	if method, ok := fields["method"]; ok {
+		fmt.Fprintf(&b, " [request = %v %v]", method, fields["path"])
+	}
+
+	// Print the request fields in a stable order.
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		switch k {
+		case "method", "path", "position":
+		default:
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		fmt.Fprintf(&b, " [%v = %v]", k, fields[k])
+	}
+
+	fmt.Fprintf(&b, " [position = %v]: %v", fields["position"], msg)
+	log.Println(b.String())
 }
 
 func (logger *Logger) trace() string {
 	pc := make([]uintptr, 1)
 	runtime.Callers(logger.skipCallers, pc)
 	fn := runtime.FuncForPC(pc[0])
+	if fn == nil {
+		return "unknown"
+	}
 	file, line := fn.FileLine(pc[0])
 	return fmt.Sprintf("%s:%d %s", file, line, fn.Name())
 }
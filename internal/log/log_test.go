@@ -0,0 +1,88 @@
+package log
+
+import (
+	// Stdlib
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRequestId(t *testing.T) {
+	r := httptest.NewRequest("POST", "/events", nil)
+	r.Header.Set("X-GitHub-Delivery", "delivery-id")
+	r.Header.Set(RequestIdHeader, "request-id")
+	if id := RequestId(WithRequest(r)); id != "delivery-id" {
+		t.Errorf("expected delivery-id, got %v", id)
+	}
+
+	// The generated ID is kept for the whole request.
+	r = WithRequest(httptest.NewRequest("POST", "/events", nil))
+	if id := RequestId(r); id == "" || id != RequestId(r) {
+		t.Errorf("request ID not stable: %v", id)
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	var id string
+	next := func(rw http.ResponseWriter, r *http.Request) {
+		id = RequestId(r)
+	}
+
+	r := httptest.NewRequest("POST", "/events", nil)
+	rw := httptest.NewRecorder()
+	NewMiddleware()(rw, r, next)
+
+	if id == "" {
+		t.Fatal("no request ID assigned")
+	}
+	if h := rw.Header().Get(RequestIdHeader); h != id {
+		t.Errorf("expected response header %v, got %v", id, h)
+	}
+	if h := r.Header.Get(RequestIdHeader); h != id {
+		t.Errorf("expected request header %v, got %v", id, h)
+	}
+}
+
+func TestJSONOutput(t *testing.T) {
+	defer func(format string, level Level, w io.Writer) {
+		config.Format = format
+		minLevel = level
+		output = w
+	}(config.Format, minLevel, output)
+
+	var buf bytes.Buffer
+	config.Format = "json"
+	minLevel = LevelInfo
+	output = &buf
+
+	r := httptest.NewRequest("POST", "/events", nil)
+	r.Header.Set("X-GitHub-Delivery", "delivery-id")
+	r = WithRequest(r)
+	AddFields(r, Fields{FieldModuleId: "module", FieldIssue: 42})
+
+	Debug(r, "not logged")
+	Info(r, "Hello %v", "world")
+
+	var record map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatalf("invalid record %q: %v", buf.String(), err)
+	}
+
+	expected := map[string]interface{}{
+		"level":        "info",
+		"msg":          "Hello world",
+		"method":       "POST",
+		"path":         "/events",
+		FieldRequestId: "delivery-id",
+		FieldModuleId:  "module",
+		FieldIssue:     float64(42),
+	}
+	for k, v := range expected {
+		if record[k] != v {
+			t.Errorf("field %v: expected %v, got %v", k, v, record[k])
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package metrics
+
+import (
+	// Stdlib
+	"context"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+)
+
+var (
+	DeliveriesReceived = NewCounterVec(
+		"salsaflow_webhook_deliveries_received_total",
+		"Number of webhook deliveries received.",
+		"module", "event")
+
+	DeliveriesProcessed = NewCounterVec(
+		"salsaflow_webhook_deliveries_processed_total",
+		"Number of webhook delivery processing attempts by outcome (handled, skipped, failed).",
+		"module", "event", "outcome")
+
+	DeliveryDuration = NewHistogramVec(
+		"salsaflow_webhook_delivery_duration_seconds",
+		"Time spent processing webhook deliveries.",
+		DefaultBuckets,
+		"module", "event")
+
+	Responses = NewCounterVec(
+		"salsaflow_http_responses_total",
+		"Number of responses written by status code.",
+		"code")
+
+	APIRequests = NewCounterVec(
+		"salsaflow_api_requests_total",
+		"Number of outbound API requests by status code, code is 'error' when the request failed.",
+		"service", "code")
+
+	APIRequestDuration = NewHistogramVec(
+		"salsaflow_api_request_duration_seconds",
+		"Latency of outbound API requests.",
+		DefaultBuckets,
+		"service")
+
+	APIRateLimitRemaining = NewGaugeVec(
+		"salsaflow_api_rate_limit_remaining",
+		"Number of requests remaining in the current rate limit window.",
+		"service")
+)
+
+// Delivery outcomes.
+const (
+	OutcomeHandled = "handled"
+	OutcomeSkipped = "skipped"
+	OutcomeFailed  = "failed"
+)
+
+// EventType returns the event type as sent in the webhook request headers.
+func EventType(header http.Header) string {
+	for _, key := range []string{"X-GitHub-Event", "X-Gitlab-Event"} {
+		if v := header.Get(key); v != "" {
+			return v
+		}
+	}
+	return "unknown"
+}
+
+// ObserveResponse counts a response with the given status code.
+func ObserveResponse(status int) {
+	Responses.Inc(strconv.Itoa(status))
+}
+
+// Skipped deliveries ----------------------------------------------------------
+
+type skipKey struct{}
+
+// TrackSkips returns a copy of r that makes it possible for the handlers
+// to report the request as skipped using Skip. The returned function
+// tells whether Skip was called.
+func TrackSkips(r *http.Request) (*http.Request, func() bool) {
+	var skipped int32
+	r = r.WithContext(context.WithValue(r.Context(), skipKey{}, &skipped))
+	return r, func() bool {
+		return atomic.LoadInt32(&skipped) != 0
+	}
+}
+
+// Skip marks the request as skipped, i.e. accepted without doing anything.
+// It is a no-op for the requests not passed through TrackSkips.
+func Skip(r *http.Request) {
+	if skipped, ok := r.Context().Value(skipKey{}).(*int32); ok {
+		atomic.StoreInt32(skipped, 1)
+	}
+}
@@ -0,0 +1,94 @@
+package metrics
+
+import (
+	// Stdlib
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRegistry_Handler(t *testing.T) {
+	defer func(registry *Registry) {
+		defaultRegistry = registry
+	}(defaultRegistry)
+
+	defaultRegistry = NewRegistry()
+	counter := NewCounterVec("test_total", "Test counter.", "module")
+	gauge := NewGaugeVec("test_gauge", "Test gauge.")
+	histogram := NewHistogramVec("test_seconds", "Test histogram.", []float64{1, 5}, "module")
+
+	counter.Inc("b")
+	counter.Inc("a")
+	counter.Add(2, "a")
+	gauge.Set(42)
+	histogram.Observe(0.5, `quoted "module"`)
+	histogram.Observe(3, `quoted "module"`)
+
+	rw := httptest.NewRecorder()
+	defaultRegistry.Handler().ServeHTTP(rw, httptest.NewRequest("GET", "/metrics", nil))
+
+	expected := `# HELP test_total Test counter.
+# TYPE test_total counter
+test_total{module="a"} 3
+test_total{module="b"} 1
+# HELP test_gauge Test gauge.
+# TYPE test_gauge gauge
+test_gauge 42
+# HELP test_seconds Test histogram.
+# TYPE test_seconds histogram
+test_seconds_bucket{module="quoted \"module\"",le="1"} 1
+test_seconds_bucket{module="quoted \"module\"",le="5"} 2
+test_seconds_bucket{module="quoted \"module\"",le="+Inf"} 2
+test_seconds_sum{module="quoted \"module\""} 3.5
+test_seconds_count{module="quoted \"module\""} 2
+`
+	if body := rw.Body.String(); body != expected {
+		t.Errorf("unexpected output:\n%v\nexpected:\n%v", body, expected)
+	}
+}
+
+func TestTransport(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.Header().Set("X-RateLimit-Remaining", "4999")
+		rw.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: &Transport{}}
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	service := strings.TrimPrefix(server.URL, "http://")
+
+	rw := httptest.NewRecorder()
+	Handler().ServeHTTP(rw, httptest.NewRequest("GET", "/metrics", nil))
+	body := rw.Body.String()
+
+	for _, line := range []string{
+		`salsaflow_api_requests_total{service="` + service + `",code="404"} 1`,
+		`salsaflow_api_rate_limit_remaining{service="` + service + `"} 4999`,
+		`salsaflow_api_request_duration_seconds_count{service="` + service + `"} 1`,
+	} {
+		if !strings.Contains(body, line) {
+			t.Errorf("line missing in the output: %v", line)
+		}
+	}
+}
+
+func TestSkip(t *testing.T) {
+	r, skipped := TrackSkips(httptest.NewRequest("POST", "/events", nil))
+	if skipped() {
+		t.Error("request reported as skipped before calling Skip")
+	}
+	Skip(r)
+	if !skipped() {
+		t.Error("request not reported as skipped after calling Skip")
+	}
+
+	// Skip must not panic for untracked requests.
+	Skip(httptest.NewRequest("POST", "/events", nil))
+}
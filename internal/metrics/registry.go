@@ -0,0 +1,267 @@
+package metrics
+
+import (
+	// Stdlib
+	"bytes"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// The Prometheus client library is not vendored, so we implement the small
+// subset we need ourselves: counters, gauges and histograms with labels
+// exported in the Prometheus text exposition format.
+
+type metric interface {
+	write(buf *bytes.Buffer)
+}
+
+// Registry is a collection of metrics that can be exported over HTTP.
+type Registry struct {
+	mu      sync.Mutex
+	metrics []metric
+}
+
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+var defaultRegistry = NewRegistry()
+
+func (registry *Registry) register(m metric) {
+	registry.mu.Lock()
+	registry.metrics = append(registry.metrics, m)
+	registry.mu.Unlock()
+}
+
+// Handler returns a http.Handler serving the registered metrics
+// in the Prometheus text exposition format.
+func (registry *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		var buf bytes.Buffer
+		registry.mu.Lock()
+		for _, m := range registry.metrics {
+			m.write(&buf)
+		}
+		registry.mu.Unlock()
+
+		rw.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		buf.WriteTo(rw)
+	})
+}
+
+// Handler returns the handler serving the default registry.
+func Handler() http.Handler {
+	return defaultRegistry.Handler()
+}
+
+// vec keeps a value for every combination of label values.
+type vec struct {
+	name       string
+	help       string
+	kind       string
+	labelNames []string
+
+	mu     sync.Mutex
+	values map[string]*series
+}
+
+type series struct {
+	labelValues []string
+	value       interface{}
+}
+
+func newVec(name, help, kind string, labelNames []string) vec {
+	return vec{
+		name:       name,
+		help:       help,
+		kind:       kind,
+		labelNames: labelNames,
+		values:     make(map[string]*series),
+	}
+}
+
+// with calls f with the value for the given label values, creating the value
+// using newValue when necessary. The vec is locked while f is running.
+func (v *vec) with(labelValues []string, newValue func() interface{}, f func(value interface{})) {
+	if len(labelValues) != len(v.labelNames) {
+		panic(fmt.Sprintf("metric %v: expected %v label values, got %v",
+			v.name, len(v.labelNames), len(labelValues)))
+	}
+
+	key := strings.Join(labelValues, "\xff")
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	s, ok := v.values[key]
+	if !ok {
+		s = &series{append([]string(nil), labelValues...), newValue()}
+		v.values[key] = s
+	}
+	f(s.value)
+}
+
+// each calls f for all the series sorted by their label values.
+func (v *vec) each(buf *bytes.Buffer, f func(labels string, value interface{})) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	fmt.Fprintf(buf, "# HELP %v %v\n", v.name, v.help)
+	fmt.Fprintf(buf, "# TYPE %v %v\n", v.name, v.kind)
+
+	keys := make([]string, 0, len(v.values))
+	for key := range v.values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		s := v.values[key]
+		f(formatLabels(v.labelNames, s.labelValues), s.value)
+	}
+}
+
+// Counter ---------------------------------------------------------------------
+
+type CounterVec struct {
+	vec
+}
+
+// NewCounterVec creates a new counter and registers it with the default registry.
+func NewCounterVec(name, help string, labelNames ...string) *CounterVec {
+	c := &CounterVec{newVec(name, help, "counter", labelNames)}
+	defaultRegistry.register(c)
+	return c
+}
+
+func (c *CounterVec) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+func (c *CounterVec) Add(delta float64, labelValues ...string) {
+	c.with(labelValues, func() interface{} { return new(float64) }, func(value interface{}) {
+		*value.(*float64) += delta
+	})
+}
+
+func (c *CounterVec) write(buf *bytes.Buffer) {
+	c.each(buf, func(labels string, value interface{}) {
+		fmt.Fprintf(buf, "%v%v %v\n", c.name, labels, formatFloat(*value.(*float64)))
+	})
+}
+
+// Gauge -----------------------------------------------------------------------
+
+type GaugeVec struct {
+	vec
+}
+
+// NewGaugeVec creates a new gauge and registers it with the default registry.
+func NewGaugeVec(name, help string, labelNames ...string) *GaugeVec {
+	g := &GaugeVec{newVec(name, help, "gauge", labelNames)}
+	defaultRegistry.register(g)
+	return g
+}
+
+func (g *GaugeVec) Set(v float64, labelValues ...string) {
+	g.with(labelValues, func() interface{} { return new(float64) }, func(value interface{}) {
+		*value.(*float64) = v
+	})
+}
+
+func (g *GaugeVec) write(buf *bytes.Buffer) {
+	g.each(buf, func(labels string, value interface{}) {
+		fmt.Fprintf(buf, "%v%v %v\n", g.name, labels, formatFloat(*value.(*float64)))
+	})
+}
+
+// Histogram -------------------------------------------------------------------
+
+// DefaultBuckets are the histogram buckets suitable for request latencies in seconds.
+var DefaultBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+type HistogramVec struct {
+	vec
+	buckets []float64
+}
+
+type histogram struct {
+	counts []uint64
+	count  uint64
+	sum    float64
+}
+
+// NewHistogramVec creates a new histogram and registers it with the default registry.
+func NewHistogramVec(name, help string, buckets []float64, labelNames ...string) *HistogramVec {
+	h := &HistogramVec{newVec(name, help, "histogram", labelNames), buckets}
+	defaultRegistry.register(h)
+	return h
+}
+
+func (h *HistogramVec) Observe(v float64, labelValues ...string) {
+	newValue := func() interface{} {
+		return &histogram{counts: make([]uint64, len(h.buckets))}
+	}
+	h.with(labelValues, newValue, func(value interface{}) {
+		hist := value.(*histogram)
+		for i, upperBound := range h.buckets {
+			if v <= upperBound {
+				hist.counts[i]++
+			}
+		}
+		hist.count++
+		hist.sum += v
+	})
+}
+
+func (h *HistogramVec) write(buf *bytes.Buffer) {
+	h.each(buf, func(labels string, value interface{}) {
+		hist := value.(*histogram)
+
+		// The le label is appended to the other labels.
+		bucketLabels := func(le string) string {
+			if labels == "" {
+				return fmt.Sprintf(`{le="%v"}`, le)
+			}
+			return fmt.Sprintf(`%v,le="%v"}`, labels[:len(labels)-1], le)
+		}
+
+		for i, upperBound := range h.buckets {
+			fmt.Fprintf(buf, "%v_bucket%v %v\n", h.name, bucketLabels(formatFloat(upperBound)), hist.counts[i])
+		}
+		fmt.Fprintf(buf, "%v_bucket%v %v\n", h.name, bucketLabels("+Inf"), hist.count)
+		fmt.Fprintf(buf, "%v_sum%v %v\n", h.name, labels, formatFloat(hist.sum))
+		fmt.Fprintf(buf, "%v_count%v %v\n", h.name, labels, hist.count)
+	})
+}
+
+// Formatting ------------------------------------------------------------------
+
+var labelValueReplacer = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func formatLabels(names, values []string) string {
+	if len(names) == 0 {
+		return ""
+	}
+	pairs := make([]string, len(names))
+	for i := range names {
+		pairs[i] = fmt.Sprintf(`%v="%v"`, names[i], labelValueReplacer.Replace(values[i]))
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, +1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	default:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	}
+}
@@ -0,0 +1,61 @@
+package metrics
+
+import (
+	// Stdlib
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Transport is a http.RoundTripper that records the latency and the status codes
+// of outbound API requests. The service label is derived from the request host.
+type Transport struct {
+	// Base is the underlying transport, http.DefaultTransport when nil.
+	Base http.RoundTripper
+}
+
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	service := serviceForHost(req.URL.Host)
+
+	start := time.Now()
+	resp, err := base.RoundTrip(req)
+	APIRequestDuration.Observe(time.Since(start).Seconds(), service)
+
+	if err != nil {
+		APIRequests.Inc(service, "error")
+		return nil, err
+	}
+	APIRequests.Inc(service, strconv.Itoa(resp.StatusCode))
+
+	// GitHub sends X-RateLimit-Remaining, GitLab RateLimit-Remaining.
+	for _, key := range []string{"X-RateLimit-Remaining", "RateLimit-Remaining"} {
+		if v, err := strconv.ParseFloat(resp.Header.Get(key), 64); err == nil {
+			APIRateLimitRemaining.Set(v, service)
+			break
+		}
+	}
+
+	return resp, nil
+}
+
+func serviceForHost(host string) string {
+	host = strings.ToLower(host)
+	switch {
+	case host == "api.github.com":
+		return "github"
+	case strings.HasSuffix(host, "pivotaltracker.com"):
+		return "pivotaltracker"
+	case host == "gitlab.com":
+		return "gitlab"
+	case strings.HasSuffix(host, ".atlassian.net"):
+		return "jira"
+	default:
+		return host
+	}
+}
@@ -0,0 +1,30 @@
+package config
+
+import (
+	// Stdlib
+	"log"
+
+	// Vendor
+	"github.com/kelseyhightower/envconfig"
+)
+
+type Config struct {
+	// ReviewIssueLabel is the label marking review issues.
+	ReviewIssueLabel string `envconfig:"REVIEW_ISSUE_LABEL" default:"review"`
+
+	// StoryImplementedLabel is the label that must be present
+	// before a review issue can be closed.
+	StoryImplementedLabel string `envconfig:"STORY_IMPLEMENTED_LABEL" default:"implemented"`
+}
+
+var config Config
+
+func init() {
+	if err := envconfig.Process("SFD_GITHUB", &config); err != nil {
+		log.Fatalln("Fatal error while parsing GitHub code review config:", err)
+	}
+}
+
+func Get() Config {
+	return config
+}
@@ -0,0 +1,54 @@
+package config
+
+import (
+	// Internal
+	"github.com/salsaflow/salsaflow-daemon/internal/github/repoconfig"
+	"github.com/salsaflow/salsaflow-daemon/internal/tenants"
+
+	// Vendor
+	"github.com/google/go-github/github"
+)
+
+// RepoSectionKey is the key of the section in .salsaflow/config.json
+// containing the GitHub code review configuration, i.e. the module ID.
+const RepoSectionKey = "salsaflow.modules.codereview.github"
+
+type repoConfig struct {
+	ReviewIssueLabel      string `json:"review_issue_label"`
+	StoryImplementedLabel string `json:"story_implemented_label"`
+}
+
+// ForRepo returns the configuration to be used for the given repository.
+// The values found in the repository configuration file take precedence,
+// then the label scheme of the tenant the repository owner belongs to is used.
+// The values read from the environment are used for the rest.
+func ForRepo(client *github.Client, owner, repo string) (Config, error) {
+	c := Get()
+
+	var tc repoConfig
+	ok, err := tenants.ForGitHubOwner(owner).Section(RepoSectionKey, &tc)
+	if err != nil {
+		return c, err
+	}
+	if ok {
+		c = tc.apply(c)
+	}
+
+	var rc repoConfig
+	ok, err = repoconfig.Load(client, owner, repo).Section(RepoSectionKey, &rc)
+	if err != nil || !ok {
+		return c, err
+	}
+
+	return rc.apply(c), nil
+}
+
+func (rc *repoConfig) apply(c Config) Config {
+	if rc.ReviewIssueLabel != "" {
+		c.ReviewIssueLabel = rc.ReviewIssueLabel
+	}
+	if rc.StoryImplementedLabel != "" {
+		c.StoryImplementedLabel = rc.StoryImplementedLabel
+	}
+	return c
+}
@@ -0,0 +1,105 @@
+package endpoint
+
+import (
+	// Stdlib
+	"fmt"
+	"net/http"
+	"regexp"
+
+	// Internal
+	"github.com/salsaflow/salsaflow-daemon/internal/commands"
+	"github.com/salsaflow/salsaflow-daemon/internal/log"
+	"github.com/salsaflow/salsaflow-daemon/internal/modules/common"
+)
+
+var commitSHARegexp = regexp.MustCompile("^[0-9a-f]{7,40}$")
+
+var fixedCommand = &commands.Command{
+	Name:        "fixed",
+	Usage:       "<blocker number or comment URL> [commit SHA]",
+	Description: "Mark the review blocker as fixed, optionally by the given commit.",
+	MinArgs:     1,
+	MaxArgs:     2,
+	Validate: func(args []string) error {
+		if len(args) == 2 {
+			return validateCommitSHAs(args[1:])
+		}
+		return nil
+	},
+}
+
+var wontfixCommand = &commands.Command{
+	Name:        "wontfix",
+	Usage:       "<blocker number or comment URL> <reason>",
+	Description: "Resolve the review blocker without fixing it.",
+	MinArgs:     2,
+	MaxArgs:     2,
+	Rest:        true,
+}
+
+var reviewedCommand = &commands.Command{
+	Name:        "reviewed",
+	Usage:       "[commit SHA...]",
+	Description: "Mark the given commits as reviewed, the commented commit by default.",
+	MinArgs:     0,
+	MaxArgs:     -1,
+	Validate:    validateCommitSHAs,
+}
+
+// reviewIssueReviewedCommand is !reviewed as available in review issue comments.
+// There is no commented commit there, so the commits must be always listed.
+var reviewIssueReviewedCommand = &commands.Command{
+	Name:        "reviewed",
+	Usage:       "<commit SHA...>",
+	Description: "Mark the given commits as reviewed.",
+	MinArgs:     1,
+	MaxArgs:     -1,
+	Validate:    validateCommitSHAs,
+}
+
+func validateCommitSHAs(shas []string) error {
+	for _, sha := range shas {
+		if !commitSHARegexp.MatchString(sha) {
+			return fmt.Errorf("not a valid commit SHA: %v", sha)
+		}
+	}
+	return nil
+}
+
+// The commands available in the contexts handled by this module.
+var (
+	commitCommentCommands = commands.NewRegistry(
+		common.MustfixCommand, fixedCommand, wontfixCommand, reviewedCommand)
+
+	pullRequestReviewCommentCommands = commands.NewRegistry(
+		common.MustfixCommand, fixedCommand, wontfixCommand)
+
+	reviewIssueCommentCommands = commands.NewRegistry(
+		fixedCommand, wontfixCommand, reviewIssueReviewedCommand)
+
+	pullRequestCommentCommands = commands.NewRegistry(
+		fixedCommand, wontfixCommand)
+)
+
+// replyToCommands posts the reply for the given commands using post
+// in case there is !help or a malformed command.
+//
+// The commands are processed already when replying, so a failure to post
+// the reply is only logged. Failing the delivery would make the retry
+// run the commands again.
+func replyToCommands(
+	r *http.Request,
+	registry *commands.Registry,
+	author string,
+	invs []*commands.Invocation,
+	errs []error,
+	post func(reply string) error,
+) {
+	reply := registry.Reply(author, invs, errs)
+	if reply == "" {
+		return
+	}
+	if err := post(reply); err != nil {
+		log.Error(r, err)
+	}
+}
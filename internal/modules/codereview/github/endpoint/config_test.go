@@ -0,0 +1,391 @@
+package endpoint
+
+import (
+	// Stdlib
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+
+	// Internal
+	"github.com/salsaflow/salsaflow-daemon/internal/github/events"
+	"github.com/salsaflow/salsaflow-daemon/internal/github/repoconfig"
+	"github.com/salsaflow/salsaflow-daemon/internal/modules/common"
+
+	// Vendor
+	"github.com/google/go-github/github"
+	"github.com/salsaflow/salsaflow/github/issues"
+)
+
+// testingRepoConfig is a real .salsaflow/config.json with the code review labels customized.
+const testingRepoConfig = `{
+  "salsaflow_enabled_timestamp": "2015-06-08T08:40:45.923692728+02:00",
+  "active_modules": {
+    "issue_tracking": "salsaflow.modules.issuetracking.github",
+    "code_review": "salsaflow.modules.codereview.github",
+    "release_notes": "salsaflow.modules.releasenotes.github"
+  },
+  "configuration": {
+    "salsaflow.core.git": {
+      "trunk_branch": "develop",
+      "release_branch": "release",
+      "staging_branch": "stage",
+      "stable_branch": "master"
+    },
+    "salsaflow.core.versioning": {
+      "trunk_suffix": "dev",
+      "testing_suffix": "qa",
+      "staging_suffix": "stage"
+    },
+    "salsaflow.modules.codereview.github": {
+      "review_issue_label": "code review",
+      "story_implemented_label": "done"
+    },
+    "salsaflow.modules.issuetracking.github": {
+      "story_labels": [
+        "enhancement",
+        "bug"
+      ],
+      "state_labels": {
+        "approved": "approved",
+        "being_implemented": "being implemented",
+        "implemented": "implemented",
+        "reviewed": "reviewed",
+        "skip_review": "no review",
+        "passed_testing": "qa+",
+        "failed_testing": "qa-",
+        "skip_testing": "no qa",
+        "staged_for_acceptance": "staged",
+        "client_rejected": "rejected"
+      },
+      "skip_release_check_labels": [
+        "duplicate",
+        "invalid"
+      ]
+    },
+    "salsaflow.modules.releasenotes.github": {}
+  }
+}`
+
+// testingGitHub is a GitHub stand-in serving a single issue, the reviews
+// of a single pull request and the repository configuration.
+// It records all requests and the calls of the story hooks.
+type testingGitHub struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	issue    *github.Issue
+	reviews  []*events.PullRequestReview
+	story    *testingStory
+	requests []string
+	queries  []string
+	comments []string
+	labels   []string
+
+	// commentLimit makes the comments fail once reached, 0 means no limit.
+	commentLimit int
+}
+
+func newTestingGitHub(labels ...string) *testingGitHub {
+	gh := &testingGitHub{
+		issue: &github.Issue{
+			Number:  github.Int(1),
+			Title:   github.String("Review story 42: Do the thing"),
+			Body:    github.String(""),
+			HTMLURL: github.String("https://github.com/owner/repo/issues/1"),
+		},
+		story: &testingStory{},
+	}
+	for _, label := range labels {
+		gh.issue.Labels = append(gh.issue.Labels, github.Label{Name: github.String(label)})
+	}
+	gh.Server = httptest.NewServer(gh)
+	return gh
+}
+
+func (gh *testingGitHub) newClient() *github.Client {
+	client := github.NewClient(nil)
+	client.BaseURL, _ = url.Parse(gh.URL + "/")
+	return client
+}
+
+func (gh *testingGitHub) newEventHandler() *eventHandler {
+	client := gh.newClient()
+	return &eventHandler{
+		newClient: func(*http.Request, string) (*github.Client, error) {
+			return client, nil
+		},
+		findStory: func(string, string) (common.Story, error) {
+			return gh.story, nil
+		},
+	}
+}
+
+// testingStory records the calls of the story hooks used by the code review module.
+type testingStory struct {
+	common.Story
+
+	calls []string
+}
+
+func (story *testingStory) OnReviewRequestOpened(rrID, rrURL string) error {
+	story.calls = append(story.calls, "opened "+rrID)
+	return nil
+}
+
+func (story *testingStory) OnReviewRequestClosed(rrID, rrURL string) error {
+	story.calls = append(story.calls, "closed "+rrID)
+	return nil
+}
+
+func (story *testingStory) OnReviewRequestReopened(rrID, rrURL string) error {
+	story.calls = append(story.calls, "reopened "+rrID)
+	return nil
+}
+
+func (story *testingStory) MarkAsReviewed() error {
+	story.calls = append(story.calls, "reviewed")
+	return nil
+}
+
+func (gh *testingGitHub) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
+	gh.mu.Lock()
+	defer gh.mu.Unlock()
+
+	gh.requests = append(gh.requests, r.Method+" "+r.URL.Path)
+
+	switch {
+	case r.Method == "GET" && strings.HasSuffix(r.URL.Path, "/contents/"+repoconfig.Path):
+		writeJSON(rw, map[string]string{
+			"type":     "file",
+			"encoding": "base64",
+			"content":  base64.StdEncoding.EncodeToString([]byte(testingRepoConfig)),
+		})
+
+	case r.Method == "GET" && strings.HasSuffix(r.URL.Path, "/pulls/1/reviews"):
+		reviews := gh.reviews
+		if reviews == nil {
+			reviews = []*events.PullRequestReview{}
+		}
+		writeJSON(rw, reviews)
+
+	case r.Method == "GET" && strings.HasSuffix(r.URL.Path, "/issues/1"):
+		writeJSON(rw, gh.issue)
+
+	case r.Method == "PATCH" && strings.HasSuffix(r.URL.Path, "/issues/1"):
+		var req github.IssueRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		if req.State != nil {
+			gh.issue.State = req.State
+		}
+		if req.Body != nil {
+			gh.issue.Body = req.Body
+		}
+		writeJSON(rw, gh.issue)
+
+	case r.Method == "POST" && strings.HasSuffix(r.URL.Path, "/issues/1/comments") &&
+		gh.commentLimit != 0 && len(gh.comments) >= gh.commentLimit:
+		http.Error(rw, `{"message":"Server Error"}`, http.StatusInternalServerError)
+
+	case r.Method == "POST" && strings.HasSuffix(r.URL.Path, "/issues/1/comments"):
+		body, _ := ioutil.ReadAll(r.Body)
+		var comment github.IssueComment
+		json.Unmarshal(body, &comment)
+		gh.comments = append(gh.comments, *comment.Body)
+		rw.WriteHeader(http.StatusCreated)
+		writeJSON(rw, &comment)
+
+	case r.Method == "POST" && strings.HasSuffix(r.URL.Path, "/issues/1/labels"):
+		var labels []string
+		json.NewDecoder(r.Body).Decode(&labels)
+		gh.labels = append(gh.labels, labels...)
+		writeJSON(rw, []interface{}{})
+
+	case r.Method == "GET" && r.URL.Path == "/search/issues":
+		// The issue is found when searching by any of its labels.
+		query := r.URL.Query().Get("q")
+		gh.queries = append(gh.queries, query)
+		items := []interface{}{}
+		for _, label := range gh.issue.Labels {
+			if strings.Contains(query, fmt.Sprintf(`label:"%v"`, *label.Name)) {
+				items = append(items, gh.issue)
+				break
+			}
+		}
+		writeJSON(rw, map[string]interface{}{"total_count": len(items), "items": items})
+
+	default:
+		http.Error(rw, `{"message":"Not Found"}`, http.StatusNotFound)
+	}
+}
+
+func writeJSON(rw http.ResponseWriter, v interface{}) {
+	rw.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(rw).Encode(v)
+}
+
+func newIssuesEvent(repo, action string) *events.IssuesEvent {
+	return &events.IssuesEvent{
+		Action: github.String(action),
+		Issue:  &github.Issue{Number: github.Int(1)},
+		Repo: &github.Repository{
+			Name:  github.String(repo),
+			Owner: &github.User{Login: github.String("owner")},
+		},
+		Sender: &github.User{Login: github.String("reviewer")},
+	}
+}
+
+func TestHandleIssuesEvent_CustomLabels_RejectClose(t *testing.T) {
+	gh := newTestingGitHub("code review")
+	defer gh.Close()
+
+	rw := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", "/events", nil)
+	gh.newEventHandler().HandleIssuesEvent(rw, r, newIssuesEvent("reject-close", "closed"))
+
+	if rw.Code != http.StatusAccepted {
+		t.Fatalf("expected status %v, got %v", http.StatusAccepted, rw.Code)
+	}
+	if gh.issue.State == nil || *gh.issue.State != "open" {
+		t.Error("the review issue was not reopened")
+	}
+	if len(gh.comments) != 1 || !strings.Contains(gh.comments[0], "labeled with `done`") {
+		t.Errorf("unexpected comments: %q", gh.comments)
+	}
+}
+
+func TestHandleIssuesEvent_CustomLabels_NotReviewIssue(t *testing.T) {
+	// The issue is labeled with the default review issue label,
+	// which is overridden in the repository configuration.
+	gh := newTestingGitHub("review")
+	defer gh.Close()
+
+	rw := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", "/events", nil)
+	gh.newEventHandler().HandleIssuesEvent(rw, r, newIssuesEvent("not-review-issue", "closed"))
+
+	if rw.Code != http.StatusAccepted {
+		t.Fatalf("expected status %v, got %v", http.StatusAccepted, rw.Code)
+	}
+	for _, req := range gh.requests {
+		if strings.HasPrefix(req, "PATCH") || strings.HasPrefix(req, "POST") {
+			t.Errorf("unexpected request: %v", req)
+		}
+	}
+}
+
+func TestCreateReviewBlocker_CustomLabels(t *testing.T) {
+	gh := newTestingGitHub("code review")
+	defer gh.Close()
+
+	reviewIssue := newTestingReviewIssue()
+	gh.issue.Title = github.String(reviewIssue.FormatTitle())
+	gh.issue.Body = github.String(reviewIssue.FormatBody())
+
+	r := httptest.NewRequest("POST", "/events", nil)
+	err := gh.newEventHandler().createReviewBlocker(
+		r, "owner", "blocker", "0123456789abcdef", "https://comment-url/3", "reviewer", "Fix it")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(gh.queries) != 1 || !strings.Contains(gh.queries[0], `label:"code review"`) {
+		t.Errorf("unexpected search queries: %q", gh.queries)
+	}
+
+	updated, err := issues.ParseReviewIssue(gh.issue)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := len(updated.ReviewBlockerItems()); n != 3 {
+		t.Errorf("expected 3 review blockers, got %v:\n%v", n, *gh.issue.Body)
+	}
+}
+
+func TestResolveAndReviewForCommit_CustomLabels(t *testing.T) {
+	data := []struct {
+		body  string
+		check func(issues.ReviewIssue) bool
+	}{
+		{"!fixed 1", func(reviewIssue issues.ReviewIssue) bool {
+			return reviewIssue.ReviewBlockerItems()[0].Fixed
+		}},
+		{"!reviewed 0123456", func(reviewIssue issues.ReviewIssue) bool {
+			return reviewIssue.CommitItems()[0].Reviewed
+		}},
+	}
+
+	for _, d := range data {
+		gh := newTestingGitHub("code review")
+
+		reviewIssue := newTestingReviewIssue()
+		gh.issue.Title = github.String(reviewIssue.FormatTitle())
+		gh.issue.Body = github.String(reviewIssue.FormatBody())
+
+		invs, errs := commitCommentCommands.Parse(d.body)
+		if len(errs) != 0 || len(invs) != 1 {
+			t.Fatalf("%v: unexpected parse result: %v, %v", d.body, invs, errs)
+		}
+
+		var (
+			handler = gh.newEventHandler()
+			r       = httptest.NewRequest("POST", "/events", nil)
+			err     error
+		)
+		switch invs[0].Command {
+		case fixedCommand:
+			err = handler.resolveReviewBlockerForCommit(
+				r, "owner", "repo", "0123456789abcdef", "https://command-url", "author", invs[0])
+		case reviewedCommand:
+			err = handler.markReviewedForCommit(
+				r, "owner", "repo", "0123456789abcdef", "https://command-url", "author", invs[0])
+		}
+		gh.Close()
+		if err != nil {
+			t.Errorf("%v: %v", d.body, err)
+			continue
+		}
+
+		if len(gh.queries) != 1 || !strings.Contains(gh.queries[0], `label:"code review"`) {
+			t.Errorf("%v: unexpected search queries: %q", d.body, gh.queries)
+		}
+
+		updated, err := issues.ParseReviewIssue(gh.issue)
+		if err != nil {
+			t.Errorf("%v: %v", d.body, err)
+			continue
+		}
+		if !d.check(updated) {
+			t.Errorf("%v: unexpected review issue body:\n%v", d.body, *gh.issue.Body)
+		}
+	}
+}
+
+func TestCreateReviewBlocker_NoReviewIssue(t *testing.T) {
+	gh := newTestingGitHub()
+	defer gh.Close()
+
+	r := httptest.NewRequest("POST", "/events", nil)
+	err := gh.newEventHandler().createReviewBlocker(
+		r, "owner", "blocker", "0123456789abcdef", "https://comment-url", "reviewer", "Fix it")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The review issues are searched for the commit, nothing is modified.
+	if len(gh.queries) != 1 || !strings.Contains(gh.queries[0], `"0123456" repo:"owner/blocker" label:"code review"`) {
+		t.Errorf("unexpected search queries: %q", gh.queries)
+	}
+	for _, req := range gh.requests {
+		if strings.HasPrefix(req, "PATCH") || strings.HasPrefix(req, "POST") {
+			t.Errorf("unexpected request: %v", req)
+		}
+	}
+}
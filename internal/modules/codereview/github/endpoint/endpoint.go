@@ -6,6 +6,10 @@ import (
 
 	// Internal
 	"github.com/salsaflow/salsaflow-daemon/internal/github"
+	"github.com/salsaflow/salsaflow-daemon/internal/modules"
+
+	// Vendor
+	"github.com/codegangsta/negroni"
 )
 
 type Endpoint struct{}
@@ -18,13 +22,22 @@ func (ep *Endpoint) ModuleId() string {
 	return ModuleId
 }
 
+func (ep *Endpoint) NewAuthMiddleware() (negroni.Handler, error) {
+	return github.NewAuthMiddleware(), nil
+}
+
 func (ep *Endpoint) NewHandler() (http.Handler, error) {
-	client, err := github.NewClient()
-	if err != nil {
+	if err := github.EnsureCredentials(); err != nil {
 		return nil, err
 	}
 
-	handler := github.NewWebhookHandler(&eventHandler{client})
+	handler, err := github.NewWebhookHandler(&eventHandler{
+		newClient: github.NewClientForRequest,
+		findStory: modules.FindStory,
+	})
+	if err != nil {
+		return nil, err
+	}
 
 	mux := http.NewServeMux()
 	mux.Handle("/events", handler)
@@ -3,16 +3,49 @@ package endpoint
 import (
 	// Stdlib
 	"errors"
+	"net/http"
 
 	// Internal
 	"github.com/salsaflow/salsaflow-daemon/internal/github/events"
+	"github.com/salsaflow/salsaflow-daemon/internal/github/repoconfig"
+	"github.com/salsaflow/salsaflow-daemon/internal/modules/codereview/github/config"
+	"github.com/salsaflow/salsaflow-daemon/internal/modules/common"
 
 	// Vendor
 	"github.com/google/go-github/github"
 )
 
 type eventHandler struct {
-	client *github.Client
+	// PushEventHandler drops the cached repository configuration on push.
+	repoconfig.PushEventHandler
+
+	// newClient returns the API client to be used for the given repository owner
+	// when handling the given webhook.
+	newClient func(r *http.Request, owner string) (*github.Client, error)
+
+	// findStory returns the story with the given tag
+	// from the issue tracker with the given module ID.
+	findStory func(moduleId, storyTag string) (common.Story, error)
+}
+
+// clientForRepo returns the API client and the configuration
+// to be used for the given repository.
+func (handler *eventHandler) clientForRepo(
+	r *http.Request,
+	owner string,
+	repo string,
+) (*github.Client, config.Config, error) {
+
+	client, err := handler.newClient(r, owner)
+	if err != nil {
+		return nil, config.Config{}, err
+	}
+
+	cfg, err := config.ForRepo(client, owner, repo)
+	if err != nil {
+		return nil, config.Config{}, err
+	}
+	return client, cfg, nil
 }
 
 func init() {
@@ -31,9 +64,29 @@ func ensureInterfaces() error {
 		return errors.New("eventHandler does not implement events.CommitCommentEventHandler")
 	}
 
+	if _, ok := handler.(events.IssueCommentEventHandler); !ok {
+		return errors.New("eventHandler does not implement events.IssueCommentEventHandler")
+	}
+
 	if _, ok := handler.(events.IssuesEventHandler); !ok {
 		return errors.New("eventHandler does not implement events.IssuesEventHandler")
 	}
 
+	if _, ok := handler.(events.PullRequestEventHandler); !ok {
+		return errors.New("eventHandler does not implement events.PullRequestEventHandler")
+	}
+
+	if _, ok := handler.(events.PullRequestReviewEventHandler); !ok {
+		return errors.New("eventHandler does not implement events.PullRequestReviewEventHandler")
+	}
+
+	if _, ok := handler.(events.PullRequestReviewCommentEventHandler); !ok {
+		return errors.New("eventHandler does not implement events.PullRequestReviewCommentEventHandler")
+	}
+
+	if _, ok := handler.(events.PushEventHandler); !ok {
+		return errors.New("eventHandler does not implement events.PushEventHandler")
+	}
+
 	return nil
 }
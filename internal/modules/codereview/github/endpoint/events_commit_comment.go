@@ -2,17 +2,17 @@ package endpoint
 
 import (
 	// Stdlib
-	"bufio"
 	"bytes"
 	"fmt"
 	"net/http"
-	"regexp"
-	"strings"
 
 	// Internal
 	"github.com/salsaflow/salsaflow-daemon/internal/github/events"
 	httputil "github.com/salsaflow/salsaflow-daemon/internal/http"
 	"github.com/salsaflow/salsaflow-daemon/internal/log"
+	"github.com/salsaflow/salsaflow-daemon/internal/metrics"
+	"github.com/salsaflow/salsaflow-daemon/internal/modules"
+	"github.com/salsaflow/salsaflow-daemon/internal/modules/common"
 
 	// Vendor
 	"github.com/google/go-github/github"
@@ -26,65 +26,119 @@ func (handler *eventHandler) HandleCommitCommentEvent(
 	r *http.Request,
 	event *events.CommitCommentEvent,
 ) {
-	// A command is always placed at the beginning of the line
-	// and it is prefixed with '!'.
-	cmdRegexp := regexp.MustCompile("^[!]([a-zA-Z]+) (.*)$")
+	var (
+		owner   = *event.Repo.Owner.Login
+		repo    = *event.Repo.Name
+		comment = event.Comment
+	)
 
 	// Process the comment body.
-	scanner := bufio.NewScanner(strings.NewReader(*event.Comment.Body))
-	for scanner.Scan() {
-		// Check whether this is a command and continue if not.
-		match := cmdRegexp.FindStringSubmatch(scanner.Text())
-		if len(match) == 0 {
-			continue
-		}
-		cmd, arg := match[1], strings.TrimSpace(match[2])
-
+	invs, errs := commitCommentCommands.Parse(*comment.Body)
+	for _, inv := range invs {
 		var err error
-		switch cmd {
-		case "mustfix":
-			err = handler.createReviewBlockerFromCommitComment(
+		switch inv.Command {
+		case common.MustfixCommand:
+			err = handler.createReviewBlocker(
+				r,
+				owner,
+				repo,
+				*comment.CommitID,
+				*comment.HTMLURL,
+				*comment.User.Login,
+				inv.Arg(0))
+
+		case fixedCommand, wontfixCommand:
+			err = handler.resolveReviewBlockerForCommit(
 				r,
-				*event.Repo.Owner.Login,
-				*event.Repo.Name,
-				event.Comment,
-				arg)
+				owner,
+				repo,
+				*comment.CommitID,
+				*comment.HTMLURL,
+				*comment.User.Login,
+				inv)
+
+		case reviewedCommand:
+			err = handler.markReviewedForCommit(
+				r,
+				owner,
+				repo,
+				*comment.CommitID,
+				*comment.HTMLURL,
+				*comment.User.Login,
+				inv)
 		}
 		if err != nil {
 			httputil.Error(rw, r, err)
 			return
 		}
 	}
-	if err := scanner.Err(); err != nil {
-		httputil.Error(rw, r, err)
-		return
-	}
+
+	replyToCommands(r, commitCommentCommands, *comment.User.Login, invs, errs,
+		func(reply string) error {
+			return handler.replyToCommitComment(r, owner, repo, comment, reply)
+		})
 
 	httputil.Status(rw, http.StatusAccepted)
 }
 
-func (handler *eventHandler) createReviewBlockerFromCommitComment(
+// replyToCommitComment posts the given reply as a comment for the commented commit.
+func (handler *eventHandler) replyToCommitComment(
 	r *http.Request,
 	owner string,
 	repo string,
 	comment *github.RepositoryComment,
+	reply string,
+) error {
+
+	client, err := handler.newClient(r, owner)
+	if err != nil {
+		return err
+	}
+
+	log.Info(r, "Replying to comment %v", *comment.HTMLURL)
+	_, _, err = client.Repositories.CreateComment(owner, repo, *comment.CommitID, &github.RepositoryComment{
+		Body: github.String(reply),
+	})
+	return err
+}
+
+// createReviewBlocker adds a new review blocker into the review issue
+// containing the given commit and it notifies the issue subscribers
+// by posting a comment into the review issue.
+func (handler *eventHandler) createReviewBlocker(
+	r *http.Request,
+	owner string,
+	repo string,
+	commitSHA string,
+	commentURL string,
+	commentAuthor string,
 	blockerSummary string,
 ) error {
 
+	client, cfg, err := handler.clientForRepo(r, owner, repo)
+	if err != nil {
+		return err
+	}
+
 	// Find the right review issue.
 	//
 	// We search the content of all review issues for the right commit hash.
-	var (
-		client        = handler.client
-		commitSHA     = *comment.CommitID
-		commentURL    = *comment.HTMLURL
-		commentAuthor = *comment.User.Login
-	)
+	issue, err := findReviewIssueByCommitItem(client, owner, repo, cfg.ReviewIssueLabel, commitSHA)
+	if err != nil {
+		return err
+	}
+	if issue == nil {
+		metrics.Skip(r)
+		log.Info(r, "No review issue found for commit %v in %v/%v, skipping", commitSHA, owner, repo)
+		return nil
+	}
 
-	issue, err := issues.FindReviewIssueByCommitItem(client, owner, repo, commitSHA)
+	// Lock the review issue, the body is going to be rewritten.
+	issue, unlock, err := lockReviewIssue(client, owner, repo, issue)
 	if err != nil {
 		return err
 	}
+	defer unlock()
 
 	// Parse issue body.
 	reviewIssue, err := issues.ParseReviewIssue(issue)
@@ -117,5 +171,15 @@ func (handler *eventHandler) createReviewBlockerFromCommitComment(
 	_, _, err = client.Issues.CreateComment(owner, repo, issueNum, &github.IssueComment{
 		Body: github.String(bodyBuffer.String()),
 	})
-	return err
+	if err != nil {
+		return err
+	}
+
+	// Let the story know in case this is a story review issue.
+	if storyIssue, ok := reviewIssue.(*issues.StoryReviewIssue); ok {
+		modules.NotifyStory(r, storyIssue.TrackerName, storyIssue.StoryKey, func(story common.Story) error {
+			return story.OnReviewBlockerOpened(commentURL, blockerSummary)
+		})
+	}
+	return nil
 }
@@ -0,0 +1,191 @@
+package endpoint
+
+import (
+	// Stdlib
+	"net/http"
+
+	// Internal
+	githubutil "github.com/salsaflow/salsaflow-daemon/internal/github"
+	"github.com/salsaflow/salsaflow-daemon/internal/github/events"
+	httputil "github.com/salsaflow/salsaflow-daemon/internal/http"
+	"github.com/salsaflow/salsaflow-daemon/internal/log"
+	"github.com/salsaflow/salsaflow-daemon/internal/metrics"
+	"github.com/salsaflow/salsaflow-daemon/internal/modules/codereview/github/config"
+
+	// Vendor
+	"github.com/google/go-github/github"
+)
+
+// HandleIssueCommentEvent implements events.IssueCommentEventHandler
+// and it is used to handle GitHub issue_comment events.
+//
+// Comments posted into review issues and pull requests are scanned
+// for !fixed and !wontfix commands resolving review blockers.
+// Comments posted into review issues are also scanned for !reviewed
+// marking commits in the commit checklist as reviewed.
+// See commands.go for the commands available in each context.
+func (handler *eventHandler) HandleIssueCommentEvent(
+	rw http.ResponseWriter,
+	r *http.Request,
+	event *events.IssueCommentEvent,
+) {
+	// Do nothing unless this is a created event.
+	if *event.Action != "created" {
+		httputil.Status(rw, http.StatusAccepted)
+		return
+	}
+
+	var (
+		owner   = *event.Repo.Owner.Login
+		repo    = *event.Repo.Name
+		comment = event.Comment
+	)
+
+	// Pick the commands available in this context.
+	registry := reviewIssueCommentCommands
+	if event.Issue.PullRequestLinks != nil {
+		registry = pullRequestCommentCommands
+	}
+
+	// Parse the commands first so that we don't need to do anything
+	// for the vast majority of comments that contain no commands.
+	invs, errs := registry.Parse(*comment.Body)
+	if len(invs) == 0 && len(errs) == 0 {
+		httputil.Status(rw, http.StatusAccepted)
+		return
+	}
+
+	client, cfg, err := handler.clientForRepo(r, owner, repo)
+	if err != nil {
+		httputil.Error(rw, r, err)
+		return
+	}
+
+	// Find the review issue.
+	issue, err := findReviewIssueForComment(r, client, cfg, owner, repo, event.Issue)
+	if err != nil {
+		httputil.Error(rw, r, err)
+		return
+	}
+
+	// Comments in issues other than review issues are none of our business.
+	if issue == nil && event.Issue.PullRequestLinks == nil {
+		metrics.Skip(r)
+		log.Info(r, "Issue %v is not a review issue, skipping", *event.Issue.HTMLURL)
+		httputil.Status(rw, http.StatusAccepted)
+		return
+	}
+
+	// Process the commands.
+	var (
+		commentURL    = *comment.HTMLURL
+		commentAuthor = *comment.User.Login
+	)
+	for _, inv := range invs {
+		if inv.IsHelp() {
+			continue
+		}
+		if issue == nil {
+			metrics.Skip(r)
+			log.Info(r, "No review issue found for %v, skipping %v", *event.Issue.HTMLURL, inv)
+			continue
+		}
+
+		var err error
+		switch inv.Command {
+		case fixedCommand, wontfixCommand:
+			err = resolveReviewBlocker(
+				r, client, cfg, owner, repo, issue, commentURL, commentAuthor, inv)
+
+		case reviewIssueReviewedCommand:
+			err = markReviewed(
+				r, client, cfg, owner, repo, issue, commentURL, commentAuthor, inv.Args)
+		}
+		if err != nil {
+			httputil.Error(rw, r, err)
+			return
+		}
+	}
+
+	issueNum := *event.Issue.Number
+	replyToCommands(r, registry, commentAuthor, invs, errs, func(reply string) error {
+		return handler.replyToIssue(r, owner, repo, issueNum, commentURL, reply)
+	})
+
+	httputil.Status(rw, http.StatusAccepted)
+}
+
+// replyToIssue posts the given reply to the given comment
+// into the issue or pull request conversation.
+func (handler *eventHandler) replyToIssue(
+	r *http.Request,
+	owner string,
+	repo string,
+	issueNum int,
+	commentURL string,
+	reply string,
+) error {
+
+	client, err := handler.newClient(r, owner)
+	if err != nil {
+		return err
+	}
+
+	log.Info(r, "Replying to comment %v", commentURL)
+	_, _, err = client.Issues.CreateComment(owner, repo, issueNum, &github.IssueComment{
+		Body: github.String(reply),
+	})
+	return err
+}
+
+// findReviewIssueForComment returns the review issue the comment belongs to.
+//
+// In case the comment was posted into a review issue, the issue is returned.
+// In case the comment was posted into a pull request, the review issue
+// containing any of the pull request commits is returned, newest commits first.
+// nil is returned when no review issue is found.
+func findReviewIssueForComment(
+	r *http.Request,
+	client *github.Client,
+	cfg config.Config,
+	owner string,
+	repo string,
+	commented *github.Issue,
+) (*github.Issue, error) {
+
+	// The label is sometimes missing in the webhook, we need to re-fetch.
+	issueNum := *commented.Number
+	log.Info(r, "Re-fetching issue %v/%v#%v", owner, repo, issueNum)
+	issue, _, err := client.Issues.Get(owner, repo, issueNum)
+	if err != nil {
+		return nil, err
+	}
+
+	if githubutil.LabeledWith(issue, cfg.ReviewIssueLabel) {
+		return issue, nil
+	}
+
+	// Unless this is a pull request, we are done.
+	if issue.PullRequestLinks == nil {
+		return nil, nil
+	}
+
+	commits, _, err := client.PullRequests.ListCommits(owner, repo, issueNum, nil)
+	if err != nil {
+		return nil, err
+	}
+	for i := len(commits) - 1; i >= 0; i-- {
+		if commits[i].SHA == nil {
+			continue
+		}
+		reviewIssue, err := findReviewIssueByCommitItem(
+			client, owner, repo, cfg.ReviewIssueLabel, *commits[i].SHA)
+		if err != nil {
+			return nil, err
+		}
+		if reviewIssue != nil {
+			return reviewIssue, nil
+		}
+	}
+	return nil, nil
+}
@@ -13,6 +13,7 @@ import (
 	httputil "github.com/salsaflow/salsaflow-daemon/internal/http"
 	"github.com/salsaflow/salsaflow-daemon/internal/log"
 	"github.com/salsaflow/salsaflow-daemon/internal/modules"
+	"github.com/salsaflow/salsaflow-daemon/internal/modules/codereview/github/config"
 
 	// Vendor
 	"github.com/google/go-github/github"
@@ -29,11 +30,16 @@ func (handler *eventHandler) HandleIssuesEvent(
 	// Make sure this is a review issue event.
 	// The label is sometimes missing in the webhook, we need to re-fetch.
 	var (
-		client   = handler.client
 		owner    = *event.Repo.Owner.Login
 		repo     = *event.Repo.Name
 		issueNum = *event.Issue.Number
 	)
+	client, err := handler.newClient(r, owner)
+	if err != nil {
+		httputil.Error(rw, r, err)
+		return
+	}
+
 	log.Info(r, "Re-fetching issue %v/%v#%v", owner, repo, issueNum)
 	issue, _, err := client.Issues.Get(owner, repo, issueNum)
 	if err != nil {
@@ -41,23 +47,34 @@ func (handler *eventHandler) HandleIssuesEvent(
 		return
 	}
 
-	if !githubutil.LabeledWith(issue, "review") {
+	// Get the configuration for this repository.
+	cfg, err := config.ForRepo(client, owner, repo)
+	if err != nil {
+		httputil.Error(rw, r, err)
+		return
+	}
+
+	if !githubutil.LabeledWith(issue, cfg.ReviewIssueLabel) {
 		log.Info(r, "Issue %s is not a review issue", *issue.HTMLURL)
 		httputil.Status(rw, http.StatusAccepted)
 		return
 	}
 
 	// Do nothing unless this is an opened, closed or reopened event.
+	// Edits and labels are only checked for the review being complete.
 	switch *event.Action {
 	case "opened":
 	case "closed":
 		// Make sure the issue is marked as implemented.
-		if !githubutil.LabeledWith(issue, "implemented") {
-			rejectClose(rw, r, client, event)
+		if !githubutil.LabeledWith(issue, cfg.StoryImplementedLabel) {
+			rejectClose(rw, r, client, event, cfg)
 			return
 		}
 
 	case "reopened":
+	case "edited", "labeled":
+		closeWhenComplete(rw, r, client, event, issue, cfg)
+		return
 	default:
 		httputil.Status(rw, http.StatusAccepted)
 		return
@@ -78,16 +95,9 @@ func (handler *eventHandler) HandleIssuesEvent(
 		return
 	}
 
-	// Instantiate the issue tracker.
-	tracker, err := modules.GetIssueTracker(storyIssue.TrackerName)
-	if err != nil {
-		log.Error(r, err)
-		httputil.Status(rw, httputil.StatusUnprocessableEntity)
-		return
-	}
-
 	// Find relevant story.
-	story, err := tracker.FindStoryByTag(storyIssue.StoryKey)
+	log.AddField(r, log.FieldStoryTag, storyIssue.StoryKey)
+	story, err := modules.FindStory(storyIssue.TrackerName, storyIssue.StoryKey)
 	if err != nil {
 		log.Error(r, err)
 		httputil.Status(rw, httputil.StatusUnprocessableEntity)
@@ -130,6 +140,7 @@ func rejectClose(
 	r *http.Request,
 	client *github.Client,
 	event *events.IssuesEvent,
+	cfg config.Config,
 ) {
 	var (
 		owner    = *event.Repo.Owner.Login
@@ -155,8 +166,9 @@ func rejectClose(
 	fmt.Fprintf(&body,
 		"@%v Reopening review issue #%v, the associated story is not implemented yet.\n",
 		sender, issueNum)
-	fmt.Fprintln(&body,
-		"The review issue needs to be labeled with `implemented`, then it can be closed.")
+	fmt.Fprintf(&body,
+		"The review issue needs to be labeled with `%v`, then it can be closed.\n",
+		cfg.StoryImplementedLabel)
 
 	_, _, err = client.Issues.CreateComment(owner, repo, issueNum, &github.IssueComment{
 		Body: github.String(body.String()),
@@ -168,3 +180,106 @@ func rejectClose(
 
 	httputil.Status(rw, http.StatusAccepted)
 }
+
+// closeWhenComplete closes the review issue in case all commits are reviewed,
+// all review blockers are fixed and the issue is labeled as implemented.
+// Closing the issue triggers the closed event, which is then handled as usual.
+//
+// Only the change completing the review closes the issue, i.e. the body edit
+// completing the checklists or labeling the issue as implemented. This way
+// the review issues reopened on purpose are not closed again on every change.
+func closeWhenComplete(
+	rw http.ResponseWriter,
+	r *http.Request,
+	client *github.Client,
+	event *events.IssuesEvent,
+	issue *github.Issue,
+	cfg config.Config,
+) {
+	var (
+		owner    = *event.Repo.Owner.Login
+		repo     = *event.Repo.Name
+		issueNum = *issue.Number
+	)
+
+	// Nothing to do in case the issue is closed already.
+	if issue.State != nil && *issue.State == "closed" {
+		httputil.Status(rw, http.StatusAccepted)
+		return
+	}
+
+	// Make sure this is the change completing the review.
+	completing, err := completesReview(event, cfg)
+	if err != nil {
+		log.Error(r, err)
+		httputil.Status(rw, httputil.StatusUnprocessableEntity)
+		return
+	}
+	if !completing {
+		httputil.Status(rw, http.StatusAccepted)
+		return
+	}
+
+	// Make sure the issue is marked as implemented.
+	if !githubutil.LabeledWith(issue, cfg.StoryImplementedLabel) {
+		httputil.Status(rw, http.StatusAccepted)
+		return
+	}
+
+	// Parse issue body.
+	reviewIssue, err := issues.ParseReviewIssue(issue)
+	if err != nil {
+		log.Error(r, err)
+		httputil.Status(rw, httputil.StatusUnprocessableEntity)
+		return
+	}
+
+	// Make sure the review is complete.
+	if !reviewComplete(reviewIssue) {
+		httputil.Status(rw, http.StatusAccepted)
+		return
+	}
+
+	// Close the issue.
+	if err := closeReviewIssue(r, client, owner, repo, issueNum); err != nil {
+		httputil.Error(rw, r, err)
+		return
+	}
+
+	httputil.Status(rw, http.StatusAccepted)
+}
+
+// completesReview returns true when the given edited or labeled event
+// is the change completing the review, judging by the event payload.
+func completesReview(event *events.IssuesEvent, cfg config.Config) (bool, error) {
+	// The review must be complete after the change.
+	after, err := issues.ParseReviewIssue(event.Issue)
+	if err != nil {
+		return false, err
+	}
+	if !reviewComplete(after) {
+		return false, nil
+	}
+
+	switch *event.Action {
+	case "labeled":
+		// The issue must have been labeled as implemented just now.
+		return event.Label != nil && event.Label.Name != nil &&
+			*event.Label.Name == cfg.StoryImplementedLabel, nil
+
+	case "edited":
+		// The review must have been incomplete before the change.
+		if event.Changes == nil || event.Changes.Body == nil || event.Changes.Body.From == nil {
+			return false, nil
+		}
+		before, err := issues.ParseReviewIssue(&github.Issue{
+			Title: event.Issue.Title,
+			Body:  event.Changes.Body.From,
+		})
+		if err != nil {
+			return false, err
+		}
+		return !reviewComplete(before), nil
+	}
+	return false, nil
+}
@@ -0,0 +1,95 @@
+package endpoint
+
+import (
+	// Stdlib
+	"net/http"
+	"strconv"
+
+	// Internal
+	"github.com/salsaflow/salsaflow-daemon/internal/github/events"
+	httputil "github.com/salsaflow/salsaflow-daemon/internal/http"
+	"github.com/salsaflow/salsaflow-daemon/internal/log"
+	"github.com/salsaflow/salsaflow-daemon/internal/metrics"
+	"github.com/salsaflow/salsaflow-daemon/internal/modules/common"
+
+	// Vendor
+	"github.com/google/go-github/github"
+)
+
+// HandlePullRequestEvent implements events.PullRequestEventHandler
+// and it is used to handle GitHub pull_request events.
+func (handler *eventHandler) HandlePullRequestEvent(
+	rw http.ResponseWriter,
+	r *http.Request,
+	event *events.PullRequestEvent,
+) {
+	// Do nothing unless this is an opened, closed or reopened event.
+	switch *event.Action {
+	case "opened":
+	case "closed":
+	case "reopened":
+	default:
+		httputil.Status(rw, http.StatusAccepted)
+		return
+	}
+
+	// Make sure the pull request references a story.
+	pr := event.PullRequest
+	trackerName, storyKey, ok := pullRequestStoryTags(pr)
+	if !ok {
+		metrics.Skip(r)
+		log.Info(r, "Pull request %v does not reference any story, skipping", *pr.HTMLURL)
+		httputil.Status(rw, http.StatusAccepted)
+		return
+	}
+
+	// Find relevant story.
+	log.AddField(r, log.FieldStoryTag, storyKey)
+	story, err := handler.findStory(trackerName, storyKey)
+	if err != nil {
+		log.Error(r, err)
+		httputil.Status(rw, httputil.StatusUnprocessableEntity)
+		return
+	}
+
+	// Invoke relevant event handler.
+	var (
+		prNumString = strconv.Itoa(*pr.Number)
+		prURL       = *pr.HTMLURL
+		ex          error
+	)
+	switch *event.Action {
+	case "opened":
+		ex = story.OnReviewRequestOpened(prNumString, prURL)
+	case "closed":
+		ex = story.OnReviewRequestClosed(prNumString, prURL)
+	case "reopened":
+		ex = story.OnReviewRequestReopened(prNumString, prURL)
+	default:
+		panic("unreachable code reached")
+	}
+	if ex != nil {
+		httputil.Error(rw, r, ex)
+		return
+	}
+
+	// A merged pull request means that the story has been reviewed.
+	// Closing a pull request without merging it says nothing about the story.
+	if *event.Action == "closed" && pr.Merged != nil && *pr.Merged {
+		log.Info(r, "Pull request %v merged, marking story %v as reviewed", prURL, storyKey)
+		if err := story.MarkAsReviewed(); err != nil {
+			httputil.Error(rw, r, err)
+			return
+		}
+	}
+
+	httputil.Status(rw, http.StatusAccepted)
+}
+
+// pullRequestStoryTags returns the story tags found in the pull request description.
+func pullRequestStoryTags(pr *github.PullRequest) (trackerName, storyKey string, ok bool) {
+	if pr.Body == nil {
+		return "", "", false
+	}
+	return common.ParseStoryTags(*pr.Body)
+}
@@ -0,0 +1,256 @@
+package endpoint
+
+import (
+	// Stdlib
+	"fmt"
+	"net/http"
+	"strings"
+
+	// Internal
+	"github.com/salsaflow/salsaflow-daemon/internal/github/events"
+	httputil "github.com/salsaflow/salsaflow-daemon/internal/http"
+	"github.com/salsaflow/salsaflow-daemon/internal/log"
+	"github.com/salsaflow/salsaflow-daemon/internal/metrics"
+	"github.com/salsaflow/salsaflow-daemon/internal/modules/common"
+
+	// Vendor
+	"github.com/google/go-github/github"
+	"github.com/salsaflow/salsaflow/github/issues"
+)
+
+// HandlePullRequestReviewEvent implements events.PullRequestReviewEventHandler
+// and it is used to handle GitHub pull_request_review events.
+//
+// A review requesting changes is turned into a review blocker,
+// an approving review marks the associated story as reviewed
+// unless there are changes requested or review blockers open still.
+func (handler *eventHandler) HandlePullRequestReviewEvent(
+	rw http.ResponseWriter,
+	r *http.Request,
+	event *events.PullRequestReviewEvent,
+) {
+	// Do nothing unless this is a submitted event.
+	if *event.Action != "submitted" {
+		httputil.Status(rw, http.StatusAccepted)
+		return
+	}
+
+	var (
+		owner  = *event.Repo.Owner.Login
+		repo   = *event.Repo.Name
+		review = event.Review
+		err    error
+	)
+	switch strings.ToLower(*review.State) {
+	case "changes_requested":
+		err = handler.createReviewBlocker(
+			r,
+			owner,
+			repo,
+			*review.CommitID,
+			*review.HTMLURL,
+			*review.User.Login,
+			reviewBlockerSummary(review))
+
+	case "approved":
+		err = handler.markPullRequestStoryAsReviewed(r, event)
+	}
+	if err != nil {
+		httputil.Error(rw, r, err)
+		return
+	}
+
+	httputil.Status(rw, http.StatusAccepted)
+}
+
+// HandlePullRequestReviewCommentEvent implements events.PullRequestReviewCommentEventHandler
+// and it is used to handle GitHub pull_request_review_comment events.
+//
+// The comment body is processed the same way as commit comments are,
+// so !mustfix can be used to open a review blocker from within a pull request
+// and !fixed or !wontfix can be used to resolve it.
+func (handler *eventHandler) HandlePullRequestReviewCommentEvent(
+	rw http.ResponseWriter,
+	r *http.Request,
+	event *events.PullRequestReviewCommentEvent,
+) {
+	// Do nothing unless this is a created event.
+	if *event.Action != "created" {
+		httputil.Status(rw, http.StatusAccepted)
+		return
+	}
+
+	var (
+		owner   = *event.Repo.Owner.Login
+		repo    = *event.Repo.Name
+		comment = event.Comment
+	)
+
+	// Process the comment body.
+	invs, errs := pullRequestReviewCommentCommands.Parse(*comment.Body)
+	for _, inv := range invs {
+		var err error
+		switch inv.Command {
+		case common.MustfixCommand:
+			err = handler.createReviewBlocker(
+				r,
+				owner,
+				repo,
+				*comment.CommitID,
+				*comment.HTMLURL,
+				*comment.User.Login,
+				inv.Arg(0))
+
+		case fixedCommand, wontfixCommand:
+			err = handler.resolveReviewBlockerForCommit(
+				r,
+				owner,
+				repo,
+				*comment.CommitID,
+				*comment.HTMLURL,
+				*comment.User.Login,
+				inv)
+		}
+		if err != nil {
+			httputil.Error(rw, r, err)
+			return
+		}
+	}
+
+	// The reply is posted into the pull request conversation.
+	prNum := *event.PullRequest.Number
+	replyToCommands(r, pullRequestReviewCommentCommands, *comment.User.Login, invs, errs,
+		func(reply string) error {
+			return handler.replyToIssue(r, owner, repo, prNum, *comment.HTMLURL, reply)
+		})
+
+	httputil.Status(rw, http.StatusAccepted)
+}
+
+// markPullRequestStoryAsReviewed marks the story referenced by the pull request
+// as reviewed once the pull request is approved. Nothing happens in case
+// there is a reviewer still requesting changes or there are review blockers
+// left unresolved in the review issue for the approved commit.
+func (handler *eventHandler) markPullRequestStoryAsReviewed(
+	r *http.Request,
+	event *events.PullRequestReviewEvent,
+) error {
+
+	// Make sure the pull request references a story.
+	pr := event.PullRequest
+	trackerName, storyKey, ok := pullRequestStoryTags(pr)
+	if !ok {
+		metrics.Skip(r)
+		log.Info(r, "Pull request %v does not reference any story, skipping", *pr.HTMLURL)
+		return nil
+	}
+
+	var (
+		owner = *event.Repo.Owner.Login
+		repo  = *event.Repo.Name
+	)
+	client, cfg, err := handler.clientForRepo(r, owner, repo)
+	if err != nil {
+		return err
+	}
+
+	// Make sure nobody is requesting changes.
+	requested, err := changesRequested(client, owner, repo, *pr.Number)
+	if err != nil {
+		return err
+	}
+	if requested {
+		metrics.Skip(r)
+		log.Info(r, "Pull request %v still has changes requested, skipping", *pr.HTMLURL)
+		return nil
+	}
+
+	// Make sure all review blockers are resolved.
+	commitSHA := *event.Review.CommitID
+	issue, err := findReviewIssueByCommitItem(client, owner, repo, cfg.ReviewIssueLabel, commitSHA)
+	if err != nil {
+		return err
+	}
+	if issue != nil {
+		reviewIssue, err := issues.ParseReviewIssue(issue)
+		if err != nil {
+			return err
+		}
+		for _, blocker := range reviewIssue.ReviewBlockerItems() {
+			if !blocker.Fixed {
+				metrics.Skip(r)
+				log.Info(r, "Pull request %v still has review blockers open in %v, skipping",
+					*pr.HTMLURL, *issue.HTMLURL)
+				return nil
+			}
+		}
+	}
+
+	// Find relevant story.
+	log.AddField(r, log.FieldStoryTag, storyKey)
+	story, err := handler.findStory(trackerName, storyKey)
+	if err != nil {
+		return err
+	}
+
+	// Mark the story as reviewed.
+	log.Info(r, "Pull request %v approved by @%v, marking story %v as reviewed",
+		*pr.HTMLURL, *event.Review.User.Login, storyKey)
+	return story.MarkAsReviewed()
+}
+
+// changesRequested returns true in case the latest review
+// of any of the pull request reviewers requests changes.
+//
+// The vendored go-github package does not know about reviews yet,
+// so the API is called directly.
+func changesRequested(client *github.Client, owner, repo string, prNum int) (bool, error) {
+	// The reviews are listed in chronological order.
+	latest := make(map[string]string)
+	for page := 1; page != 0; {
+		u := fmt.Sprintf("repos/%v/%v/pulls/%v/reviews?per_page=100&page=%v", owner, repo, prNum, page)
+		req, err := client.NewRequest("GET", u, nil)
+		if err != nil {
+			return false, err
+		}
+
+		var reviews []*events.PullRequestReview
+		resp, err := client.Do(req, &reviews)
+		if err != nil {
+			return false, err
+		}
+
+		for _, review := range reviews {
+			if review.User == nil || review.User.Login == nil || review.State == nil {
+				continue
+			}
+			// Comments do not change the review state of the reviewer.
+			switch state := strings.ToLower(*review.State); state {
+			case "approved", "changes_requested", "dismissed":
+				latest[*review.User.Login] = state
+			}
+		}
+
+		page = resp.NextPage
+	}
+
+	for _, state := range latest {
+		if state == "changes_requested" {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// reviewBlockerSummary returns the first non-empty line of the review body.
+// A generic summary is returned in case the review body is empty.
+func reviewBlockerSummary(review *events.PullRequestReview) string {
+	if review.Body != nil {
+		for _, line := range strings.Split(*review.Body, "\n") {
+			if line = strings.TrimSpace(line); line != "" {
+				return line
+			}
+		}
+	}
+	return fmt.Sprintf("Changes requested by @%v.", *review.User.Login)
+}
@@ -0,0 +1,195 @@
+package endpoint
+
+import (
+	// Stdlib
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	// Internal
+	"github.com/salsaflow/salsaflow-daemon/internal/github/events"
+
+	// Vendor
+	"github.com/google/go-github/github"
+	"github.com/salsaflow/salsaflow/github/issues"
+)
+
+const testingPullRequestBody = "Do the thing.\n\nSF-Issue-Tracker: jira\nSF-Story-Key: SF-42\n"
+
+func newTestingPullRequest() *github.PullRequest {
+	return &github.PullRequest{
+		Number:  github.Int(1),
+		Body:    github.String(testingPullRequestBody),
+		HTMLURL: github.String("https://github.com/owner/repo/pull/1"),
+	}
+}
+
+func newTestingReview(login, state string) *events.PullRequestReview {
+	return &events.PullRequestReview{
+		User:     &github.User{Login: github.String(login)},
+		Body:     github.String("Rename it.\n\nThe name is confusing."),
+		CommitID: github.String("0123456789abcdef"),
+		State:    github.String(state),
+		HTMLURL:  github.String("https://github.com/owner/repo/pull/1#review-1"),
+	}
+}
+
+func newPullRequestReviewEvent(review *events.PullRequestReview) *events.PullRequestReviewEvent {
+	return &events.PullRequestReviewEvent{
+		Action:      github.String("submitted"),
+		Review:      review,
+		PullRequest: newTestingPullRequest(),
+		Repo: &github.Repository{
+			Name:  github.String("repo"),
+			Owner: &github.User{Login: github.String("owner")},
+		},
+		Sender: review.User,
+	}
+}
+
+func TestHandlePullRequestReviewEvent_ChangesRequested(t *testing.T) {
+	gh := newTestingGitHub("code review")
+	defer gh.Close()
+
+	reviewIssue := newTestingReviewIssue()
+	gh.issue.Title = github.String(reviewIssue.FormatTitle())
+	gh.issue.Body = github.String(reviewIssue.FormatBody())
+
+	rw := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", "/events", nil)
+	event := newPullRequestReviewEvent(newTestingReview("reviewer", "CHANGES_REQUESTED"))
+	gh.newEventHandler().HandlePullRequestReviewEvent(rw, r, event)
+
+	if rw.Code != http.StatusAccepted {
+		t.Fatalf("expected status %v, got %v", http.StatusAccepted, rw.Code)
+	}
+
+	updated, err := issues.ParseReviewIssue(gh.issue)
+	if err != nil {
+		t.Fatal(err)
+	}
+	blockers := updated.ReviewBlockerItems()
+	if len(blockers) != 3 || blockers[2].BlockerSummary != "Rename it." {
+		t.Errorf("unexpected review issue body:\n%v", *gh.issue.Body)
+	}
+	if len(gh.story.calls) != 0 {
+		t.Errorf("unexpected story calls: %v", gh.story.calls)
+	}
+}
+
+func TestHandlePullRequestReviewEvent_Approved(t *testing.T) {
+	data := []struct {
+		desc     string
+		reviews  []*events.PullRequestReview
+		blockers bool
+		reviewed bool
+	}{
+		{
+			"approved",
+			[]*events.PullRequestReview{newTestingReview("reviewer", "APPROVED")},
+			false,
+			true,
+		},
+		{
+			"changes requested by another reviewer",
+			[]*events.PullRequestReview{
+				newTestingReview("other", "CHANGES_REQUESTED"),
+				newTestingReview("other", "COMMENTED"),
+				newTestingReview("reviewer", "APPROVED"),
+			},
+			false,
+			false,
+		},
+		{
+			"changes requested and approved later",
+			[]*events.PullRequestReview{
+				newTestingReview("other", "CHANGES_REQUESTED"),
+				newTestingReview("other", "APPROVED"),
+				newTestingReview("reviewer", "APPROVED"),
+			},
+			false,
+			true,
+		},
+		{
+			"review blockers open",
+			[]*events.PullRequestReview{newTestingReview("reviewer", "APPROVED")},
+			true,
+			false,
+		},
+	}
+
+	for _, d := range data {
+		gh := newTestingGitHub("code review")
+		gh.reviews = d.reviews
+
+		reviewIssue := newTestingReviewIssue()
+		if !d.blockers {
+			for _, blocker := range reviewIssue.ReviewBlockerItems() {
+				blocker.Fixed = true
+			}
+		}
+		gh.issue.Title = github.String(reviewIssue.FormatTitle())
+		gh.issue.Body = github.String(reviewIssue.FormatBody())
+
+		rw := httptest.NewRecorder()
+		r := httptest.NewRequest("POST", "/events", nil)
+		event := newPullRequestReviewEvent(newTestingReview("reviewer", "APPROVED"))
+		gh.newEventHandler().HandlePullRequestReviewEvent(rw, r, event)
+		gh.Close()
+
+		if rw.Code != http.StatusAccepted {
+			t.Errorf("%v: expected status %v, got %v", d.desc, http.StatusAccepted, rw.Code)
+		}
+
+		var expected []string
+		if d.reviewed {
+			expected = []string{"reviewed"}
+		}
+		if !reflect.DeepEqual(gh.story.calls, expected) {
+			t.Errorf("%v: expected story calls %v, got %v", d.desc, expected, gh.story.calls)
+		}
+	}
+}
+
+func TestHandlePullRequestReviewCommentEvent_Mustfix(t *testing.T) {
+	gh := newTestingGitHub("code review")
+	defer gh.Close()
+
+	reviewIssue := newTestingReviewIssue()
+	gh.issue.Title = github.String(reviewIssue.FormatTitle())
+	gh.issue.Body = github.String(reviewIssue.FormatBody())
+
+	event := &events.PullRequestReviewCommentEvent{
+		Action: github.String("created"),
+		Comment: &events.PullRequestReviewComment{
+			Body:     github.String("This is wrong.\n!mustfix Fix the off-by-one error"),
+			CommitID: github.String("0123456789abcdef"),
+			User:     &github.User{Login: github.String("reviewer")},
+			HTMLURL:  github.String("https://github.com/owner/repo/pull/1#discussion_r1"),
+		},
+		PullRequest: newTestingPullRequest(),
+		Repo: &github.Repository{
+			Name:  github.String("repo"),
+			Owner: &github.User{Login: github.String("owner")},
+		},
+	}
+
+	rw := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", "/events", nil)
+	gh.newEventHandler().HandlePullRequestReviewCommentEvent(rw, r, event)
+
+	if rw.Code != http.StatusAccepted {
+		t.Fatalf("expected status %v, got %v", http.StatusAccepted, rw.Code)
+	}
+
+	updated, err := issues.ParseReviewIssue(gh.issue)
+	if err != nil {
+		t.Fatal(err)
+	}
+	blockers := updated.ReviewBlockerItems()
+	if len(blockers) != 3 || blockers[2].BlockerSummary != "Fix the off-by-one error" ||
+		blockers[2].CommentURL != "https://github.com/owner/repo/pull/1#discussion_r1" {
+		t.Errorf("unexpected review issue body:\n%v", *gh.issue.Body)
+	}
+}
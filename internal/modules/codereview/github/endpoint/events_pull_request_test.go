@@ -0,0 +1,86 @@
+package endpoint
+
+import (
+	// Stdlib
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	// Internal
+	"github.com/salsaflow/salsaflow-daemon/internal/github/events"
+
+	// Vendor
+	"github.com/google/go-github/github"
+)
+
+func TestHandlePullRequestEvent(t *testing.T) {
+	data := []struct {
+		action   string
+		merged   bool
+		expected []string
+	}{
+		{"opened", false, []string{"opened 1"}},
+		{"closed", true, []string{"closed 1", "reviewed"}},
+		{"closed", false, []string{"closed 1"}},
+		{"reopened", false, []string{"reopened 1"}},
+		{"edited", false, nil},
+	}
+
+	for _, d := range data {
+		gh := newTestingGitHub()
+
+		pr := newTestingPullRequest()
+		pr.Merged = github.Bool(d.merged)
+		event := &events.PullRequestEvent{
+			Action:      github.String(d.action),
+			Number:      pr.Number,
+			PullRequest: pr,
+			Repo: &github.Repository{
+				Name:  github.String("repo"),
+				Owner: &github.User{Login: github.String("owner")},
+			},
+		}
+
+		rw := httptest.NewRecorder()
+		r := httptest.NewRequest("POST", "/events", nil)
+		gh.newEventHandler().HandlePullRequestEvent(rw, r, event)
+		gh.Close()
+
+		if rw.Code != http.StatusAccepted {
+			t.Errorf("%v (merged %v): expected status %v, got %v",
+				d.action, d.merged, http.StatusAccepted, rw.Code)
+		}
+		if !reflect.DeepEqual(gh.story.calls, d.expected) {
+			t.Errorf("%v (merged %v): expected story calls %v, got %v",
+				d.action, d.merged, d.expected, gh.story.calls)
+		}
+	}
+}
+
+func TestHandlePullRequestEvent_NoStory(t *testing.T) {
+	gh := newTestingGitHub()
+	defer gh.Close()
+
+	pr := newTestingPullRequest()
+	pr.Body = github.String("Do the thing.")
+	event := &events.PullRequestEvent{
+		Action:      github.String("opened"),
+		PullRequest: pr,
+		Repo: &github.Repository{
+			Name:  github.String("repo"),
+			Owner: &github.User{Login: github.String("owner")},
+		},
+	}
+
+	rw := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", "/events", nil)
+	gh.newEventHandler().HandlePullRequestEvent(rw, r, event)
+
+	if rw.Code != http.StatusAccepted {
+		t.Errorf("expected status %v, got %v", http.StatusAccepted, rw.Code)
+	}
+	if len(gh.story.calls) != 0 {
+		t.Errorf("unexpected story calls: %v", gh.story.calls)
+	}
+}
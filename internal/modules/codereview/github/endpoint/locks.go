@@ -0,0 +1,76 @@
+package endpoint
+
+import (
+	// Stdlib
+	"fmt"
+	"strings"
+	"sync"
+
+	// Vendor
+	"github.com/google/go-github/github"
+)
+
+// reviewIssueLocks serializes the review issue updates. The review issue body
+// is read, modified and written back as a whole, so the queue workers updating
+// the same review issue at the same time would lose some of the updates otherwise.
+var reviewIssueLocks = newIssueLocks()
+
+type issueLocks struct {
+	mu    sync.Mutex
+	locks map[string]*issueLock
+}
+
+type issueLock struct {
+	sync.Mutex
+	refs int
+}
+
+func newIssueLocks() *issueLocks {
+	return &issueLocks{locks: make(map[string]*issueLock)}
+}
+
+// lock locks the given issue and it returns the function unlocking it.
+// The lock is dropped once there is nobody waiting for it.
+func (locks *issueLocks) lock(owner, repo string, issueNum int) (unlock func()) {
+	key := strings.ToLower(fmt.Sprintf("%v/%v#%v", owner, repo, issueNum))
+
+	locks.mu.Lock()
+	l, ok := locks.locks[key]
+	if !ok {
+		l = &issueLock{}
+		locks.locks[key] = l
+	}
+	l.refs++
+	locks.mu.Unlock()
+
+	l.Lock()
+	return func() {
+		l.Unlock()
+
+		locks.mu.Lock()
+		l.refs--
+		if l.refs == 0 {
+			delete(locks.locks, key)
+		}
+		locks.mu.Unlock()
+	}
+}
+
+// lockReviewIssue locks the given review issue and it re-fetches the issue
+// so that the changes made while waiting for the lock are not overwritten.
+// The function returned is to be called to unlock the issue.
+func lockReviewIssue(
+	client *github.Client,
+	owner string,
+	repo string,
+	issue *github.Issue,
+) (*github.Issue, func(), error) {
+
+	unlock := reviewIssueLocks.lock(owner, repo, *issue.Number)
+	current, _, err := client.Issues.Get(owner, repo, *issue.Number)
+	if err != nil {
+		unlock()
+		return nil, nil, err
+	}
+	return current, unlock, nil
+}
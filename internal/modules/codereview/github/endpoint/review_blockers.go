@@ -0,0 +1,216 @@
+package endpoint
+
+import (
+	// Stdlib
+	"bytes"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	// Internal
+	"github.com/salsaflow/salsaflow-daemon/internal/commands"
+	"github.com/salsaflow/salsaflow-daemon/internal/log"
+	"github.com/salsaflow/salsaflow-daemon/internal/metrics"
+	"github.com/salsaflow/salsaflow-daemon/internal/modules"
+	"github.com/salsaflow/salsaflow-daemon/internal/modules/codereview/github/config"
+	"github.com/salsaflow/salsaflow-daemon/internal/modules/common"
+
+	// Vendor
+	"github.com/google/go-github/github"
+	"github.com/salsaflow/salsaflow/github/issues"
+)
+
+// wontfixSummaryPrefix is prepended to the summary of the review blockers
+// resolved as won't fix so that they can be told apart in the checklist.
+const wontfixSummaryPrefix = "(won't fix) "
+
+// blockerResolution represents a parsed !fixed or !wontfix command.
+type blockerResolution struct {
+	// blockerRef is either the blocker number or the blocker comment URL.
+	blockerRef string
+
+	// wontfix is set for !wontfix, otherwise this is !fixed.
+	wontfix bool
+
+	// commitSHA is the commit fixing the blocker, it is optional.
+	commitSHA string
+
+	// reason is the explanation why the blocker is not going to be fixed.
+	reason string
+}
+
+// newBlockerResolution returns the resolution for the given !fixed or !wontfix command.
+func newBlockerResolution(inv *commands.Invocation) *blockerResolution {
+	resolution := &blockerResolution{blockerRef: inv.Arg(0)}
+	switch inv.Command {
+	case fixedCommand:
+		resolution.commitSHA = inv.Arg(1)
+	case wontfixCommand:
+		resolution.wontfix = true
+		resolution.reason = inv.Arg(1)
+	}
+	return resolution
+}
+
+// findReviewBlocker returns the review blocker matching the given reference,
+// which is either the blocker number or the blocker comment URL.
+// It returns nil in case there is no such blocker.
+func findReviewBlocker(reviewIssue issues.ReviewIssue, blockerRef string) *issues.ReviewBlockerItem {
+	blockerNum, err := strconv.Atoi(strings.TrimPrefix(blockerRef, "#"))
+	for _, item := range reviewIssue.ReviewBlockerItems() {
+		if err == nil && item.BlockerNumber == blockerNum {
+			return item
+		}
+		if item.CommentURL == blockerRef {
+			return item
+		}
+	}
+	return nil
+}
+
+// resolveReviewBlockerForCommit finds the review issue containing the given commit
+// and it resolves the review blocker as specified by the given !fixed or !wontfix command.
+func (handler *eventHandler) resolveReviewBlockerForCommit(
+	r *http.Request,
+	owner string,
+	repo string,
+	commitSHA string,
+	commentURL string,
+	commentAuthor string,
+	inv *commands.Invocation,
+) error {
+
+	client, cfg, err := handler.clientForRepo(r, owner, repo)
+	if err != nil {
+		return err
+	}
+
+	// Find the right review issue.
+	issue, err := findReviewIssueByCommitItem(client, owner, repo, cfg.ReviewIssueLabel, commitSHA)
+	if err != nil {
+		return err
+	}
+	if issue == nil {
+		metrics.Skip(r)
+		log.Info(r, "No review issue found for commit %v in %v/%v, skipping", commitSHA, owner, repo)
+		return nil
+	}
+
+	return resolveReviewBlocker(r, client, cfg, owner, repo, issue, commentURL, commentAuthor, inv)
+}
+
+// resolveReviewBlocker marks the review blocker referenced by the given command
+// as fixed in the given review issue and it posts an acknowledgement
+// into the review issue. Blockers resolved as won't fix are marked as such
+// in the review blocker checklist.
+func resolveReviewBlocker(
+	r *http.Request,
+	client *github.Client,
+	cfg config.Config,
+	owner string,
+	repo string,
+	issue *github.Issue,
+	commentURL string,
+	commentAuthor string,
+	inv *commands.Invocation,
+) error {
+
+	resolution := newBlockerResolution(inv)
+
+	// Lock the review issue, the body is going to be rewritten.
+	issue, unlock, err := lockReviewIssue(client, owner, repo, issue)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	// Parse issue body.
+	reviewIssue, err := issues.ParseReviewIssue(issue)
+	if err != nil {
+		return err
+	}
+
+	// Find the blocker.
+	issueNum := *issue.Number
+	blocker := findReviewBlocker(reviewIssue, resolution.blockerRef)
+	if blocker == nil {
+		metrics.Skip(r)
+		log.Info(r, "Review blocker %v not found in review issue %v/%v#%v, skipping",
+			resolution.blockerRef, owner, repo, issueNum)
+		return nil
+	}
+	if blocker.Fixed {
+		metrics.Skip(r)
+		log.Info(r, "Review blocker %v in review issue %v/%v#%v already fixed, skipping",
+			blocker.BlockerNumber, owner, repo, issueNum)
+		return nil
+	}
+
+	// Tick the blocker off, marking it in case it is not going to be fixed.
+	summary := blocker.BlockerSummary
+	blocker.Fixed = true
+	if resolution.wontfix {
+		blocker.BlockerSummary = wontfixSummaryPrefix + summary
+	}
+
+	// Complete the review in case this was the last step.
+	complete := reviewComplete(reviewIssue)
+	if complete {
+		if err := completeReview(r, client, cfg, owner, repo, issue); err != nil {
+			return err
+		}
+	}
+
+	// Update the review issue.
+	_, _, err = client.Issues.Edit(owner, repo, issueNum, &github.IssueRequest{
+		Body: github.String(reviewIssue.FormatBody()),
+	})
+	if err != nil {
+		return err
+	}
+
+	if resolution.wontfix {
+		log.Info(r, "Review blocker %v in review issue %v/%v#%v resolved as won't fix",
+			blocker.BlockerNumber, owner, repo, issueNum)
+	} else {
+		log.Info(r, "Review blocker %v in review issue %v/%v#%v marked as fixed",
+			blocker.BlockerNumber, owner, repo, issueNum)
+	}
+
+	// Post the acknowledgement.
+	var bodyBuffer bytes.Buffer
+	if resolution.wontfix {
+		fmt.Fprintf(&bodyBuffer,
+			"[Review blocker %v](%v) was [resolved](%v) by @%v as won't fix. The reason follows:\n",
+			blocker.BlockerNumber, blocker.CommentURL, commentURL, commentAuthor)
+		fmt.Fprintf(&bodyBuffer, "> %v\n", resolution.reason)
+	} else {
+		fmt.Fprintf(&bodyBuffer,
+			"[Review blocker %v](%v) was [marked as fixed](%v) by @%v",
+			blocker.BlockerNumber, blocker.CommentURL, commentURL, commentAuthor)
+		if resolution.commitSHA != "" {
+			fmt.Fprintf(&bodyBuffer, " in commit %v", resolution.commitSHA)
+		}
+		fmt.Fprint(&bodyBuffer, ".\n")
+	}
+	if complete {
+		fmt.Fprint(&bodyBuffer, "\n")
+		fmt.Fprint(&bodyBuffer, reviewCompleteMessage(cfg))
+	}
+
+	_, _, err = client.Issues.CreateComment(owner, repo, issueNum, &github.IssueComment{
+		Body: github.String(bodyBuffer.String()),
+	})
+	if err != nil {
+		return err
+	}
+
+	// Let the story know in case this is a story review issue.
+	if storyIssue, ok := reviewIssue.(*issues.StoryReviewIssue); ok {
+		modules.NotifyStory(r, storyIssue.TrackerName, storyIssue.StoryKey, func(story common.Story) error {
+			return story.OnReviewBlockerFixed(blocker.CommentURL, summary)
+		})
+	}
+	return nil
+}
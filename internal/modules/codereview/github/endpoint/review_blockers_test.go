@@ -0,0 +1,253 @@
+package endpoint
+
+import (
+	// Stdlib
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	// Internal
+	"github.com/salsaflow/salsaflow-daemon/internal/commands"
+	"github.com/salsaflow/salsaflow-daemon/internal/github/events"
+	"github.com/salsaflow/salsaflow-daemon/internal/modules/codereview/github/config"
+
+	// Vendor
+	"github.com/google/go-github/github"
+	"github.com/salsaflow/salsaflow/github/issues"
+)
+
+func TestBlockerResolutionCommands(t *testing.T) {
+	data := []struct {
+		body     string
+		expected *blockerResolution
+	}{
+		{"!fixed 1", &blockerResolution{blockerRef: "1"}},
+		{"!fixed #2 0123456", &blockerResolution{blockerRef: "#2", commitSHA: "0123456"}},
+		{"!fixed https://comment-url", &blockerResolution{blockerRef: "https://comment-url"}},
+		{"!fixed 1 not-a-sha", nil},
+		{"!fixed 1 0123456 extra", nil},
+		{"!fixed", nil},
+		{"!wontfix 3 Not worth it.", &blockerResolution{blockerRef: "3", wontfix: true, reason: "Not worth it."}},
+		{`!wontfix 3 "Not worth it."`, &blockerResolution{blockerRef: "3", wontfix: true, reason: "Not worth it."}},
+		{"!wontfix 3", nil},
+	}
+
+	for _, d := range data {
+		invs, errs := reviewIssueCommentCommands.Parse(d.body)
+		switch {
+		case d.expected == nil && len(errs) == 0:
+			t.Errorf("%v: expected an error", d.body)
+		case d.expected != nil && len(errs) != 0:
+			t.Errorf("%v: unexpected errors: %v", d.body, errs)
+		case d.expected != nil && len(invs) != 1:
+			t.Errorf("%v: expected a single command, got %v", d.body, invs)
+		case d.expected != nil:
+			if resolution := newBlockerResolution(invs[0]); *resolution != *d.expected {
+				t.Errorf("%v: expected %+v, got %+v", d.body, d.expected, resolution)
+			}
+		}
+	}
+}
+
+// parseInvocation returns the only command contained in the given body.
+func parseInvocation(t *testing.T, body string) *commands.Invocation {
+	invs, errs := reviewIssueCommentCommands.Parse(body)
+	if len(errs) != 0 || len(invs) != 1 {
+		t.Fatalf("%v: unexpected parse result: %v, %v", body, invs, errs)
+	}
+	return invs[0]
+}
+
+func newTestingReviewIssue() *issues.CommitReviewIssue {
+	reviewIssue := issues.NewCommitReviewIssue("0123456", "Do the thing")
+	reviewIssue.AddReviewBlocker(false, "https://comment-url/1", "0123456", "Fix the typo")
+	reviewIssue.AddReviewBlocker(false, "https://comment-url/2", "0123456", "Fix the naming")
+	return reviewIssue
+}
+
+func TestFindReviewBlocker(t *testing.T) {
+	reviewIssue := newTestingReviewIssue()
+
+	data := []struct {
+		ref      string
+		expected int
+	}{
+		{"1", 1},
+		{"#2", 2},
+		{"https://comment-url/2", 2},
+		{"3", 0},
+		{"https://comment-url/3", 0},
+	}
+
+	for _, d := range data {
+		blocker := findReviewBlocker(reviewIssue, d.ref)
+		switch {
+		case d.expected == 0 && blocker != nil:
+			t.Errorf("%v: expected no blocker, got blocker %v", d.ref, blocker.BlockerNumber)
+		case d.expected != 0 && blocker == nil:
+			t.Errorf("%v: expected blocker %v, got none", d.ref, d.expected)
+		case d.expected != 0 && blocker.BlockerNumber != d.expected:
+			t.Errorf("%v: expected blocker %v, got blocker %v", d.ref, d.expected, blocker.BlockerNumber)
+		}
+	}
+}
+
+func TestResolveReviewBlocker(t *testing.T) {
+	data := []struct {
+		body    string
+		summary string
+		comment string
+	}{
+		{
+			"!fixed 2 89abcde",
+			"Fix the naming",
+			"[Review blocker 2](https://comment-url/2) was [marked as fixed](https://command-url) by @author in commit 89abcde.\n",
+		},
+		{
+			"!wontfix https://comment-url/2 Not worth it.",
+			"(won't fix) Fix the naming",
+			"[Review blocker 2](https://comment-url/2) was [resolved](https://command-url) by @author as won't fix. The reason follows:\n> Not worth it.\n",
+		},
+	}
+
+	for _, d := range data {
+		gh := newTestingGitHub("code review")
+
+		reviewIssue := newTestingReviewIssue()
+		gh.issue.Title = github.String(reviewIssue.FormatTitle())
+		gh.issue.Body = github.String(reviewIssue.FormatBody())
+
+		client := gh.newClient()
+
+		r := httptest.NewRequest("POST", "/events", nil)
+		err := resolveReviewBlocker(
+			r, client, config.Config{}, "owner", "repo", gh.issue, "https://command-url", "author",
+			parseInvocation(t, d.body))
+		gh.Close()
+		if err != nil {
+			t.Errorf("%v: %v", d.body, err)
+			continue
+		}
+
+		updated, err := issues.ParseReviewIssue(gh.issue)
+		if err != nil {
+			t.Errorf("%v: %v", d.body, err)
+			continue
+		}
+		blockers := updated.ReviewBlockerItems()
+		if blockers[0].Fixed || !blockers[1].Fixed || blockers[1].BlockerSummary != d.summary {
+			t.Errorf("%v: unexpected review issue body:\n%v", d.body, *gh.issue.Body)
+		}
+
+		if len(gh.comments) != 1 || gh.comments[0] != d.comment {
+			t.Errorf("%v: unexpected comments: %q", d.body, gh.comments)
+		}
+	}
+}
+
+func TestResolveReviewBlocker_AlreadyFixed(t *testing.T) {
+	gh := newTestingGitHub("code review")
+	defer gh.Close()
+
+	reviewIssue := newTestingReviewIssue()
+	reviewIssue.ReviewBlockerItems()[0].Fixed = true
+	gh.issue.Title = github.String(reviewIssue.FormatTitle())
+	gh.issue.Body = github.String(reviewIssue.FormatBody())
+
+	client := gh.newClient()
+
+	r := httptest.NewRequest("POST", "/events", nil)
+	err := resolveReviewBlocker(
+		r, client, config.Config{}, "owner", "repo", gh.issue, "https://command-url", "author",
+		parseInvocation(t, "!fixed 1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, req := range gh.requests {
+		if strings.HasPrefix(req, "PATCH") || strings.HasPrefix(req, "POST") {
+			t.Errorf("unexpected request: %v", req)
+		}
+	}
+}
+
+func TestResolveReviewBlocker_Concurrent(t *testing.T) {
+	gh := newTestingGitHub("code review")
+	defer gh.Close()
+
+	reviewIssue := newTestingReviewIssue()
+	gh.issue.Title = github.String(reviewIssue.FormatTitle())
+	gh.issue.Body = github.String(reviewIssue.FormatBody())
+
+	client := gh.newClient()
+
+	// Both workers start with the same stale issue, none of the updates may be lost.
+	var (
+		stale = *gh.issue
+		wg    sync.WaitGroup
+	)
+	for _, body := range []string{"!fixed 1", "!wontfix 2 Not worth it."} {
+		wg.Add(1)
+		go func(inv *commands.Invocation) {
+			defer wg.Done()
+			issue := stale
+			r := httptest.NewRequest("POST", "/events", nil)
+			err := resolveReviewBlocker(
+				r, client, config.Config{}, "owner", "repo", &issue, "https://command-url", "author", inv)
+			if err != nil {
+				t.Error(err)
+			}
+		}(parseInvocation(t, body))
+	}
+	wg.Wait()
+
+	updated, err := issues.ParseReviewIssue(gh.issue)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, blocker := range updated.ReviewBlockerItems() {
+		if !blocker.Fixed {
+			t.Errorf("review blocker %v not resolved:\n%v", blocker.BlockerNumber, *gh.issue.Body)
+		}
+	}
+}
+
+func TestHandleIssueCommentEvent_ReplyFailure(t *testing.T) {
+	gh := newTestingGitHub("code review")
+	defer gh.Close()
+
+	reviewIssue := newTestingReviewIssue()
+	gh.issue.Title = github.String(reviewIssue.FormatTitle())
+	gh.issue.Body = github.String(reviewIssue.FormatBody())
+
+	// Only the acknowledgement can be posted, the !help reply fails.
+	gh.commentLimit = 1
+
+	event := &events.IssueCommentEvent{
+		Action: github.String("created"),
+		Issue:  &github.Issue{Number: github.Int(1), HTMLURL: gh.issue.HTMLURL},
+		Comment: &github.IssueComment{
+			Body:    github.String("!fixed 2\n!help"),
+			HTMLURL: github.String("https://command-url"),
+			User:    &github.User{Login: github.String("author")},
+		},
+		Repo: &github.Repository{
+			Name:  github.String("repo"),
+			Owner: &github.User{Login: github.String("owner")},
+		},
+	}
+
+	rw := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", "/events", nil)
+	gh.newEventHandler().HandleIssueCommentEvent(rw, r, event)
+
+	// The delivery must not fail, the retry would resolve the blocker again.
+	if rw.Code != http.StatusAccepted {
+		t.Errorf("expected status %v, got %v", http.StatusAccepted, rw.Code)
+	}
+	if len(gh.comments) != 1 {
+		t.Errorf("unexpected comments: %q", gh.comments)
+	}
+}
@@ -0,0 +1,234 @@
+package endpoint
+
+import (
+	// Stdlib
+	"bytes"
+	"fmt"
+	"net/http"
+	"strings"
+
+	// Internal
+	"github.com/salsaflow/salsaflow-daemon/internal/commands"
+	githubutil "github.com/salsaflow/salsaflow-daemon/internal/github"
+	"github.com/salsaflow/salsaflow-daemon/internal/log"
+	"github.com/salsaflow/salsaflow-daemon/internal/metrics"
+	"github.com/salsaflow/salsaflow-daemon/internal/modules/codereview/github/config"
+
+	// Vendor
+	"github.com/google/go-github/github"
+	"github.com/salsaflow/salsaflow/github/issues"
+)
+
+// matchesCommitSHA returns true when the two commit SHAs match,
+// one of them possibly being abbreviated.
+func matchesCommitSHA(a, b string) bool {
+	return strings.HasPrefix(a, b) || strings.HasPrefix(b, a)
+}
+
+// markCommitsReviewed ticks off the given commits in the review issue commit checklist.
+// It returns the commit items that were not reviewed before.
+func markCommitsReviewed(reviewIssue issues.ReviewIssue, shas []string) []*issues.CommitItem {
+	var marked []*issues.CommitItem
+	for _, item := range reviewIssue.CommitItems() {
+		if item.Reviewed {
+			continue
+		}
+		for _, sha := range shas {
+			if matchesCommitSHA(item.CommitSHA, sha) {
+				item.Reviewed = true
+				marked = append(marked, item)
+				break
+			}
+		}
+	}
+	return marked
+}
+
+// reviewComplete returns true when all commits in the review issue are reviewed
+// and all review blockers are fixed.
+func reviewComplete(reviewIssue issues.ReviewIssue) bool {
+	for _, item := range reviewIssue.CommitItems() {
+		if !item.Reviewed {
+			return false
+		}
+	}
+	for _, item := range reviewIssue.ReviewBlockerItems() {
+		if !item.Fixed {
+			return false
+		}
+	}
+	return true
+}
+
+// markReviewedForCommit finds the review issue containing the given commit
+// and it marks the commits passed to the given !reviewed command as reviewed.
+// In case no commit SHA is passed, the commit being commented is marked as reviewed.
+func (handler *eventHandler) markReviewedForCommit(
+	r *http.Request,
+	owner string,
+	repo string,
+	commitSHA string,
+	commentURL string,
+	commentAuthor string,
+	inv *commands.Invocation,
+) error {
+
+	// Use the commented commit unless told otherwise.
+	shas := inv.Args
+	if len(shas) == 0 {
+		shas = []string{commitSHA}
+	}
+
+	client, cfg, err := handler.clientForRepo(r, owner, repo)
+	if err != nil {
+		return err
+	}
+
+	// Find the right review issue.
+	issue, err := findReviewIssueByCommitItem(client, owner, repo, cfg.ReviewIssueLabel, commitSHA)
+	if err != nil {
+		return err
+	}
+	if issue == nil {
+		metrics.Skip(r)
+		log.Info(r, "No review issue found for commit %v in %v/%v, skipping", commitSHA, owner, repo)
+		return nil
+	}
+
+	return markReviewed(r, client, cfg, owner, repo, issue, commentURL, commentAuthor, shas)
+}
+
+// markReviewed marks the given commits as reviewed in the given review issue
+// and it posts an acknowledgement into the review issue.
+func markReviewed(
+	r *http.Request,
+	client *github.Client,
+	cfg config.Config,
+	owner string,
+	repo string,
+	issue *github.Issue,
+	commentURL string,
+	commentAuthor string,
+	shas []string,
+) error {
+
+	// Lock the review issue, the body is going to be rewritten.
+	issue, unlock, err := lockReviewIssue(client, owner, repo, issue)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	// Parse issue body.
+	reviewIssue, err := issues.ParseReviewIssue(issue)
+	if err != nil {
+		return err
+	}
+
+	// Tick the commits off.
+	issueNum := *issue.Number
+	marked := markCommitsReviewed(reviewIssue, shas)
+	if len(marked) == 0 {
+		metrics.Skip(r)
+		log.Info(r, "No commits to be marked as reviewed in review issue %v/%v#%v, skipping",
+			owner, repo, issueNum)
+		return nil
+	}
+
+	// Complete the review in case this was the last step.
+	complete := reviewComplete(reviewIssue)
+	if complete {
+		if err := completeReview(r, client, cfg, owner, repo, issue); err != nil {
+			return err
+		}
+	}
+
+	// Update the review issue.
+	_, _, err = client.Issues.Edit(owner, repo, issueNum, &github.IssueRequest{
+		Body: github.String(reviewIssue.FormatBody()),
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Info(r, "%v commit(s) in review issue %v/%v#%v marked as reviewed",
+		len(marked), owner, repo, issueNum)
+
+	// Post the acknowledgement.
+	var bodyBuffer bytes.Buffer
+	fmt.Fprintf(&bodyBuffer, "The following commits were [marked as reviewed](%v) by @%v:\n",
+		commentURL, commentAuthor)
+	for _, item := range marked {
+		fmt.Fprintf(&bodyBuffer, "- %v: %v\n", item.CommitSHA, item.CommitTitle)
+	}
+	if complete {
+		fmt.Fprint(&bodyBuffer, "\n")
+		fmt.Fprint(&bodyBuffer, reviewCompleteMessage(cfg))
+	}
+
+	_, _, err = client.Issues.CreateComment(owner, repo, issueNum, &github.IssueComment{
+		Body: github.String(bodyBuffer.String()),
+	})
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// completeReview is to be called once all commits are reviewed
+// and all review blockers are fixed. It labels the review issue as implemented.
+//
+// The review issue is then closed by the edited event completing the review,
+// see closeWhenComplete, and the closed event marks the story as reviewed.
+func completeReview(
+	r *http.Request,
+	client *github.Client,
+	cfg config.Config,
+	owner string,
+	repo string,
+	issue *github.Issue,
+) error {
+
+	issueNum := *issue.Number
+
+	// Label the review issue as implemented.
+	if !githubutil.LabeledWith(issue, cfg.StoryImplementedLabel) {
+		log.Info(r, "Labeling review issue %v/%v#%v with %v",
+			owner, repo, issueNum, cfg.StoryImplementedLabel)
+		_, _, err := client.Issues.AddLabelsToIssue(
+			owner, repo, issueNum, []string{cfg.StoryImplementedLabel})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// closeReviewIssue closes the given review issue. The closed event
+// sent by GitHub afterwards then runs the usual closed review issue flow,
+// which marks the story as reviewed.
+func closeReviewIssue(
+	r *http.Request,
+	client *github.Client,
+	owner string,
+	repo string,
+	issueNum int,
+) error {
+
+	log.Info(r, "Review issue %v/%v#%v complete, closing", owner, repo, issueNum)
+	_, _, err := client.Issues.Edit(owner, repo, issueNum, &github.IssueRequest{
+		State: github.String("closed"),
+	})
+	return err
+}
+
+// reviewCompleteMessage returns the message to be appended
+// to the acknowledgement once the review is complete.
+func reviewCompleteMessage(cfg config.Config) string {
+	return fmt.Sprintf(
+		"All commits are reviewed and all review blockers are fixed, "+
+			"the review issue was labeled with `%v` and it is going to be closed.\n",
+		cfg.StoryImplementedLabel)
+}
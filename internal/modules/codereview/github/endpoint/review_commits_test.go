@@ -0,0 +1,207 @@
+package endpoint
+
+import (
+	// Stdlib
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	// Internal
+	"github.com/salsaflow/salsaflow-daemon/internal/github/events"
+	"github.com/salsaflow/salsaflow-daemon/internal/modules/codereview/github/config"
+
+	// Vendor
+	"github.com/google/go-github/github"
+	"github.com/salsaflow/salsaflow/github/issues"
+)
+
+func TestReviewedCommand(t *testing.T) {
+	data := []struct {
+		body     string
+		expected []string
+		valid    bool
+	}{
+		{"!reviewed", nil, false},
+		{"!reviewed 0123456", []string{"0123456"}, true},
+		{"!reviewed 0123456 89abcdef", []string{"0123456", "89abcdef"}, true},
+		{"!reviewed 0123456 HEAD", nil, false},
+	}
+
+	for _, d := range data {
+		invs, errs := reviewIssueCommentCommands.Parse(d.body)
+		if d.valid != (len(errs) == 0) {
+			t.Errorf("%v: unexpected errors: %v", d.body, errs)
+			continue
+		}
+		if d.valid && !reflect.DeepEqual(invs[0].Args, d.expected) {
+			t.Errorf("%v: expected %v, got %v", d.body, d.expected, invs[0].Args)
+		}
+	}
+}
+
+func TestReviewedCommand_CommitComment(t *testing.T) {
+	// The commented commit is used by default in commit comments.
+	invs, errs := commitCommentCommands.Parse("!reviewed")
+	if len(errs) != 0 || len(invs) != 1 || len(invs[0].Args) != 0 {
+		t.Errorf("unexpected result: %v, %v", invs, errs)
+	}
+}
+
+func newTestingMultiCommitReviewIssue() *issues.CommitReviewIssue {
+	reviewIssue := issues.NewCommitReviewIssue("0123456", "Do the thing")
+	reviewIssue.AddCommit(false, "89abcde", "Do the other thing")
+	return reviewIssue
+}
+
+func TestMarkCommitsReviewed(t *testing.T) {
+	data := []struct {
+		shas     []string
+		expected []string
+	}{
+		{nil, nil},
+		{[]string{"0123456", "89abcde"}, []string{"0123456", "89abcde"}},
+		{[]string{"89abcdef0123"}, []string{"89abcde"}},
+		{[]string{"fedcba9"}, nil},
+	}
+
+	for _, d := range data {
+		var marked []string
+		for _, item := range markCommitsReviewed(newTestingMultiCommitReviewIssue(), d.shas) {
+			marked = append(marked, item.CommitSHA)
+		}
+		if !reflect.DeepEqual(marked, d.expected) {
+			t.Errorf("%v: expected %v, got %v", d.shas, d.expected, marked)
+		}
+	}
+}
+
+func TestReviewComplete(t *testing.T) {
+	reviewIssue := newTestingMultiCommitReviewIssue()
+	reviewIssue.AddReviewBlocker(false, "https://comment-url/1", "0123456", "Fix the typo")
+
+	markCommitsReviewed(reviewIssue, []string{"0123456", "89abcde"})
+	if reviewComplete(reviewIssue) {
+		t.Error("review complete while a review blocker is open")
+	}
+
+	reviewIssue.ReviewBlockerItems()[0].Fixed = true
+	if !reviewComplete(reviewIssue) {
+		t.Error("review not complete while everything is done")
+	}
+}
+
+func TestMarkReviewed(t *testing.T) {
+	data := []struct {
+		shas     []string
+		complete bool
+	}{
+		{[]string{"0123456"}, false},
+		{[]string{"0123456", "89abcde"}, true},
+	}
+
+	for _, d := range data {
+		gh := newTestingGitHub("code review")
+
+		reviewIssue := newTestingMultiCommitReviewIssue()
+		gh.issue.Title = github.String(reviewIssue.FormatTitle())
+		gh.issue.Body = github.String(reviewIssue.FormatBody())
+
+		cfg := config.Config{ReviewIssueLabel: "code review", StoryImplementedLabel: "done"}
+
+		r := httptest.NewRequest("POST", "/events", nil)
+		err := markReviewed(
+			r, gh.newClient(), cfg, "owner", "repo", gh.issue, "https://command-url", "author", d.shas)
+		gh.Close()
+		if err != nil {
+			t.Errorf("%v: %v", d.shas, err)
+			continue
+		}
+
+		updated, err := issues.ParseReviewIssue(gh.issue)
+		if err != nil {
+			t.Errorf("%v: %v", d.shas, err)
+			continue
+		}
+		if reviewComplete(updated) != d.complete {
+			t.Errorf("%v: unexpected review issue body:\n%v", d.shas, *gh.issue.Body)
+		}
+
+		if d.complete {
+			if !reflect.DeepEqual(gh.labels, []string{"done"}) {
+				t.Errorf("%v: unexpected labels added: %v", d.shas, gh.labels)
+			}
+			if len(gh.comments) != 1 || !strings.Contains(gh.comments[0], "labeled with `done`") {
+				t.Errorf("%v: unexpected comments: %q", d.shas, gh.comments)
+			}
+		} else {
+			if len(gh.labels) != 0 {
+				t.Errorf("%v: unexpected labels added: %v", d.shas, gh.labels)
+			}
+			if len(gh.comments) != 1 || strings.Contains(gh.comments[0], "labeled with") {
+				t.Errorf("%v: unexpected comments: %q", d.shas, gh.comments)
+			}
+		}
+
+		// The review issue is closed by the edited event that follows.
+		if gh.issue.State != nil {
+			t.Errorf("%v: unexpected review issue state: %v", d.shas, *gh.issue.State)
+		}
+	}
+}
+
+func TestHandleIssuesEvent_Complete(t *testing.T) {
+	incomplete := newTestingMultiCommitReviewIssue()
+	complete := newTestingMultiCommitReviewIssue()
+	markCommitsReviewed(complete, []string{"0123456", "89abcde"})
+
+	data := []struct {
+		desc   string
+		action string
+		label  string
+		labels []string
+		before *issues.CommitReviewIssue
+		after  *issues.CommitReviewIssue
+		closed bool
+	}{
+		{"completing edit", "edited", "", []string{"code review", "done"}, incomplete, complete, true},
+		{"edit of complete review", "edited", "", []string{"code review", "done"}, complete, complete, false},
+		{"incomplete edit", "edited", "", []string{"code review", "done"}, incomplete, incomplete, false},
+		{"completing edit without label", "edited", "", []string{"code review"}, incomplete, complete, false},
+		{"completing label", "labeled", "done", []string{"code review", "done"}, nil, complete, true},
+		{"other label", "labeled", "bug", []string{"code review", "done", "bug"}, nil, complete, false},
+		{"label of incomplete review", "labeled", "done", []string{"code review", "done"}, nil, incomplete, false},
+	}
+
+	for _, d := range data {
+		gh := newTestingGitHub(d.labels...)
+		gh.issue.Title = github.String(d.after.FormatTitle())
+		gh.issue.Body = github.String(d.after.FormatBody())
+		gh.issue.State = github.String("open")
+
+		event := newIssuesEvent("edited", d.action)
+		event.Issue.Title = gh.issue.Title
+		event.Issue.Body = gh.issue.Body
+		if d.label != "" {
+			event.Label = &github.Label{Name: github.String(d.label)}
+		}
+		if d.before != nil {
+			event.Changes = &events.IssueChanges{
+				Body: &events.Change{From: github.String(d.before.FormatBody())},
+			}
+		}
+
+		rw := httptest.NewRecorder()
+		r := httptest.NewRequest("POST", "/events", nil)
+		gh.newEventHandler().HandleIssuesEvent(rw, r, event)
+		gh.Close()
+
+		if rw.Code != http.StatusAccepted {
+			t.Errorf("%v: expected status %v, got %v", d.desc, http.StatusAccepted, rw.Code)
+		}
+		if closed := *gh.issue.State == "closed"; closed != d.closed {
+			t.Errorf("%v: expected closed to be %v, got %v", d.desc, d.closed, closed)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package endpoint
+
+import (
+	// Stdlib
+	"fmt"
+	"regexp"
+
+	// Vendor
+	"github.com/google/go-github/github"
+)
+
+// findReviewIssueByCommitItem searches review issues for the one that
+// contains the given commit in its commit checklist.
+//
+// This is issues.FindReviewIssueByCommitItem except that the review issue
+// label is not hardcoded, it is passed in as an argument.
+func findReviewIssueByCommitItem(
+	client *github.Client,
+	owner string,
+	repo string,
+	reviewIssueLabel string,
+	commitSHA string,
+) (*github.Issue, error) {
+
+	// Use the first 7 chars from the commit hexsha.
+	shortSHA := commitSHA
+	if len(shortSHA) > 7 {
+		shortSHA = shortSHA[:7]
+	}
+
+	// Commit item regexp.
+	re := regexp.MustCompile(fmt.Sprintf(`[-*] \[[xX ]\] %v[:]`, shortSHA))
+
+	// Perform the search.
+	query := fmt.Sprintf(
+		`"%v" repo:"%v/%v" label:"%v" type:issue state:open state:closed in:body`,
+		shortSHA, owner, repo, reviewIssueLabel)
+
+	searchOpts := &github.SearchOptions{}
+	searchOpts.Page = 1
+	searchOpts.PerPage = 50
+
+	var searched int
+	for {
+		// Fetch another page.
+		result, _, err := client.Search.Issues(query, searchOpts)
+		if err != nil {
+			return nil, err
+		}
+
+		// Check the issues for the commit item.
+		for _, issue := range result.Issues {
+			if issue.Body != nil && re.MatchString(*issue.Body) {
+				return &issue, nil
+			}
+		}
+
+		// Check whether we have reached the end or not.
+		searched += len(result.Issues)
+		if len(result.Issues) == 0 || result.Total == nil || searched >= *result.Total {
+			return nil, nil
+		}
+
+		// Check the next page in the next iteration.
+		searchOpts.Page += 1
+	}
+}
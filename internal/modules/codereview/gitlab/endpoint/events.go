@@ -0,0 +1,37 @@
+package endpoint
+
+import (
+	// Stdlib
+	"errors"
+
+	// Internal
+	"github.com/salsaflow/salsaflow-daemon/internal/gitlab"
+	"github.com/salsaflow/salsaflow-daemon/internal/gitlab/events"
+)
+
+type eventHandler struct {
+	newClient func() (*gitlab.Client, error)
+}
+
+func init() {
+	// Panic in case eventHandler is not implement the right interfaces.
+	// Optimally the compiler would check this, but that is not possible here,
+	// so we at least panic at program startup when this is not correct.
+	if err := ensureInterfaces(); err != nil {
+		panic(err)
+	}
+}
+
+func ensureInterfaces() error {
+	var handler interface{} = &eventHandler{}
+
+	if _, ok := handler.(events.MergeRequestEventHandler); !ok {
+		return errors.New("eventHandler does not implement events.MergeRequestEventHandler")
+	}
+
+	if _, ok := handler.(events.NoteEventHandler); !ok {
+		return errors.New("eventHandler does not implement events.NoteEventHandler")
+	}
+
+	return nil
+}
@@ -0,0 +1,90 @@
+package endpoint
+
+import (
+	// Stdlib
+	"net/http"
+	"strconv"
+
+	// Internal
+	"github.com/salsaflow/salsaflow-daemon/internal/gitlab/events"
+	httputil "github.com/salsaflow/salsaflow-daemon/internal/http"
+	"github.com/salsaflow/salsaflow-daemon/internal/log"
+	"github.com/salsaflow/salsaflow-daemon/internal/metrics"
+	"github.com/salsaflow/salsaflow-daemon/internal/modules"
+	"github.com/salsaflow/salsaflow-daemon/internal/modules/common"
+)
+
+// HandleMergeRequestEvent implements events.MergeRequestEventHandler
+// and it is used to handle GitLab merge request hooks.
+func (handler *eventHandler) HandleMergeRequestEvent(
+	rw http.ResponseWriter,
+	r *http.Request,
+	event *events.MergeRequestEvent,
+) {
+	mr := event.ObjectAttributes
+	if mr == nil {
+		httputil.Status(rw, http.StatusBadRequest)
+		return
+	}
+
+	// Do nothing unless this is an open, close, merge or reopen event.
+	switch mr.Action {
+	case "open":
+	case "close":
+	case "merge":
+	case "reopen":
+	default:
+		httputil.Status(rw, http.StatusAccepted)
+		return
+	}
+
+	// Make sure the merge request references a story.
+	trackerName, storyKey, ok := common.ParseStoryTags(mr.Description)
+	if !ok {
+		metrics.Skip(r)
+		log.Info(r, "Merge request %v does not reference any story, skipping", mr.URL)
+		httputil.Status(rw, http.StatusAccepted)
+		return
+	}
+
+	// Find relevant story.
+	log.AddField(r, log.FieldStoryTag, storyKey)
+	story, err := modules.FindStory(trackerName, storyKey)
+	if err != nil {
+		log.Error(r, err)
+		httputil.Status(rw, httputil.StatusUnprocessableEntity)
+		return
+	}
+
+	// Invoke relevant event handler.
+	var (
+		mrIidString = strconv.Itoa(mr.Iid)
+		mrURL       = mr.URL
+		ex          error
+	)
+	switch mr.Action {
+	case "open":
+		ex = story.OnReviewRequestOpened(mrIidString, mrURL)
+	case "close", "merge":
+		ex = story.OnReviewRequestClosed(mrIidString, mrURL)
+	case "reopen":
+		ex = story.OnReviewRequestReopened(mrIidString, mrURL)
+	default:
+		panic("unreachable code reached")
+	}
+	if ex != nil {
+		httputil.Error(rw, r, ex)
+		return
+	}
+
+	// A merged merge request means that the story has been reviewed.
+	if mr.Action == "merge" {
+		log.Info(r, "Merge request %v merged, marking story %v as reviewed", mrURL, storyKey)
+		if err := story.MarkAsReviewed(); err != nil {
+			httputil.Error(rw, r, err)
+			return
+		}
+	}
+
+	httputil.Status(rw, http.StatusAccepted)
+}
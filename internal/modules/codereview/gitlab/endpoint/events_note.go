@@ -0,0 +1,250 @@
+package endpoint
+
+import (
+	// Stdlib
+	"bytes"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	// Internal
+	"github.com/salsaflow/salsaflow-daemon/internal/commands"
+	"github.com/salsaflow/salsaflow-daemon/internal/gitlab"
+	"github.com/salsaflow/salsaflow-daemon/internal/gitlab/events"
+	httputil "github.com/salsaflow/salsaflow-daemon/internal/http"
+	"github.com/salsaflow/salsaflow-daemon/internal/log"
+	"github.com/salsaflow/salsaflow-daemon/internal/metrics"
+	"github.com/salsaflow/salsaflow-daemon/internal/modules"
+	"github.com/salsaflow/salsaflow-daemon/internal/modules/common"
+)
+
+// HandleNoteEvent implements events.NoteEventHandler
+// and it is used to handle GitLab note hooks.
+//
+// Notes on merge requests and commits are scanned for !mustfix commands,
+// which are turned into review blockers of the relevant merge requests.
+// !help and malformed commands are answered by a reply next to the note.
+func (handler *eventHandler) HandleNoteEvent(
+	rw http.ResponseWriter,
+	r *http.Request,
+	event *events.NoteEvent,
+) {
+	note := event.ObjectAttributes
+	if note == nil || event.Project == nil || event.User == nil {
+		httputil.Status(rw, http.StatusBadRequest)
+		return
+	}
+
+	// Only merge request and commit notes are interesting.
+	switch note.NoteableType {
+	case "MergeRequest":
+	case "Commit":
+	default:
+		httputil.Status(rw, http.StatusAccepted)
+		return
+	}
+
+	// Collect the review blockers.
+	invs, errs := noteCommands.Parse(note.Note)
+	var blockerSummaries []string
+	for _, inv := range invs {
+		if inv.Command == common.MustfixCommand {
+			blockerSummaries = append(blockerSummaries, inv.Arg(0))
+		}
+	}
+
+	// Reply in case there is !help or a malformed command.
+	reply := noteCommands.Reply(event.User.Username, invs, errs)
+	if len(blockerSummaries) == 0 && reply == "" {
+		httputil.Status(rw, http.StatusAccepted)
+		return
+	}
+
+	// Get the API client.
+	client, err := handler.newClient()
+	if err != nil {
+		httputil.Error(rw, r, err)
+		return
+	}
+
+	projectId := strconv.Itoa(event.Project.Id)
+	if len(blockerSummaries) == 0 {
+		if err := replyToNote(r, client, projectId, event, reply); err != nil {
+			httputil.Error(rw, r, err)
+			return
+		}
+		httputil.Status(rw, http.StatusAccepted)
+		return
+	}
+
+	// Find the merge requests the blockers belong to.
+
+	var mrIids []int
+	switch note.NoteableType {
+	case "MergeRequest":
+		if event.MergeRequest == nil {
+			httputil.Status(rw, http.StatusBadRequest)
+			return
+		}
+		mrIids = []int{event.MergeRequest.Iid}
+
+	case "Commit":
+		mrs, _, err := client.MergeRequests.ListByCommit(projectId, note.CommitId)
+		if err != nil {
+			httputil.Error(rw, r, err)
+			return
+		}
+		for _, mr := range mrs {
+			if mr.State == "opened" {
+				mrIids = append(mrIids, mr.Iid)
+			}
+		}
+		if len(mrIids) == 0 {
+			metrics.Skip(r)
+			log.Info(r, "No open merge request found for commit %v in %v, skipping",
+				note.CommitId, event.Project.PathWithNamespace)
+			if reply != "" {
+				if err := replyToNote(r, client, projectId, event, reply); err != nil {
+					httputil.Error(rw, r, err)
+					return
+				}
+			}
+			httputil.Status(rw, http.StatusAccepted)
+			return
+		}
+	}
+
+	// Add the blockers.
+	for _, mrIid := range mrIids {
+		err := createReviewBlockers(
+			r, client, projectId, mrIid, note.URL, event.User.Username, blockerSummaries)
+		if err != nil {
+			httputil.Error(rw, r, err)
+			return
+		}
+	}
+
+	// Reply once the valid commands are processed.
+	if reply != "" {
+		if err := replyToNote(r, client, projectId, event, reply); err != nil {
+			// The commands are processed already, do not make the delivery
+			// fail since the retry would run them again.
+			log.Error(r, err)
+		}
+	}
+
+	httputil.Status(rw, http.StatusAccepted)
+}
+
+// noteCommands are the commands available in merge request and commit notes.
+var noteCommands = commands.NewRegistry(common.MustfixCommand)
+
+// replyToNote posts the given reply next to the note, i.e. into the merge request
+// or as a commit comment, depending on what was commented.
+func replyToNote(
+	r *http.Request,
+	client *gitlab.Client,
+	projectId string,
+	event *events.NoteEvent,
+	reply string,
+) error {
+
+	note := event.ObjectAttributes
+	log.Info(r, "Replying to note %v", note.URL)
+
+	switch note.NoteableType {
+	case "MergeRequest":
+		if event.MergeRequest == nil {
+			return nil
+		}
+		_, _, err := client.MergeRequests.CreateNote(projectId, event.MergeRequest.Iid, &gitlab.Note{
+			Body: reply,
+		})
+		return err
+
+	case "Commit":
+		_, err := client.Commits.CreateComment(projectId, note.CommitId, reply)
+		return err
+
+	default:
+		return nil
+	}
+}
+
+// createReviewBlockers adds new review blockers into the merge request description
+// and it notifies the merge request subscribers by posting a note.
+func createReviewBlockers(
+	r *http.Request,
+	client *gitlab.Client,
+	projectId string,
+	mrIid int,
+	noteURL string,
+	noteAuthor string,
+	blockerSummaries []string,
+) error {
+
+	// Get the current merge request description.
+	mr, _, err := client.MergeRequests.Get(projectId, mrIid)
+	if err != nil {
+		return err
+	}
+
+	// Add the blockers that are not there yet.
+	var (
+		description = mr.Description
+		bodyBuffer  bytes.Buffer
+		opened      []string
+	)
+	for _, summary := range blockerSummaries {
+		var (
+			number int
+			added  bool
+		)
+		description, number, added = addReviewBlocker(description, noteURL, summary)
+		if !added {
+			log.Info(r, "Review blocker %v already listed in merge request %v", number, mr.WebURL)
+			continue
+		}
+
+		opened = append(opened, summary)
+
+		if bodyBuffer.Len() != 0 {
+			bodyBuffer.WriteString("\n")
+		}
+		fmt.Fprintf(&bodyBuffer,
+			"A new [review blocker](%v) was opened by @%v for merge request !%v. The summary follows:\n",
+			noteURL, noteAuthor, mrIid)
+		fmt.Fprintf(&bodyBuffer, "> %v\n", summary)
+	}
+	if bodyBuffer.Len() == 0 {
+		return nil
+	}
+
+	// Update the merge request.
+	if _, _, err := client.MergeRequests.UpdateDescription(projectId, mrIid, description); err != nil {
+		return err
+	}
+
+	log.Info(r, "Linked a new review note to merge request %v", mr.WebURL)
+
+	// Add the blocker note.
+	_, _, err = client.MergeRequests.CreateNote(projectId, mrIid, &gitlab.Note{
+		Body: bodyBuffer.String(),
+	})
+	if err != nil {
+		return err
+	}
+
+	// Let the story know in case the merge request references one.
+	if trackerName, storyKey, ok := common.ParseStoryTags(mr.Description); ok {
+		modules.NotifyStory(r, trackerName, storyKey, func(story common.Story) error {
+			for _, summary := range opened {
+				if err := story.OnReviewBlockerOpened(noteURL, summary); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	}
+	return nil
+}
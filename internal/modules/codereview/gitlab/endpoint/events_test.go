@@ -0,0 +1,59 @@
+package endpoint
+
+import (
+	// Stdlib
+	"testing"
+)
+
+func Test_eventHandler_interfaces(t *testing.T) {
+	if err := ensureInterfaces(); err != nil {
+		t.Error(err)
+	}
+}
+
+func Test_addReviewBlocker(t *testing.T) {
+	const url = "https://gitlab.example.com/group/project/merge_requests/1#note_10"
+
+	data := []struct {
+		description    string
+		newDescription string
+		number         int
+		added          bool
+	}{
+		{
+			"",
+			"#### Review blockers\n\n- [ ] [blocker 1](" + url + "): Fix it\n",
+			1, true,
+		},
+		{
+			"Implement the thing.\n\nSF-Story-Key: 123\n",
+			"Implement the thing.\n\nSF-Story-Key: 123\n\n#### Review blockers\n\n" +
+				"- [ ] [blocker 1](" + url + "): Fix it\n",
+			1, true,
+		},
+		{
+			"Text\n\n#### Review blockers\n\n- [x] [blocker 1](https://x/1): Done\n\nFooter\n",
+			"Text\n\n#### Review blockers\n\n- [x] [blocker 1](https://x/1): Done\n" +
+				"- [ ] [blocker 2](" + url + "): Fix it\n\nFooter\n",
+			2, true,
+		},
+		{
+			"#### Review blockers\n",
+			"#### Review blockers\n\n- [ ] [blocker 1](" + url + "): Fix it\n",
+			1, true,
+		},
+		{
+			"#### Review blockers\n\n- [ ] [blocker 1](https://x/1): A\n- [ ] [blocker 2](" + url + "): Fix it\n",
+			"#### Review blockers\n\n- [ ] [blocker 1](https://x/1): A\n- [ ] [blocker 2](" + url + "): Fix it\n",
+			2, false,
+		},
+	}
+
+	for _, d := range data {
+		newDescription, number, added := addReviewBlocker(d.description, url, "Fix it")
+		if newDescription != d.newDescription || number != d.number || added != d.added {
+			t.Errorf("addReviewBlocker(%q): expected (%q, %v, %v), got (%q, %v, %v)",
+				d.description, d.newDescription, d.number, d.added, newDescription, number, added)
+		}
+	}
+}
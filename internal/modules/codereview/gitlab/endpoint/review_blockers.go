@@ -0,0 +1,91 @@
+package endpoint
+
+import (
+	// Stdlib
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// GitLab has no review issues, so the review blockers are kept
+// as a checklist at the end of the merge request description.
+const reviewBlockersHeading = "#### Review blockers"
+
+var reviewBlockerRegexp = regexp.MustCompile(`^- \[[ xX]\] \[blocker ([0-9]+)\]\((.+?)\): (.*)$`)
+
+// addReviewBlocker inserts a new unchecked review blocker item into the review
+// blocker checklist contained in the given merge request description.
+// The checklist is appended to the description when missing.
+//
+// The new description is returned together with the blocker number.
+// added is false when the same blocker is already listed, in which case
+// the description is returned unchanged.
+func addReviewBlocker(
+	description string,
+	noteURL string,
+	summary string,
+) (newDescription string, number int, added bool) {
+
+	lines := strings.Split(description, "\n")
+
+	// Find the checklist heading.
+	headingIndex := -1
+	for i, line := range lines {
+		if strings.TrimSpace(line) == reviewBlockersHeading {
+			headingIndex = i
+			break
+		}
+	}
+
+	// Append a new checklist in case there is none.
+	if headingIndex == -1 {
+		item := formatReviewBlocker(1, noteURL, summary)
+		content := strings.TrimRight(description, " \t\r\n")
+		if content == "" {
+			return reviewBlockersHeading + "\n\n" + item + "\n", 1, true
+		}
+		return content + "\n\n" + reviewBlockersHeading + "\n\n" + item + "\n", 1, true
+	}
+
+	// Go through the checklist items following the heading.
+	lastItemIndex := -1
+	for i := headingIndex + 1; i < len(lines); i++ {
+		line := strings.TrimRight(lines[i], "\r")
+		if line == "" && lastItemIndex == -1 {
+			continue
+		}
+		match := reviewBlockerRegexp.FindStringSubmatch(line)
+		if len(match) == 0 {
+			break
+		}
+		if match[2] == noteURL && match[3] == summary {
+			n, _ := strconv.Atoi(match[1])
+			return description, n, false
+		}
+		lastItemIndex = i
+		number++
+	}
+	number++
+
+	// Insert the new item after the last one, or after the heading.
+	item := formatReviewBlocker(number, noteURL, summary)
+	var insert []string
+	insertIndex := lastItemIndex + 1
+	if lastItemIndex == -1 {
+		insert = []string{"", item}
+		insertIndex = headingIndex + 1
+	} else {
+		insert = []string{item}
+	}
+
+	newLines := make([]string, 0, len(lines)+len(insert))
+	newLines = append(newLines, lines[:insertIndex]...)
+	newLines = append(newLines, insert...)
+	newLines = append(newLines, lines[insertIndex:]...)
+	return strings.Join(newLines, "\n"), number, true
+}
+
+func formatReviewBlocker(number int, noteURL, summary string) string {
+	return fmt.Sprintf("- [ ] [blocker %v](%v): %v", number, noteURL, summary)
+}
@@ -0,0 +1,31 @@
+package config
+
+import (
+	// Stdlib
+	"log"
+
+	// Vendor
+	"github.com/kelseyhightower/envconfig"
+)
+
+type Config struct {
+	// WebhookSecret is the secret configured for the Review Board WebHook.
+	// Review Board signs the payload with it using X-Hub-Signature.
+	WebhookSecret string `envconfig:"WEBHOOK_SECRET"`
+
+	// IssueTracker is the issue tracker module ID used for the story IDs
+	// listed in the Bugs field of review requests that contain no story tags.
+	IssueTracker string `envconfig:"ISSUE_TRACKER"`
+}
+
+var config Config
+
+func init() {
+	if err := envconfig.Process("SFD_REVIEWBOARD", &config); err != nil {
+		log.Fatalln("Fatal error while parsing Review Board config:", err)
+	}
+}
+
+func Get() Config {
+	return config
+}
@@ -0,0 +1,80 @@
+package endpoint
+
+import (
+	// Stdlib
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/hex"
+	"io/ioutil"
+	stdLog "log"
+	"net/http"
+
+	// Internal
+	httputil "github.com/salsaflow/salsaflow-daemon/internal/http"
+	"github.com/salsaflow/salsaflow-daemon/internal/log"
+	"github.com/salsaflow/salsaflow-daemon/internal/modules/codereview/reviewboard/config"
+
+	// Vendor
+	"github.com/codegangsta/negroni"
+)
+
+type Endpoint struct{}
+
+func NewEndpoint() *Endpoint {
+	return &Endpoint{}
+}
+
+func (ep *Endpoint) ModuleId() string {
+	return ModuleId
+}
+
+func (ep *Endpoint) NewAuthMiddleware() (negroni.Handler, error) {
+	secret := config.Get().WebhookSecret
+	if secret == "" {
+		stdLog.Println("WARNING: SFD_REVIEWBOARD_WEBHOOK_SECRET is not set")
+		return nil, nil
+	}
+	return newSecretMiddleware(secret), nil
+}
+
+func (ep *Endpoint) NewHandler() (http.Handler, error) {
+	// Create a new mux.
+	mux := http.NewServeMux()
+
+	// Handle /events
+	mux.HandleFunc("/events", handleEvent)
+
+	// Return the mux.
+	return mux, nil
+}
+
+func newSecretMiddleware(secret string) negroni.HandlerFunc {
+	return negroni.HandlerFunc(
+		func(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+			// Read the request body into a buffer.
+			bodyBytes, err := ioutil.ReadAll(r.Body)
+			if err != nil {
+				httputil.Error(rw, r, err)
+				return
+			}
+
+			// Fill the request body again so that it is available in the next handler.
+			r.Body.Close()
+			r.Body = ioutil.NopCloser(bytes.NewBuffer(bodyBytes))
+
+			// Compute the hash and compare with the header provided in the request.
+			mac := hmac.New(sha1.New, []byte(secret))
+			mac.Write(bodyBytes)
+			expected := "sha1=" + hex.EncodeToString(mac.Sum(nil))
+
+			if !hmac.Equal([]byte(r.Header.Get("X-Hub-Signature")), []byte(expected)) {
+				log.Warn(r, "Review Board webhook signature mismatch")
+				httputil.Status(rw, http.StatusUnauthorized)
+				return
+			}
+
+			// Call the next handler.
+			next(rw, r)
+		})
+}
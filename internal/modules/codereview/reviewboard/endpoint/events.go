@@ -0,0 +1,135 @@
+package endpoint
+
+import (
+	// Stdlib
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	// Internal
+	httputil "github.com/salsaflow/salsaflow-daemon/internal/http"
+	"github.com/salsaflow/salsaflow-daemon/internal/log"
+	"github.com/salsaflow/salsaflow-daemon/internal/metrics"
+	"github.com/salsaflow/salsaflow-daemon/internal/modules"
+	"github.com/salsaflow/salsaflow-daemon/internal/modules/codereview/reviewboard/config"
+	"github.com/salsaflow/salsaflow-daemon/internal/modules/common"
+)
+
+// Event represents a Review Board WebHook payload
+// using the default payload format.
+type Event struct {
+	Event         string         `json:"event"`
+	IsNew         bool           `json:"is_new"`
+	CloseType     string         `json:"close_type"`
+	ReviewRequest *ReviewRequest `json:"review_request"`
+	Review        *Review        `json:"review"`
+}
+
+type ReviewRequest struct {
+	Id          int      `json:"id"`
+	Summary     string   `json:"summary"`
+	Description string   `json:"description"`
+	BugsClosed  []string `json:"bugs_closed"`
+	AbsoluteURL string   `json:"absolute_url"`
+}
+
+type Review struct {
+	Id      int    `json:"id"`
+	ShipIt  bool   `json:"ship_it"`
+	BodyTop string `json:"body_top"`
+}
+
+func handleEvent(rw http.ResponseWriter, r *http.Request) {
+	// Decode the event object.
+	var event Event
+	if err := json.NewDecoder(r.Body).Decode(&event); err != nil {
+		httputil.Error(rw, r, err)
+		return
+	}
+
+	// Do nothing unless this is one of the events we care about.
+	// review_request_published is sent for every update of the review request,
+	// but we only want to handle the initial publication.
+	switch event.Event {
+	case "review_request_published":
+		if !event.IsNew {
+			httputil.Status(rw, http.StatusAccepted)
+			return
+		}
+	case "review_request_closed":
+	case "review_request_reopened":
+	case "review_published":
+		if event.Review == nil || !event.Review.ShipIt {
+			httputil.Status(rw, http.StatusAccepted)
+			return
+		}
+	default:
+		metrics.Skip(r)
+		httputil.Status(rw, http.StatusAccepted)
+		return
+	}
+
+	rr := event.ReviewRequest
+	if rr == nil {
+		httputil.Status(rw, http.StatusBadRequest)
+		return
+	}
+
+	// Make sure the review request references a story.
+	trackerName, storyKey, ok := reviewRequestStoryTags(rr, config.Get())
+	if !ok {
+		metrics.Skip(r)
+		log.Info(r, "Review request %v does not reference any story, skipping", rr.AbsoluteURL)
+		httputil.Status(rw, http.StatusAccepted)
+		return
+	}
+
+	// Find relevant story.
+	log.AddField(r, log.FieldStoryTag, storyKey)
+	story, err := modules.FindStory(trackerName, storyKey)
+	if err != nil {
+		log.Error(r, err)
+		httputil.Status(rw, httputil.StatusUnprocessableEntity)
+		return
+	}
+
+	// Invoke relevant event handler.
+	var (
+		rrIdString = strconv.Itoa(rr.Id)
+		rrURL      = rr.AbsoluteURL
+		ex         error
+	)
+	switch event.Event {
+	case "review_request_published":
+		ex = story.OnReviewRequestOpened(rrIdString, rrURL)
+	case "review_request_closed":
+		ex = story.OnReviewRequestClosed(rrIdString, rrURL)
+	case "review_request_reopened":
+		ex = story.OnReviewRequestReopened(rrIdString, rrURL)
+	case "review_published":
+		log.Info(r, "Review request %v got a Ship It!, marking story %v as reviewed", rrURL, storyKey)
+		ex = story.MarkAsReviewed()
+	default:
+		panic("unreachable code reached")
+	}
+	if ex != nil {
+		httputil.Error(rw, r, ex)
+		return
+	}
+
+	httputil.Status(rw, http.StatusAccepted)
+}
+
+// reviewRequestStoryTags returns the story tags found in the review request description.
+// In case there are none, the first bug listed in the review request
+// is used as the story key for the configured issue tracker.
+func reviewRequestStoryTags(rr *ReviewRequest, cfg config.Config) (trackerName, storyKey string, ok bool) {
+	if trackerName, storyKey, ok := common.ParseStoryTags(rr.Description); ok {
+		return trackerName, storyKey, true
+	}
+
+	if cfg.IssueTracker == "" || len(rr.BugsClosed) == 0 {
+		return "", "", false
+	}
+	return cfg.IssueTracker, rr.BugsClosed[0], true
+}
@@ -0,0 +1,59 @@
+package endpoint
+
+import (
+	// Stdlib
+	"testing"
+
+	// Internal
+	"github.com/salsaflow/salsaflow-daemon/internal/modules/codereview/reviewboard/config"
+)
+
+func Test_reviewRequestStoryTags(t *testing.T) {
+	data := []struct {
+		rr          *ReviewRequest
+		cfg         config.Config
+		trackerName string
+		storyKey    string
+		ok          bool
+	}{
+		{
+			&ReviewRequest{
+				Description: "Fix it.\n\nSF-Issue-Tracker: Pivotal Tracker\nSF-Story-Key: 123/stories/456\n",
+				BugsClosed:  []string{"789"},
+			},
+			config.Config{IssueTracker: "JIRA"},
+			"Pivotal Tracker", "123/stories/456", true,
+		},
+		{
+			&ReviewRequest{
+				Description: "Fix it.",
+				BugsClosed:  []string{"SF-42", "SF-43"},
+			},
+			config.Config{IssueTracker: "JIRA"},
+			"JIRA", "SF-42", true,
+		},
+		{
+			&ReviewRequest{
+				Description: "Fix it.",
+				BugsClosed:  []string{"SF-42"},
+			},
+			config.Config{},
+			"", "", false,
+		},
+		{
+			&ReviewRequest{
+				Description: "Fix it.",
+			},
+			config.Config{IssueTracker: "JIRA"},
+			"", "", false,
+		},
+	}
+
+	for _, d := range data {
+		trackerName, storyKey, ok := reviewRequestStoryTags(d.rr, d.cfg)
+		if trackerName != d.trackerName || storyKey != d.storyKey || ok != d.ok {
+			t.Errorf("reviewRequestStoryTags(%+v): expected (%q, %q, %v), got (%q, %q, %v)",
+				d.rr, d.trackerName, d.storyKey, d.ok, trackerName, storyKey, ok)
+		}
+	}
+}
@@ -0,0 +1,3 @@
+package endpoint
+
+const ModuleId = "salsaflow.modules.codereview.reviewboard"
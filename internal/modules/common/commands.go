@@ -0,0 +1,29 @@
+package common
+
+import (
+	// Internal
+	"github.com/salsaflow/salsaflow-daemon/internal/commands"
+)
+
+// The commands shared by the modules for different services.
+var (
+	// MustfixCommand opens a new review blocker.
+	MustfixCommand = &commands.Command{
+		Name:        "mustfix",
+		Usage:       "<summary>",
+		Description: "Open a new review blocker with the given summary.",
+		MinArgs:     1,
+		MaxArgs:     1,
+		Rest:        true,
+	}
+
+	// RejectCommand marks the story as rejected.
+	RejectCommand = &commands.Command{
+		Name:        "reject",
+		Usage:       "[reason]",
+		Description: "Mark the story as rejected.",
+		MinArgs:     0,
+		MaxArgs:     1,
+		Rest:        true,
+	}
+)
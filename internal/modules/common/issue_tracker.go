@@ -24,4 +24,11 @@ type Story interface {
 	// MarkAsReviewed can be used to mark the story as reviewed when
 	// that information cannot be deduced from other events.
 	MarkAsReviewed() error
+
+	// OnReviewBlockerOpened is called when a review blocker is raised
+	// for a review request associated with the story.
+	OnReviewBlockerOpened(blockerURL, summary string) error
+
+	// OnReviewBlockerFixed is called when a review blocker is resolved.
+	OnReviewBlockerFixed(blockerURL, summary string) error
 }
@@ -0,0 +1,36 @@
+package common
+
+import (
+	// Stdlib
+	"bufio"
+	"regexp"
+	"strings"
+
+	// Vendor
+	"github.com/salsaflow/salsaflow/github/issues"
+)
+
+var (
+	issueTrackerTagRegexp = regexp.MustCompile("^" + issues.TagIssueTracker + ":[ \t]*(.+)$")
+	storyKeyTagRegexp     = regexp.MustCompile("^" + issues.TagStoryKey + ":[ \t]*(.+)$")
+)
+
+// ParseStoryTags scans the given text for the SalsaFlow story tags,
+// i.e. the SF-Issue-Tracker and SF-Story-Key lines, and returns their values.
+//
+// This is how review requests that are not review issues, e.g. pull requests,
+// are linked to the relevant story. ok is false unless both tags are present.
+func ParseStoryTags(text string) (trackerName, storyKey string, ok bool) {
+	scanner := bufio.NewScanner(strings.NewReader(text))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if match := issueTrackerTagRegexp.FindStringSubmatch(line); len(match) == 2 {
+			trackerName = strings.TrimSpace(match[1])
+			continue
+		}
+		if match := storyKeyTagRegexp.FindStringSubmatch(line); len(match) == 2 {
+			storyKey = strings.TrimSpace(match[1])
+		}
+	}
+	return trackerName, storyKey, trackerName != "" && storyKey != ""
+}
@@ -0,0 +1,44 @@
+package common
+
+import (
+	// Stdlib
+	"testing"
+)
+
+func TestParseStoryTags(t *testing.T) {
+	data := []struct {
+		text        string
+		trackerName string
+		storyKey    string
+		ok          bool
+	}{
+		{
+			"",
+			"", "", false,
+		},
+		{
+			"Implement the thing.\n\nSF-Issue-Tracker: Pivotal Tracker\nSF-Story-Key: 123/stories/456\n",
+			"Pivotal Tracker", "123/stories/456", true,
+		},
+		{
+			"SF-Story-Key:   owner/repo#12\r\n  SF-Issue-Tracker: salsaflow.modules.issuetracking.github  \r\n",
+			"salsaflow.modules.issuetracking.github", "owner/repo#12", true,
+		},
+		{
+			"SF-Issue-Tracker: Pivotal Tracker\n",
+			"Pivotal Tracker", "", false,
+		},
+		{
+			"See SF-Story-Key: 123/stories/456 for more details.\n",
+			"", "", false,
+		},
+	}
+
+	for i, td := range data {
+		trackerName, storyKey, ok := ParseStoryTags(td.text)
+		if trackerName != td.trackerName || storyKey != td.storyKey || ok != td.ok {
+			t.Errorf("test case %v: expected (%q, %q, %v), got (%q, %q, %v)", i,
+				td.trackerName, td.storyKey, td.ok, trackerName, storyKey, ok)
+		}
+	}
+}
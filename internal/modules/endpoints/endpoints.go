@@ -6,18 +6,36 @@ import (
 
 	// Internal
 	ghReview "github.com/salsaflow/salsaflow-daemon/internal/modules/codereview/github/endpoint"
+	glReview "github.com/salsaflow/salsaflow-daemon/internal/modules/codereview/gitlab/endpoint"
+	rbReview "github.com/salsaflow/salsaflow-daemon/internal/modules/codereview/reviewboard/endpoint"
 	ghIssues "github.com/salsaflow/salsaflow-daemon/internal/modules/issuetracking/github/endpoint"
+	glIssues "github.com/salsaflow/salsaflow-daemon/internal/modules/issuetracking/gitlab/endpoint"
+	jira "github.com/salsaflow/salsaflow-daemon/internal/modules/issuetracking/jira/endpoint"
 	pt "github.com/salsaflow/salsaflow-daemon/internal/modules/issuetracking/pivotaltracker/endpoint"
+
+	// Vendor
+	"github.com/codegangsta/negroni"
 )
 
 type ModuleEndpoint interface {
 	ModuleId() string
+
+	// NewAuthMiddleware returns the middleware authenticating the incoming webhooks.
+	// It is run before the webhooks are queued so that only authenticated webhooks
+	// are ever stored. nil is returned in case the webhooks are not authenticated.
+	NewAuthMiddleware() (negroni.Handler, error)
+
+	// NewHandler returns the handler processing the queued webhooks.
 	NewHandler() (http.Handler, error)
 }
 
 var endpoints = []ModuleEndpoint{
 	ghReview.NewEndpoint(),
+	glReview.NewEndpoint(),
+	rbReview.NewEndpoint(),
 	ghIssues.NewEndpoint(),
+	glIssues.NewEndpoint(),
+	jira.NewEndpoint(),
 	pt.NewEndpoint(),
 }
 
@@ -1,10 +1,18 @@
 package modules
 
 import (
+	// Stdlib
+	"net/http"
+
 	// Internal
+	"github.com/salsaflow/salsaflow-daemon/internal/log"
 	"github.com/salsaflow/salsaflow-daemon/internal/modules/common"
 	gh "github.com/salsaflow/salsaflow-daemon/internal/modules/issuetracking/github"
 	ghTracker "github.com/salsaflow/salsaflow-daemon/internal/modules/issuetracking/github/tracker"
+	gl "github.com/salsaflow/salsaflow-daemon/internal/modules/issuetracking/gitlab"
+	glTracker "github.com/salsaflow/salsaflow-daemon/internal/modules/issuetracking/gitlab/tracker"
+	jira "github.com/salsaflow/salsaflow-daemon/internal/modules/issuetracking/jira"
+	jiraTracker "github.com/salsaflow/salsaflow-daemon/internal/modules/issuetracking/jira/tracker"
 	pt "github.com/salsaflow/salsaflow-daemon/internal/modules/issuetracking/pivotaltracker"
 	ptTracker "github.com/salsaflow/salsaflow-daemon/internal/modules/issuetracking/pivotaltracker/tracker"
 )
@@ -12,8 +20,10 @@ import (
 type factoryFunc func() (common.IssueTracker, error)
 
 var factories = map[string]factoryFunc{
-	gh.ModuleId: ghTracker.Factory,
-	pt.ModuleId: ptTracker.Factory,
+	gh.ModuleId:   ghTracker.Factory,
+	gl.ModuleId:   glTracker.Factory,
+	jira.ModuleId: jiraTracker.Factory,
+	pt.ModuleId:   ptTracker.Factory,
 }
 
 // GetIssueTracker can be used to get a common.IssueTracker for the given module ID.
@@ -23,6 +33,10 @@ func GetIssueTracker(moduleId string) (common.IssueTracker, error) {
 	switch moduleId {
 	case "GitHub Issues":
 		moduleId = gh.ModuleId
+	case "GitLab Issues":
+		moduleId = gl.ModuleId
+	case "JIRA":
+		moduleId = jira.ModuleId
 	case "Pivotal Tracker":
 		moduleId = pt.ModuleId
 	}
@@ -36,3 +50,29 @@ func GetIssueTracker(moduleId string) (common.IssueTracker, error) {
 	// Return a new IssueTracker instance.
 	return factory()
 }
+
+// FindStory instantiates the issue tracker for the given module ID
+// and uses it to find the story with the given tag.
+func FindStory(moduleId, storyTag string) (common.Story, error) {
+	tracker, err := GetIssueTracker(moduleId)
+	if err != nil {
+		return nil, err
+	}
+
+	return tracker.FindStoryByTag(storyTag)
+}
+
+// NotifyStory finds the story with the given tag and it passes it to notify.
+// It is used to call the story hooks once the code review tool is updated.
+// The errors are only logged since the code review changes are recorded already
+// and processing the event again would only duplicate them.
+func NotifyStory(r *http.Request, moduleId, storyTag string, notify func(common.Story) error) {
+	log.AddField(r, log.FieldStoryTag, storyTag)
+	story, err := FindStory(moduleId, storyTag)
+	if err == nil {
+		err = notify(story)
+	}
+	if err != nil {
+		log.Error(r, err)
+	}
+}
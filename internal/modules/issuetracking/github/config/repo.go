@@ -0,0 +1,82 @@
+package config
+
+import (
+	// Internal
+	"github.com/salsaflow/salsaflow-daemon/internal/github/repoconfig"
+	"github.com/salsaflow/salsaflow-daemon/internal/tenants"
+
+	// Vendor
+	"github.com/google/go-github/github"
+)
+
+// RepoSectionKey is the key of the section in .salsaflow/config.json
+// containing the GitHub Issues configuration, i.e. the module ID.
+const RepoSectionKey = "salsaflow.modules.issuetracking.github"
+
+type repoConfig struct {
+	StoryLabels []string `json:"story_labels"`
+	StateLabels *struct {
+		ApprovedLabel         string `json:"approved"`
+		BeingImplementedLabel string `json:"being_implemented"`
+		ImplementedLabel      string `json:"implemented"`
+		ReviewedLabel         string `json:"reviewed"`
+		SkipReviewLabel       string `json:"skip_review"`
+		PassedTestingLabel    string `json:"passed_testing"`
+		FailedTestingLabel    string `json:"failed_testing"`
+		SkipTestingLabel      string `json:"skip_testing"`
+		StagedLabel           string `json:"staged_for_acceptance"`
+		RejectedLabel         string `json:"client_rejected"`
+	} `json:"state_labels"`
+}
+
+// ForRepo returns the configuration to be used for the given repository.
+// The values found in the repository configuration file take precedence,
+// then the label scheme of the tenant the repository owner belongs to is used.
+// The values read from the environment are used for the rest.
+func ForRepo(client *github.Client, owner, repo string) (Config, error) {
+	c := Get()
+
+	var tc repoConfig
+	ok, err := tenants.ForGitHubOwner(owner).Section(RepoSectionKey, &tc)
+	if err != nil {
+		return c, err
+	}
+	if ok {
+		c = tc.apply(c)
+	}
+
+	var rc repoConfig
+	ok, err = repoconfig.Load(client, owner, repo).Section(RepoSectionKey, &rc)
+	if err != nil || !ok {
+		return c, err
+	}
+
+	return rc.apply(c), nil
+}
+
+func (rc *repoConfig) apply(c Config) Config {
+	set := func(dst *string, src string) {
+		if src != "" {
+			*dst = src
+		}
+	}
+
+	if len(rc.StoryLabels) != 0 {
+		c.StoryLabels = rc.StoryLabels
+	}
+
+	if sl := rc.StateLabels; sl != nil {
+		set(&c.ApprovedLabel, sl.ApprovedLabel)
+		set(&c.BeingImplementedLabel, sl.BeingImplementedLabel)
+		set(&c.ImplementedLabel, sl.ImplementedLabel)
+		set(&c.ReviewedLabel, sl.ReviewedLabel)
+		set(&c.SkipReviewLabel, sl.SkipReviewLabel)
+		set(&c.PassedTestingLabel, sl.PassedTestingLabel)
+		set(&c.FailedTestingLabel, sl.FailedTestingLabel)
+		set(&c.SkipTestingLabel, sl.SkipTestingLabel)
+		set(&c.StagedLabel, sl.StagedLabel)
+		set(&c.RejectedLabel, sl.RejectedLabel)
+	}
+
+	return c
+}
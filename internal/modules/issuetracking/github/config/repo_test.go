@@ -0,0 +1,55 @@
+package config
+
+import (
+	// Stdlib
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+// testingSection is the GitHub Issues section of a real .salsaflow/config.json
+// with some of the labels customized.
+const testingSection = `{
+  "story_labels": [
+    "feature",
+    "bug"
+  ],
+  "state_labels": {
+    "approved": "approved",
+    "being_implemented": "being implemented",
+    "implemented": "implemented",
+    "reviewed": "reviewed",
+    "skip_review": "no review",
+    "passed_testing": "qa+",
+    "failed_testing": "qa-",
+    "skip_testing": "no qa",
+    "staged_for_acceptance": "ready for acceptance",
+    "client_rejected": "client rejected"
+  },
+  "skip_release_check_labels": [
+    "duplicate",
+    "invalid"
+  ]
+}`
+
+func TestRepoConfig_apply(t *testing.T) {
+	var rc repoConfig
+	if err := json.Unmarshal([]byte(testingSection), &rc); err != nil {
+		t.Fatal(err)
+	}
+
+	c := rc.apply(Config{StagedLabel: "staged", RejectedLabel: "rejected"})
+
+	if expected := []string{"feature", "bug"}; !reflect.DeepEqual(c.StoryLabels, expected) {
+		t.Errorf("expected story labels %q, got %q", expected, c.StoryLabels)
+	}
+	if c.StagedLabel != "ready for acceptance" {
+		t.Errorf("unexpected staged label: %q", c.StagedLabel)
+	}
+	if c.RejectedLabel != "client rejected" {
+		t.Errorf("unexpected rejected label: %q", c.RejectedLabel)
+	}
+	if c.ApprovedLabel != "approved" {
+		t.Errorf("unexpected approved label: %q", c.ApprovedLabel)
+	}
+}
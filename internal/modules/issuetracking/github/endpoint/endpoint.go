@@ -7,6 +7,9 @@ import (
 	// Internal
 	"github.com/salsaflow/salsaflow-daemon/internal/github"
 	module "github.com/salsaflow/salsaflow-daemon/internal/modules/issuetracking/github"
+
+	// Vendor
+	"github.com/codegangsta/negroni"
 )
 
 type Endpoint struct{}
@@ -19,13 +22,19 @@ func (ep *Endpoint) ModuleId() string {
 	return module.ModuleId
 }
 
+func (ep *Endpoint) NewAuthMiddleware() (negroni.Handler, error) {
+	return github.NewAuthMiddleware(), nil
+}
+
 func (ep *Endpoint) NewHandler() (http.Handler, error) {
-	client, err := github.NewClient()
-	if err != nil {
+	if err := github.EnsureCredentials(); err != nil {
 		return nil, err
 	}
 
-	handler := github.NewWebhookHandler(&eventHandler{client})
+	handler, err := github.NewWebhookHandler(&eventHandler{newClient: github.NewClientForRequest})
+	if err != nil {
+		return nil, err
+	}
 
 	mux := http.NewServeMux()
 	mux.Handle("/events", handler)
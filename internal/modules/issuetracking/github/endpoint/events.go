@@ -3,16 +3,23 @@ package endpoint
 import (
 	// Stdlib
 	"errors"
+	"net/http"
 
 	// Internal
 	"github.com/salsaflow/salsaflow-daemon/internal/github/events"
+	"github.com/salsaflow/salsaflow-daemon/internal/github/repoconfig"
 
 	// Vendor
 	"github.com/google/go-github/github"
 )
 
 type eventHandler struct {
-	client *github.Client
+	// PushEventHandler drops the cached repository configuration on push.
+	repoconfig.PushEventHandler
+
+	// newClient returns the API client to be used for the given repository owner
+	// when handling the given webhook.
+	newClient func(r *http.Request, owner string) (*github.Client, error)
 }
 
 func init() {
@@ -32,5 +39,9 @@ func ensureInterfaces() error {
 		return errors.New("eventHandler does not implement events.IssuesEventHandler")
 	}
 
+	if _, ok := handler.(events.PushEventHandler); !ok {
+		return errors.New("eventHandler does not implement events.PushEventHandler")
+	}
+
 	return nil
 }
@@ -2,14 +2,15 @@ package endpoint
 
 import (
 	// Stdlib
-	"bufio"
 	"net/http"
-	"strings"
 
 	// Internal
+	"github.com/salsaflow/salsaflow-daemon/internal/commands"
 	"github.com/salsaflow/salsaflow-daemon/internal/github/events"
 	httputil "github.com/salsaflow/salsaflow-daemon/internal/http"
 	"github.com/salsaflow/salsaflow-daemon/internal/log"
+	"github.com/salsaflow/salsaflow-daemon/internal/metrics"
+	"github.com/salsaflow/salsaflow-daemon/internal/modules/common"
 	"github.com/salsaflow/salsaflow-daemon/internal/modules/issuetracking/github/config"
 	"github.com/salsaflow/salsaflow-daemon/internal/modules/issuetracking/github/util"
 
@@ -31,22 +32,36 @@ func (handler *eventHandler) HandleIssueCommentEvent(
 		repo     = *event.Repo.Name
 		issueNum = *event.Issue.Number
 	)
+	client, err := handler.newClient(r, owner)
+	if err != nil {
+		httputil.Error(rw, r, err)
+		return
+	}
+
 	log.Info(r, "Re-fetching issue %v/%v#%v", owner, repo, issueNum)
-	issue, _, err := handler.client.Issues.Get(owner, repo, issueNum)
+	issue, _, err := client.Issues.Get(owner, repo, issueNum)
+	if err != nil {
+		httputil.Error(rw, r, err)
+		return
+	}
+
+	// Get the configuration for this repository.
+	cfg, err := config.ForRepo(client, owner, repo)
 	if err != nil {
 		httputil.Error(rw, r, err)
 		return
 	}
 
 	// Make sure this is a story issue.
-	if !isStoryIssue(issue, config.Get()) {
+	if !isStoryIssue(issue, cfg) {
+		metrics.Skip(r)
 		log.Info(r, "Issue %v is not a story issue, skipping", *issue.HTMLURL)
 		return
 	}
 
 	switch *event.Action {
 	case "created":
-		handler.onIssueCommentCreated(rw, r, event, issue)
+		handler.onIssueCommentCreated(rw, r, client, event, issue, cfg)
 	default:
 		httputil.Status(rw, http.StatusAccepted)
 	}
@@ -55,41 +70,62 @@ func (handler *eventHandler) HandleIssueCommentEvent(
 func (handler *eventHandler) onIssueCommentCreated(
 	rw http.ResponseWriter,
 	r *http.Request,
+	client *github.Client,
 	event *events.IssueCommentEvent,
 	issue *github.Issue,
+	cfg config.Config,
 ) {
 
-	scanner := bufio.NewScanner(strings.NewReader(*event.Comment.Body))
-	scanner.Split(bufio.ScanWords)
-	for scanner.Scan() {
-		word := scanner.Text()
-		switch word {
-		case "!reject":
-			if err := handler.rejectIssue(r, event, issue); err != nil {
+	// Process the comment body.
+	comment := event.Comment
+	invs, errs := issueCommentCommands.Parse(*comment.Body)
+	for _, inv := range invs {
+		switch inv.Command {
+		case common.RejectCommand:
+			if err := handler.rejectIssue(r, client, event, issue, cfg); err != nil {
 				httputil.Error(rw, r, err)
 				return
 			}
 		}
 	}
-	if err := scanner.Err(); err != nil {
-		httputil.Error(rw, r, err)
-		return
+
+	// Reply in case there is !help or a malformed command.
+	if reply := issueCommentCommands.Reply(*comment.User.Login, invs, errs); reply != "" {
+		var (
+			owner    = *event.Repo.Owner.Login
+			repo     = *event.Repo.Name
+			issueNum = *issue.Number
+		)
+		log.Info(r, "Replying to comment %v", *comment.HTMLURL)
+		_, _, err := client.Issues.CreateComment(owner, repo, issueNum, &github.IssueComment{
+			Body: github.String(reply),
+		})
+		if err != nil {
+			// The commands are processed already, do not make the delivery
+			// fail since the retry would run them again.
+			log.Error(r, err)
+		}
 	}
 
 	httputil.Status(rw, http.StatusAccepted)
 }
 
+// issueCommentCommands are the commands available in story issue comments.
+var issueCommentCommands = commands.NewRegistry(common.RejectCommand)
+
 func (handler *eventHandler) rejectIssue(
 	r *http.Request,
+	client *github.Client,
 	event *events.IssueCommentEvent,
 	issue *github.Issue,
+	cfg config.Config,
 ) error {
 
 	// Mark the issue as rejected.
 	var (
 		owner  = *event.Repo.Owner.Login
 		repo   = *event.Repo.Name
-		labels = []string{config.Get().RejectedLabel}
+		labels = []string{cfg.RejectedLabel}
 	)
-	return util.ReplaceWorkflowLabels(handler.client, owner, repo, issue, labels, nil)
+	return util.ReplaceWorkflowLabels(client, cfg, owner, repo, issue, labels, nil)
 }
@@ -8,6 +8,7 @@ import (
 	"github.com/salsaflow/salsaflow-daemon/internal/github/events"
 	httputil "github.com/salsaflow/salsaflow-daemon/internal/http"
 	"github.com/salsaflow/salsaflow-daemon/internal/log"
+	"github.com/salsaflow/salsaflow-daemon/internal/metrics"
 	"github.com/salsaflow/salsaflow-daemon/internal/modules/issuetracking/github/config"
 	"github.com/salsaflow/salsaflow-daemon/internal/modules/issuetracking/github/util"
 
@@ -29,24 +30,38 @@ func (handler *eventHandler) HandleIssuesEvent(
 		repo     = *event.Repo.Name
 		issueNum = *event.Issue.Number
 	)
+	client, err := handler.newClient(r, owner)
+	if err != nil {
+		httputil.Error(rw, r, err)
+		return
+	}
+
 	log.Info(r, "Re-fetching issue %v/%v#%v", owner, repo, issueNum)
-	issue, _, err := handler.client.Issues.Get(owner, repo, issueNum)
+	issue, _, err := client.Issues.Get(owner, repo, issueNum)
+	if err != nil {
+		httputil.Error(rw, r, err)
+		return
+	}
+
+	// Get the configuration for this repository.
+	cfg, err := config.ForRepo(client, owner, repo)
 	if err != nil {
 		httputil.Error(rw, r, err)
 		return
 	}
 
 	// Make sure this is a story issue.
-	if !isStoryIssue(issue, config.Get()) {
+	if !isStoryIssue(issue, cfg) {
+		metrics.Skip(r)
 		log.Info(r, "Issue %v is not a story issue, skipping", *issue.HTMLURL)
 		return
 	}
 
 	switch *event.Action {
 	case "closed":
-		handler.onIssueClosed(rw, r, event, issue)
+		handler.onIssueClosed(rw, r, client, event, issue, cfg)
 	case "reopened":
-		handler.onIssueReopened(rw, r, event, issue)
+		handler.onIssueReopened(rw, r, client, event, issue, cfg)
 	default:
 		httputil.Status(rw, http.StatusAccepted)
 	}
@@ -55,8 +70,10 @@ func (handler *eventHandler) HandleIssuesEvent(
 func (handler *eventHandler) onIssueClosed(
 	rw http.ResponseWriter,
 	r *http.Request,
+	client *github.Client,
 	event *events.IssuesEvent,
 	issue *github.Issue,
+	cfg config.Config,
 ) {
 
 	// When an issue is closed, we want to prune all SalsaFlow labels.
@@ -64,7 +81,7 @@ func (handler *eventHandler) onIssueClosed(
 		owner = *event.Repo.Owner.Login
 		repo  = *event.Repo.Name
 	)
-	err := util.ReplaceWorkflowLabels(handler.client, owner, repo, issue, nil, nil)
+	err := util.ReplaceWorkflowLabels(client, cfg, owner, repo, issue, nil, nil)
 	if err != nil {
 		httputil.Error(rw, r, err)
 	} else {
@@ -75,17 +92,19 @@ func (handler *eventHandler) onIssueClosed(
 func (handler *eventHandler) onIssueReopened(
 	rw http.ResponseWriter,
 	r *http.Request,
+	client *github.Client,
 	event *events.IssuesEvent,
 	issue *github.Issue,
+	cfg config.Config,
 ) {
 
 	// When an issue is reopened, we want to move it into Being Implemented.
 	var (
 		owner  = *event.Repo.Owner.Login
 		repo   = *event.Repo.Name
-		labels = []string{config.Get().BeingImplementedLabel}
+		labels = []string{cfg.BeingImplementedLabel}
 	)
-	err := util.ReplaceWorkflowLabels(handler.client, owner, repo, issue, labels, nil)
+	err := util.ReplaceWorkflowLabels(client, cfg, owner, repo, issue, labels, nil)
 	if err != nil {
 		httputil.Error(rw, r, err)
 	} else {
@@ -9,6 +9,7 @@ import (
 	// Internal
 	githubutil "github.com/salsaflow/salsaflow-daemon/internal/github"
 	"github.com/salsaflow/salsaflow-daemon/internal/modules/common"
+	"github.com/salsaflow/salsaflow-daemon/internal/modules/issuetracking/github/config"
 
 	// Vendor
 	"github.com/google/go-github/github"
@@ -17,16 +18,16 @@ import (
 const Id = "GitHub Issues"
 
 func Factory() (common.IssueTracker, error) {
-	client, err := githubutil.NewClient()
-	if err != nil {
+	if err := githubutil.EnsureCredentials(); err != nil {
 		return nil, err
 	}
 
-	return &issueTracker{client}, nil
+	return &issueTracker{githubutil.NewClientForOwner}, nil
 }
 
 type issueTracker struct {
-	client *github.Client
+	// newClient returns the API client to be used for the given repository owner.
+	newClient func(owner string) (*github.Client, error)
 }
 
 func (tracker *issueTracker) FindStoryByTag(storyTag string) (common.Story, error) {
@@ -35,12 +36,22 @@ func (tracker *issueTracker) FindStoryByTag(storyTag string) (common.Story, erro
 		return nil, err
 	}
 
-	issue, _, err := tracker.client.Issues.Get(owner, repo, issueNum)
+	client, err := tracker.newClient(owner)
+	if err != nil {
+		return nil, err
+	}
+
+	issue, _, err := client.Issues.Get(owner, repo, issueNum)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg, err := config.ForRepo(client, owner, repo)
 	if err != nil {
 		return nil, err
 	}
 
-	return &commonStory{tracker.client, issue, owner, repo}, nil
+	return &commonStory{client, cfg, issue, owner, repo}, nil
 }
 
 func parseStoryTag(storyTag string) (owner, repo string, issueNum int, err error) {
@@ -15,6 +15,7 @@ import (
 
 type commonStory struct {
 	client *github.Client
+	config config.Config
 	issue  *github.Issue
 	owner  string
 	repo   string
@@ -31,7 +32,7 @@ func (s *commonStory) OnReviewRequestClosed(rrID, rrURL string) error {
 func (s *commonStory) OnReviewRequestReopened(rrID, rrURL string) error {
 	// Add 'implemented' unless 'qa+' or 'no qa' is there already.
 	var (
-		c    = config.Get()
+		c    = s.config
 		add  []string
 		keep []string
 	)
@@ -49,7 +50,7 @@ func (s *commonStory) OnReviewRequestReopened(rrID, rrURL string) error {
 func (s *commonStory) MarkAsReviewed() error {
 	// Add 'reviewed', but also keep 'qa+' and 'no qa'.
 	var (
-		c    = config.Get()
+		c    = s.config
 		add  = []string{c.ReviewedLabel}
 		keep []string
 	)
@@ -62,6 +63,39 @@ func (s *commonStory) MarkAsReviewed() error {
 	return s.updateStateLabels(add, keep)
 }
 
+func (s *commonStory) OnReviewBlockerOpened(blockerURL, summary string) error {
+	if err := s.addComment(fmt.Sprintf("Review blocker [opened](%v): %v", blockerURL, summary)); err != nil {
+		return err
+	}
+
+	// The story is not reviewed any more, move it back to 'implemented'.
+	c := s.config
+	if !githubutil.LabeledWith(s.issue, c.ReviewedLabel) {
+		return nil
+	}
+	return s.updateStateLabels([]string{c.ImplementedLabel}, s.testingLabels())
+}
+
+func (s *commonStory) OnReviewBlockerFixed(blockerURL, summary string) error {
+	return s.addComment(fmt.Sprintf("Review blocker [fixed](%v): %v", blockerURL, summary))
+}
+
+// testingLabels returns the testing related labels the issue is labeled with.
+func (s *commonStory) testingLabels() []string {
+	c := s.config
+	return s.labeledWith(c.PassedTestingLabel, c.SkipTestingLabel)
+}
+
+func (s *commonStory) labeledWith(labels ...string) []string {
+	var ls []string
+	for _, label := range labels {
+		if githubutil.LabeledWith(s.issue, label) {
+			ls = append(ls, label)
+		}
+	}
+	return ls
+}
+
 func (s *commonStory) addComment(text string) error {
 	var (
 		client   = s.client
@@ -76,5 +110,5 @@ func (s *commonStory) addComment(text string) error {
 }
 
 func (s *commonStory) updateStateLabels(add, keep []string) error {
-	return util.ReplaceWorkflowLabels(s.client, s.owner, s.repo, s.issue, add, keep)
+	return util.ReplaceWorkflowLabels(s.client, s.config, s.owner, s.repo, s.issue, add, keep)
 }
@@ -0,0 +1,86 @@
+package tracker
+
+import (
+	// Stdlib
+	"fmt"
+)
+
+var _ = Describe("Invoking OnReviewBlockerOpened story event handler", func() {
+
+	const (
+		blockerURL     = "https://some-blocker-url"
+		blockerSummary = "Fix the typo"
+	)
+
+	data := []struct {
+		labels string
+		update string
+	}{
+		{"other", ""},
+		{"implemented,other", ""},
+		{"reviewed,other", "implemented,other"},
+		{"reviewed,qa+,other", "implemented,qa+,other"},
+		{"reviewed,no qa", "implemented,no qa"},
+	}
+
+	for i := range data {
+		func(i int) {
+			td := data[i]
+			ctx := fmt.Sprintf("labels=%q, update=%q", td.labels, td.update)
+
+			Context(ctx, func() {
+
+				var gh *testingGitHub
+
+				BeforeEach(func() {
+					gh = newTestingGitHub()
+				})
+
+				AfterEach(func() {
+					gh.Close()
+				})
+
+				It("adds the comment and sends out the expected requests", func() {
+					story := gh.newStory(labelList(td.labels)...)
+
+					err := story.OnReviewBlockerOpened(blockerURL, blockerSummary)
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(gh.comments).To(Equal([]string{
+						fmt.Sprintf("Review blocker [opened](%v): %v", blockerURL, blockerSummary),
+					}))
+					if td.update == "" {
+						Expect(gh.labelUpdates).To(BeEmpty())
+					} else {
+						Expect(gh.labelUpdates).To(Equal([][]string{labelList(td.update)}))
+					}
+				})
+			})
+		}(i)
+	}
+})
+
+var _ = Describe("Invoking OnReviewBlockerFixed story event handler", func() {
+
+	var gh *testingGitHub
+
+	BeforeEach(func() {
+		gh = newTestingGitHub()
+	})
+
+	AfterEach(func() {
+		gh.Close()
+	})
+
+	It("only adds the comment", func() {
+		story := gh.newStory("implemented")
+
+		err := story.OnReviewBlockerFixed("https://some-blocker-url", "Fix the typo")
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(gh.comments).To(Equal([]string{
+			"Review blocker [fixed](https://some-blocker-url): Fix the typo",
+		}))
+		Expect(gh.labelUpdates).To(BeEmpty())
+	})
+})
@@ -0,0 +1,138 @@
+package tracker
+
+import (
+	// Stdlib
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+
+	// Internal
+	"github.com/salsaflow/salsaflow-daemon/internal/modules/issuetracking/github/config"
+
+	// Vendor
+	"github.com/google/go-github/github"
+	"github.com/onsi/ginkgo"
+	"github.com/onsi/gomega"
+)
+
+// Set up Ginkgo and Gomega ----------------------------------------------------
+
+func TestIssueTracker(t *testing.T) {
+	gomega.RegisterFailHandler(ginkgo.Fail)
+	ginkgo.RunSpecs(t, "GitHub Issues Tracker Suite")
+}
+
+// Testing imports -------------------------------------------------------------
+
+var (
+	AfterEach  = ginkgo.AfterEach
+	BeforeEach = ginkgo.BeforeEach
+	Context    = ginkgo.Context
+	Describe   = ginkgo.Describe
+	It         = ginkgo.It
+
+	BeEmpty      = gomega.BeEmpty
+	Equal        = gomega.Equal
+	Expect       = gomega.Expect
+	HaveOccurred = gomega.HaveOccurred
+)
+
+// Shared testing infrastructure -----------------------------------------------
+
+const (
+	testingOwner    = "owner"
+	testingRepo     = "repo"
+	testingIssueNum = 42
+)
+
+var testingConfig = config.Config{
+	ApprovedLabel:         "approved",
+	BeingImplementedLabel: "being implemented",
+	ImplementedLabel:      "implemented",
+	ReviewedLabel:         "reviewed",
+	SkipReviewLabel:       "no review",
+	PassedTestingLabel:    "qa+",
+	FailedTestingLabel:    "qa-",
+	SkipTestingLabel:      "no qa",
+	StagedLabel:           "staged",
+	RejectedLabel:         "rejected",
+}
+
+// testingGitHub is a GitHub stand-in implementing the part of the issues API
+// that is used by the tracker. It records all modifying requests.
+type testingGitHub struct {
+	*httptest.Server
+
+	mu           sync.Mutex
+	comments     []string
+	labelUpdates [][]string
+}
+
+func newTestingGitHub() *testingGitHub {
+	gh := &testingGitHub{}
+	gh.Server = httptest.NewServer(gh)
+	return gh
+}
+
+// newStory returns a story for the testing issue labeled with the given labels.
+func (gh *testingGitHub) newStory(labels ...string) *commonStory {
+	client := github.NewClient(nil)
+	client.BaseURL, _ = url.Parse(gh.URL + "/")
+
+	issue := &github.Issue{Number: github.Int(testingIssueNum)}
+	for _, label := range labels {
+		issue.Labels = append(issue.Labels, github.Label{Name: github.String(label)})
+	}
+	return &commonStory{client, testingConfig, issue, testingOwner, testingRepo}
+}
+
+func (gh *testingGitHub) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
+	gh.mu.Lock()
+	defer gh.mu.Unlock()
+
+	issuePath := fmt.Sprintf("/repos/%v/%v/issues/%v", testingOwner, testingRepo, testingIssueNum)
+	switch {
+	case r.Method == "POST" && r.URL.Path == issuePath+"/comments":
+		var comment github.IssueComment
+		if err := json.NewDecoder(r.Body).Decode(&comment); err != nil || comment.Body == nil {
+			http.Error(rw, "Bad Request", http.StatusBadRequest)
+			return
+		}
+		gh.comments = append(gh.comments, *comment.Body)
+		rw.WriteHeader(http.StatusCreated)
+		writeJSON(rw, &comment)
+
+	case r.Method == "PUT" && r.URL.Path == issuePath+"/labels":
+		var names []string
+		if err := json.NewDecoder(r.Body).Decode(&names); err != nil {
+			http.Error(rw, "Bad Request", http.StatusBadRequest)
+			return
+		}
+		gh.labelUpdates = append(gh.labelUpdates, names)
+		labels := make([]github.Label, 0, len(names))
+		for _, name := range names {
+			labels = append(labels, github.Label{Name: github.String(name)})
+		}
+		writeJSON(rw, labels)
+
+	default:
+		http.Error(rw, `{"message":"Not Found"}`, http.StatusNotFound)
+	}
+}
+
+func writeJSON(rw http.ResponseWriter, v interface{}) {
+	rw.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(rw).Encode(v)
+}
+
+func labelList(labels string) []string {
+	if labels == "" {
+		return nil
+	}
+	return strings.Split(labels, ",")
+}
@@ -10,6 +10,7 @@ import (
 
 func ReplaceWorkflowLabels(
 	client *github.Client,
+	c config.Config,
 	owner string,
 	repo string,
 	issue *github.Issue,
@@ -26,7 +27,6 @@ func ReplaceWorkflowLabels(
 		return false
 	}
 
-	c := config.Get()
 	labelNames := make([]string, 0, len(issue.Labels)+len(add))
 	labelNames = append(labelNames, add...)
 	for _, label := range issue.Labels {
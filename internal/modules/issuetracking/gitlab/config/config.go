@@ -0,0 +1,77 @@
+package config
+
+import (
+	// Stdlib
+	"log"
+	"strings"
+
+	// Vendor
+	"github.com/kelseyhightower/envconfig"
+)
+
+type Config struct {
+	// Story label.
+	StoryLabelList string `envconfig:"STORY_LABELS" default:"enhancement,bug"`
+
+	// StoryLabels contains parsed StoryLabelList.
+	StoryLabels []string
+
+	// Workflow scope. The state labels are scoped labels, i.e. they are
+	// all prefixed with "<scope>::", so that an issue is always in a single state.
+	WorkflowScope string `envconfig:"WORKFLOW_SCOPE" default:"workflow"`
+
+	// State labels. The values are complete label names including the scope.
+	ApprovedLabel         string `envconfig:"APPROVED_LABEL"          default:"approved"`
+	BeingImplementedLabel string `envconfig:"BEING_IMPLEMENTED_LABEL" default:"being implemented"`
+	ImplementedLabel      string `envconfig:"IMPLEMENTED_LABEL"       default:"implemented"`
+	ReviewedLabel         string `envconfig:"REVIEWED_LABEL"          default:"reviewed"`
+	SkipReviewLabel       string `envconfig:"SKIP_REVIEW_LABEL"       default:"no review"`
+	PassedTestingLabel    string `envconfig:"PASSED_TESTING_LABEL"    default:"qa+"`
+	FailedTestingLabel    string `envconfig:"FAILED_TESTING_LABEL"    default:"qa-"`
+	SkipTestingLabel      string `envconfig:"SKIP_TESTING_LABEL"      default:"no qa"`
+	StagedLabel           string `envconfig:"STAGED_LABEL"            default:"staged"`
+	RejectedLabel         string `envconfig:"REJECTED_LABEL"          default:"rejected"`
+}
+
+// IsWorkflowLabel returns true when the given label belongs to the workflow scope.
+func (c Config) IsWorkflowLabel(label string) bool {
+	return strings.HasPrefix(label, c.WorkflowScope+"::")
+}
+
+var config Config
+
+func init() {
+	if err := envconfig.Process("SFD_GITLAB", &config); err != nil {
+		log.Fatalln("Fatal error while parsing GitLab config:", err)
+	}
+
+	mp := func(xs []string, mapFunc func(string) string) []string {
+		ss := make([]string, len(xs))
+		for i, x := range xs {
+			ss[i] = mapFunc(x)
+		}
+		return ss
+	}
+
+	config.StoryLabels = mp(strings.Split(config.StoryLabelList, ","), strings.TrimSpace)
+
+	// Turn the state label names into scoped labels.
+	for _, label := range []*string{
+		&config.ApprovedLabel,
+		&config.BeingImplementedLabel,
+		&config.ImplementedLabel,
+		&config.ReviewedLabel,
+		&config.SkipReviewLabel,
+		&config.PassedTestingLabel,
+		&config.FailedTestingLabel,
+		&config.SkipTestingLabel,
+		&config.StagedLabel,
+		&config.RejectedLabel,
+	} {
+		*label = config.WorkflowScope + "::" + *label
+	}
+}
+
+func Get() Config {
+	return config
+}
@@ -0,0 +1,40 @@
+package endpoint
+
+import (
+	// Stdlib
+	"net/http"
+
+	// Internal
+	"github.com/salsaflow/salsaflow-daemon/internal/gitlab"
+	module "github.com/salsaflow/salsaflow-daemon/internal/modules/issuetracking/gitlab"
+
+	// Vendor
+	"github.com/codegangsta/negroni"
+)
+
+type Endpoint struct{}
+
+func NewEndpoint() *Endpoint {
+	return &Endpoint{}
+}
+
+func (ep *Endpoint) ModuleId() string {
+	return module.ModuleId
+}
+
+func (ep *Endpoint) NewAuthMiddleware() (negroni.Handler, error) {
+	return gitlab.NewAuthMiddleware(), nil
+}
+
+func (ep *Endpoint) NewHandler() (http.Handler, error) {
+	// The API client is created for every event that needs it,
+	// so that the daemon can start even when GitLab is not configured.
+	handler, err := gitlab.NewWebhookHandler(&eventHandler{gitlab.NewClient})
+	if err != nil {
+		return nil, err
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/events", handler)
+	return mux, nil
+}
@@ -0,0 +1,34 @@
+package endpoint
+
+import (
+	// Stdlib
+	"errors"
+
+	// Internal
+	"github.com/salsaflow/salsaflow-daemon/internal/gitlab"
+	"github.com/salsaflow/salsaflow-daemon/internal/gitlab/events"
+)
+
+type eventHandler struct {
+	newClient func() (*gitlab.Client, error)
+}
+
+func init() {
+	if err := ensureInterfaces(); err != nil {
+		panic(err)
+	}
+}
+
+func ensureInterfaces() error {
+	var handler interface{} = &eventHandler{}
+
+	if _, ok := handler.(events.IssueEventHandler); !ok {
+		return errors.New("eventHandler does not implement events.IssueEventHandler")
+	}
+
+	if _, ok := handler.(events.NoteEventHandler); !ok {
+		return errors.New("eventHandler does not implement events.NoteEventHandler")
+	}
+
+	return nil
+}
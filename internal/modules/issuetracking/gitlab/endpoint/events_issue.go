@@ -0,0 +1,100 @@
+package endpoint
+
+import (
+	// Stdlib
+	"net/http"
+	"strconv"
+
+	// Internal
+	"github.com/salsaflow/salsaflow-daemon/internal/gitlab"
+	"github.com/salsaflow/salsaflow-daemon/internal/gitlab/events"
+	httputil "github.com/salsaflow/salsaflow-daemon/internal/http"
+	"github.com/salsaflow/salsaflow-daemon/internal/modules/issuetracking/gitlab/config"
+	"github.com/salsaflow/salsaflow-daemon/internal/modules/issuetracking/gitlab/util"
+)
+
+// HandleIssueEvent implements events.IssueEventHandler
+// and it is used to handle GitLab issue hooks.
+func (handler *eventHandler) HandleIssueEvent(
+	rw http.ResponseWriter,
+	r *http.Request,
+	event *events.IssueEvent,
+) {
+	if event.Project == nil || event.ObjectAttributes == nil {
+		httputil.Status(rw, http.StatusBadRequest)
+		return
+	}
+
+	// Do nothing unless this is a close or reopen event.
+	action := event.ObjectAttributes.Action
+	switch action {
+	case "close":
+	case "reopen":
+	default:
+		httputil.Status(rw, http.StatusAccepted)
+		return
+	}
+
+	// Make sure this is a story issue event.
+	client, err := handler.newClient()
+	if err != nil {
+		httputil.Error(rw, r, err)
+		return
+	}
+
+	issue, err := getStoryIssue(r, client, event.Project.Id, event.ObjectAttributes.Iid)
+	if err != nil {
+		httputil.Error(rw, r, err)
+		return
+	}
+	if issue == nil {
+		httputil.Status(rw, http.StatusAccepted)
+		return
+	}
+
+	switch action {
+	case "close":
+		handler.onIssueClosed(rw, r, client, event, issue)
+	case "reopen":
+		handler.onIssueReopened(rw, r, client, event, issue)
+	}
+}
+
+func (handler *eventHandler) onIssueClosed(
+	rw http.ResponseWriter,
+	r *http.Request,
+	client *gitlab.Client,
+	event *events.IssueEvent,
+	issue *gitlab.Issue,
+) {
+
+	// When an issue is closed, we want to prune all SalsaFlow labels.
+	project := strconv.Itoa(event.Project.Id)
+	err := util.ReplaceWorkflowLabels(client, project, issue, nil, nil)
+	if err != nil {
+		httputil.Error(rw, r, err)
+	} else {
+		httputil.Status(rw, http.StatusAccepted)
+	}
+}
+
+func (handler *eventHandler) onIssueReopened(
+	rw http.ResponseWriter,
+	r *http.Request,
+	client *gitlab.Client,
+	event *events.IssueEvent,
+	issue *gitlab.Issue,
+) {
+
+	// When an issue is reopened, we want to move it into Being Implemented.
+	var (
+		project = strconv.Itoa(event.Project.Id)
+		labels  = []string{config.Get().BeingImplementedLabel}
+	)
+	err := util.ReplaceWorkflowLabels(client, project, issue, labels, nil)
+	if err != nil {
+		httputil.Error(rw, r, err)
+	} else {
+		httputil.Status(rw, http.StatusAccepted)
+	}
+}
@@ -0,0 +1,90 @@
+package endpoint
+
+import (
+	// Stdlib
+	"net/http"
+	"strconv"
+
+	// Internal
+	"github.com/salsaflow/salsaflow-daemon/internal/commands"
+	"github.com/salsaflow/salsaflow-daemon/internal/gitlab"
+	"github.com/salsaflow/salsaflow-daemon/internal/gitlab/events"
+	httputil "github.com/salsaflow/salsaflow-daemon/internal/http"
+	"github.com/salsaflow/salsaflow-daemon/internal/log"
+	"github.com/salsaflow/salsaflow-daemon/internal/modules/common"
+	"github.com/salsaflow/salsaflow-daemon/internal/modules/issuetracking/gitlab/config"
+	"github.com/salsaflow/salsaflow-daemon/internal/modules/issuetracking/gitlab/util"
+)
+
+// HandleNoteEvent implements events.NoteEventHandler
+// and it is used to handle GitLab note hooks.
+func (handler *eventHandler) HandleNoteEvent(
+	rw http.ResponseWriter,
+	r *http.Request,
+	event *events.NoteEvent,
+) {
+	// Only issue notes are interesting.
+	note := event.ObjectAttributes
+	if note == nil || note.NoteableType != "Issue" {
+		httputil.Status(rw, http.StatusAccepted)
+		return
+	}
+	if event.Project == nil || event.Issue == nil || event.User == nil {
+		httputil.Status(rw, http.StatusBadRequest)
+		return
+	}
+
+	// Check for the commands first so that the API is only called when necessary.
+	invs, errs := noteCommands.Parse(note.Note)
+	if len(invs) == 0 && len(errs) == 0 {
+		httputil.Status(rw, http.StatusAccepted)
+		return
+	}
+
+	// Make sure this is a story issue.
+	client, err := handler.newClient()
+	if err != nil {
+		httputil.Error(rw, r, err)
+		return
+	}
+
+	issue, err := getStoryIssue(r, client, event.Project.Id, event.Issue.Iid)
+	if err != nil {
+		httputil.Error(rw, r, err)
+		return
+	}
+	if issue == nil {
+		httputil.Status(rw, http.StatusAccepted)
+		return
+	}
+
+	// Process the commands.
+	project := strconv.Itoa(event.Project.Id)
+	for _, inv := range invs {
+		switch inv.Command {
+		case common.RejectCommand:
+			// Mark the issue as rejected.
+			labels := []string{config.Get().RejectedLabel}
+			if err := util.ReplaceWorkflowLabels(client, project, issue, labels, nil); err != nil {
+				httputil.Error(rw, r, err)
+				return
+			}
+		}
+	}
+
+	// Reply in case there is !help or a malformed command.
+	if reply := noteCommands.Reply(event.User.Username, invs, errs); reply != "" {
+		log.Info(r, "Replying to note %v", note.URL)
+		_, _, err := client.Issues.CreateNote(project, issue.Iid, &gitlab.Note{Body: reply})
+		if err != nil {
+			// The commands are processed already, do not make the delivery
+			// fail since the retry would run them again.
+			log.Error(r, err)
+		}
+	}
+
+	httputil.Status(rw, http.StatusAccepted)
+}
+
+// noteCommands are the commands available in story issue notes.
+var noteCommands = commands.NewRegistry(common.RejectCommand)
@@ -0,0 +1,47 @@
+package endpoint
+
+import (
+	// Stdlib
+	"net/http"
+	"strconv"
+
+	// Internal
+	"github.com/salsaflow/salsaflow-daemon/internal/gitlab"
+	"github.com/salsaflow/salsaflow-daemon/internal/log"
+	"github.com/salsaflow/salsaflow-daemon/internal/metrics"
+	"github.com/salsaflow/salsaflow-daemon/internal/modules/issuetracking/gitlab/config"
+)
+
+func isStoryIssue(issue *gitlab.Issue, cfg config.Config) bool {
+	for _, storyLabel := range cfg.StoryLabels {
+		if gitlab.LabeledWith(issue, storyLabel) {
+			return true
+		}
+	}
+	return false
+}
+
+// getStoryIssue re-fetches the given issue and returns it in case it is a story issue.
+// nil is returned for the issues that are not story issues.
+func getStoryIssue(
+	r *http.Request,
+	client *gitlab.Client,
+	projectId int,
+	issueIid int,
+) (*gitlab.Issue, error) {
+
+	// The labels in the webhook payload may be outdated, we need to re-fetch.
+	log.Info(r, "Re-fetching issue %v#%v", projectId, issueIid)
+	issue, _, err := client.Issues.Get(strconv.Itoa(projectId), issueIid)
+	if err != nil {
+		return nil, err
+	}
+
+	// Make sure this is a story issue.
+	if !isStoryIssue(issue, config.Get()) {
+		metrics.Skip(r)
+		log.Info(r, "Issue %v is not a story issue, skipping", issue.WebURL)
+		return nil, nil
+	}
+	return issue, nil
+}
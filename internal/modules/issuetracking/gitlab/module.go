@@ -0,0 +1,3 @@
+package gitlab
+
+const ModuleId = "salsaflow.modules.issuetracking.gitlab"
@@ -0,0 +1,54 @@
+package tracker
+
+import (
+	// Stdlib
+	"fmt"
+	"regexp"
+	"strconv"
+
+	// Internal
+	"github.com/salsaflow/salsaflow-daemon/internal/gitlab"
+	"github.com/salsaflow/salsaflow-daemon/internal/modules/common"
+)
+
+const Id = "GitLab Issues"
+
+func Factory() (common.IssueTracker, error) {
+	client, err := gitlab.NewClient()
+	if err != nil {
+		return nil, err
+	}
+
+	return &issueTracker{client}, nil
+}
+
+type issueTracker struct {
+	client *gitlab.Client
+}
+
+func (tracker *issueTracker) FindStoryByTag(storyTag string) (common.Story, error) {
+	project, issueIid, err := parseStoryTag(storyTag)
+	if err != nil {
+		return nil, err
+	}
+
+	issue, _, err := tracker.client.Issues.Get(project, issueIid)
+	if err != nil {
+		return nil, err
+	}
+
+	return &commonStory{tracker.client, issue, project}, nil
+}
+
+// The format is group/project#issueIid, there can be any number of subgroups.
+var storyTagRegexp = regexp.MustCompile("^([^#]+/[^#]+)#([0-9]+)$")
+
+func parseStoryTag(storyTag string) (project string, issueIid int, err error) {
+	match := storyTagRegexp.FindStringSubmatch(storyTag)
+	if len(match) != 3 {
+		return "", 0, fmt.Errorf("GitLab Issues: malformed story tag: %v", storyTag)
+	}
+
+	issueIid, _ = strconv.Atoi(match[2])
+	return match[1], issueIid, nil
+}
@@ -0,0 +1,77 @@
+package tracker
+
+import (
+	// Stdlib
+	"fmt"
+
+	// Internal
+	"github.com/salsaflow/salsaflow-daemon/internal/gitlab"
+	"github.com/salsaflow/salsaflow-daemon/internal/modules/issuetracking/gitlab/config"
+	"github.com/salsaflow/salsaflow-daemon/internal/modules/issuetracking/gitlab/util"
+)
+
+type commonStory struct {
+	client  *gitlab.Client
+	issue   *gitlab.Issue
+	project string
+}
+
+func (s *commonStory) OnReviewRequestOpened(rrID, rrURL string) error {
+	return s.addComment(fmt.Sprintf("Review request [#%v](%v) opened.", rrID, rrURL))
+}
+
+func (s *commonStory) OnReviewRequestClosed(rrID, rrURL string) error {
+	return nil
+}
+
+func (s *commonStory) OnReviewRequestReopened(rrID, rrURL string) error {
+	// Move to 'implemented' unless 'qa+' or 'no qa' is there already.
+	// Scoped labels are mutually exclusive, so the later state simply wins.
+	c := config.Get()
+	if s.passedOrSkippedTesting(c) {
+		return nil
+	}
+	return s.updateStateLabels([]string{c.ImplementedLabel}, nil)
+}
+
+func (s *commonStory) MarkAsReviewed() error {
+	// Move to 'reviewed' unless 'qa+' or 'no qa' is there already.
+	c := config.Get()
+	if s.passedOrSkippedTesting(c) {
+		return nil
+	}
+	return s.updateStateLabels([]string{c.ReviewedLabel}, nil)
+}
+
+func (s *commonStory) OnReviewBlockerOpened(blockerURL, summary string) error {
+	if err := s.addComment(fmt.Sprintf("Review blocker [opened](%v): %v", blockerURL, summary)); err != nil {
+		return err
+	}
+
+	// The story is not reviewed any more, move it back to 'implemented'.
+	c := config.Get()
+	if !gitlab.LabeledWith(s.issue, c.ReviewedLabel) {
+		return nil
+	}
+	return s.updateStateLabels([]string{c.ImplementedLabel}, nil)
+}
+
+func (s *commonStory) OnReviewBlockerFixed(blockerURL, summary string) error {
+	return s.addComment(fmt.Sprintf("Review blocker [fixed](%v): %v", blockerURL, summary))
+}
+
+func (s *commonStory) passedOrSkippedTesting(c config.Config) bool {
+	return gitlab.LabeledWith(s.issue, c.PassedTestingLabel) ||
+		gitlab.LabeledWith(s.issue, c.SkipTestingLabel)
+}
+
+func (s *commonStory) addComment(text string) error {
+	_, _, err := s.client.Issues.CreateNote(s.project, s.issue.Iid, &gitlab.Note{
+		Body: text,
+	})
+	return err
+}
+
+func (s *commonStory) updateStateLabels(add, keep []string) error {
+	return util.ReplaceWorkflowLabels(s.client, s.project, s.issue, add, keep)
+}
@@ -0,0 +1,199 @@
+package tracker
+
+import (
+	// Stdlib
+	"fmt"
+)
+
+var _ = Describe("Finding a story by tag", func() {
+
+	var gl *testingGitLab
+
+	BeforeEach(func() {
+		gl = newTestingGitLab()
+	})
+
+	AfterEach(func() {
+		gl.Close()
+	})
+
+	It("returns the issue with the given project and IID", func() {
+		story, err := gl.newIssueTracker().FindStoryByTag(testingStoryTag)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(story.(*commonStory).issue.Iid).To(Equal(testingIssueIid))
+		Expect(story.(*commonStory).project).To(Equal(testingProject))
+		Expect(gl.unauthorized).To(Equal(false))
+	})
+
+	It("fails for a malformed story tag", func() {
+		_, err := gl.newIssueTracker().FindStoryByTag("project#42")
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("fails for an unknown issue", func() {
+		_, err := gl.newIssueTracker().FindStoryByTag("group/subgroup/project#43")
+		Expect(err).To(HaveOccurred())
+	})
+})
+
+var _ = Describe("Invoking OnReviewRequestOpened story event handler", func() {
+
+	var gl *testingGitLab
+
+	BeforeEach(func() {
+		gl = newTestingGitLab()
+	})
+
+	AfterEach(func() {
+		gl.Close()
+	})
+
+	It("should result in a note being added to the relevant issue", func() {
+		story, err := gl.newIssueTracker().FindStoryByTag(testingStoryTag)
+		Expect(err).NotTo(HaveOccurred())
+
+		err = story.OnReviewRequestOpened(testingReviewRequestId, testingReviewRequestURL)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(gl.notes).To(Equal([]string{
+			fmt.Sprintf("Review request [#%v](%v) opened.",
+				testingReviewRequestId, testingReviewRequestURL),
+		}))
+	})
+})
+
+var _ = Describe("Invoking OnReviewRequestReopened story event handler", func() {
+
+	data := []struct {
+		labels []string
+		update []string
+	}{
+		{
+			[]string{"bug", "workflow::reviewed"},
+			[]string{"workflow::implemented,bug"},
+		},
+		{
+			[]string{"bug", "workflow::qa+"},
+			nil,
+		},
+		{
+			[]string{"workflow::no qa"},
+			nil,
+		},
+	}
+
+	for _, d := range data {
+		d := d
+
+		Context(fmt.Sprintf("with labels %v", d.labels), func() {
+
+			var gl *testingGitLab
+
+			BeforeEach(func() {
+				gl = newTestingGitLab(d.labels...)
+			})
+
+			AfterEach(func() {
+				gl.Close()
+			})
+
+			It(fmt.Sprintf("should set labels to %v", d.update), func() {
+				story, err := gl.newIssueTracker().FindStoryByTag(testingStoryTag)
+				Expect(err).NotTo(HaveOccurred())
+
+				err = story.OnReviewRequestReopened(testingReviewRequestId, testingReviewRequestURL)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(gl.labelUpdates).To(Equal(d.update))
+			})
+		})
+	}
+})
+
+var _ = Describe("Invoking MarkAsReviewed story event handler", func() {
+
+	data := []struct {
+		labels []string
+		update []string
+	}{
+		{
+			[]string{"enhancement", "workflow::implemented", "workflow::being implemented"},
+			[]string{"workflow::reviewed,enhancement"},
+		},
+		{
+			[]string{"workflow::qa+"},
+			nil,
+		},
+	}
+
+	for _, d := range data {
+		d := d
+
+		Context(fmt.Sprintf("with labels %v", d.labels), func() {
+
+			var gl *testingGitLab
+
+			BeforeEach(func() {
+				gl = newTestingGitLab(d.labels...)
+			})
+
+			AfterEach(func() {
+				gl.Close()
+			})
+
+			It(fmt.Sprintf("should set labels to %v", d.update), func() {
+				story, err := gl.newIssueTracker().FindStoryByTag(testingStoryTag)
+				Expect(err).NotTo(HaveOccurred())
+
+				err = story.MarkAsReviewed()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(gl.labelUpdates).To(Equal(d.update))
+			})
+		})
+	}
+})
+
+var _ = Describe("Invoking OnReviewBlockerOpened story event handler", func() {
+
+	data := []struct {
+		labels []string
+		update []string
+	}{
+		{
+			[]string{"bug", "workflow::reviewed"},
+			[]string{"workflow::implemented,bug"},
+		},
+		{
+			[]string{"bug", "workflow::implemented"},
+			nil,
+		},
+	}
+
+	for _, d := range data {
+		d := d
+
+		Context(fmt.Sprintf("with labels %v", d.labels), func() {
+
+			var gl *testingGitLab
+
+			BeforeEach(func() {
+				gl = newTestingGitLab(d.labels...)
+			})
+
+			AfterEach(func() {
+				gl.Close()
+			})
+
+			It(fmt.Sprintf("should add a note and set labels to %v", d.update), func() {
+				story, err := gl.newIssueTracker().FindStoryByTag(testingStoryTag)
+				Expect(err).NotTo(HaveOccurred())
+
+				err = story.OnReviewBlockerOpened("https://some-note-url", "Fix the typo")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(gl.notes).To(Equal([]string{
+					"Review blocker [opened](https://some-note-url): Fix the typo",
+				}))
+				Expect(gl.labelUpdates).To(Equal(d.update))
+			})
+		})
+	}
+})
@@ -0,0 +1,140 @@
+package tracker
+
+import (
+	// Stdlib
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	// Internal
+	"github.com/salsaflow/salsaflow-daemon/internal/gitlab"
+
+	// Vendor
+	"github.com/onsi/ginkgo"
+	"github.com/onsi/gomega"
+)
+
+// Set up Ginkgo and Gomega ----------------------------------------------------
+
+func TestIssueTracker(t *testing.T) {
+	gomega.RegisterFailHandler(ginkgo.Fail)
+	ginkgo.RunSpecs(t, "GitLab Issues Tracker Suite")
+}
+
+// Testing imports -------------------------------------------------------------
+
+var (
+	AfterEach  = ginkgo.AfterEach
+	BeforeEach = ginkgo.BeforeEach
+	Context    = ginkgo.Context
+	Describe   = ginkgo.Describe
+	It         = ginkgo.It
+
+	BeEmpty      = gomega.BeEmpty
+	Equal        = gomega.Equal
+	Expect       = gomega.Expect
+	HaveOccurred = gomega.HaveOccurred
+)
+
+// Shared testing infrastructure -----------------------------------------------
+
+const (
+	testingProject          = "group/subgroup/project"
+	testingIssueIid         = 42
+	testingStoryTag         = "group/subgroup/project#42"
+	testingToken            = "t0k3n"
+	testingReviewRequestId  = "10"
+	testingReviewRequestURL = "https://some-review-request-url"
+)
+
+// testingGitLab is a GitLab stand-in implementing the part of the REST API
+// that is used by the tracker. It records all modifying requests.
+type testingGitLab struct {
+	*httptest.Server
+
+	mu    sync.Mutex
+	issue *gitlab.Issue
+
+	unauthorized bool
+	notes        []string
+	labelUpdates []string
+}
+
+func newTestingGitLab(labels ...string) *testingGitLab {
+	gl := &testingGitLab{
+		issue: &gitlab.Issue{
+			Id:     1042,
+			Iid:    testingIssueIid,
+			Title:  "Do the thing",
+			State:  "opened",
+			Labels: labels,
+		},
+	}
+	gl.Server = httptest.NewServer(gl)
+	return gl
+}
+
+func (gl *testingGitLab) newIssueTracker() *issueTracker {
+	client, err := gitlab.NewClientWithToken(gl.URL, testingToken)
+	Expect(err).NotTo(HaveOccurred())
+	return &issueTracker{client}
+}
+
+func (gl *testingGitLab) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
+	gl.mu.Lock()
+	defer gl.mu.Unlock()
+
+	if r.Header.Get("PRIVATE-TOKEN") != testingToken {
+		gl.unauthorized = true
+		http.Error(rw, `{"message":"401 Unauthorized"}`, http.StatusUnauthorized)
+		return
+	}
+
+	issuePath := "/api/v4/projects/group%2Fsubgroup%2Fproject/issues/42"
+	switch {
+	case r.Method == "GET" && r.URL.EscapedPath() == issuePath:
+		writeJSON(rw, gl.issue)
+
+	case r.Method == "PUT" && r.URL.EscapedPath() == issuePath:
+		var body struct {
+			Labels string `json:"labels"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			http.Error(rw, `{"message":"400 Bad Request"}`, http.StatusBadRequest)
+			return
+		}
+		gl.issue.Labels = labelList(body.Labels)
+		gl.labelUpdates = append(gl.labelUpdates, body.Labels)
+		writeJSON(rw, gl.issue)
+
+	case r.Method == "POST" && r.URL.EscapedPath() == issuePath+"/notes":
+		var note gitlab.Note
+		if err := json.NewDecoder(r.Body).Decode(&note); err != nil {
+			http.Error(rw, `{"message":"400 Bad Request"}`, http.StatusBadRequest)
+			return
+		}
+		gl.notes = append(gl.notes, note.Body)
+		note.Id = 1
+		rw.Header().Set("Content-Type", "application/json")
+		rw.WriteHeader(http.StatusCreated)
+		json.NewEncoder(rw).Encode(&note)
+
+	default:
+		http.Error(rw, `{"message":"404 Not found"}`, http.StatusNotFound)
+	}
+}
+
+func writeJSON(rw http.ResponseWriter, v interface{}) {
+	rw.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(rw).Encode(v)
+}
+
+func labelList(labels string) []string {
+	if labels == "" {
+		return nil
+	}
+	return strings.Split(labels, ",")
+}
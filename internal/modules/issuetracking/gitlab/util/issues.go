@@ -0,0 +1,52 @@
+package util
+
+import (
+	// Stdlib
+	"strings"
+
+	// Internal
+	"github.com/salsaflow/salsaflow-daemon/internal/gitlab"
+	"github.com/salsaflow/salsaflow-daemon/internal/modules/issuetracking/gitlab/config"
+)
+
+// ReplaceWorkflowLabels is the GitLab counterpart of the function
+// of the same name in the GitHub Issues module. All labels in the workflow
+// scope except those listed in keep are removed, labels in add are added.
+func ReplaceWorkflowLabels(
+	client *gitlab.Client,
+	project string,
+	issue *gitlab.Issue,
+	add []string,
+	keep []string,
+) error {
+	// Get the list of labels to be used.
+	shouldKeep := func(label string) bool {
+		for _, keepName := range keep {
+			if keepName == label {
+				return true
+			}
+		}
+		return false
+	}
+
+	c := config.Get()
+	labelNames := make([]string, 0, len(issue.Labels)+len(add))
+	labelNames = append(labelNames, add...)
+	for _, name := range issue.Labels {
+		if shouldKeep(name) || !c.IsWorkflowLabel(name) {
+			labelNames = append(labelNames, name)
+		}
+	}
+
+	// Replace the labels.
+	labels := strings.Join(labelNames, ",")
+	updatedIssue, _, err := client.Issues.Edit(project, issue.Iid, &gitlab.IssueRequest{
+		Labels: &labels,
+	})
+	if err != nil {
+		return err
+	}
+
+	issue.Labels = updatedIssue.Labels
+	return nil
+}
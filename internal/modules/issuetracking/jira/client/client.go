@@ -0,0 +1,38 @@
+// Package client implements the tiny subset of the Jira REST API v2
+// that is needed by the Jira issue tracking module.
+package client
+
+import (
+	// Stdlib
+	"net/http"
+
+	// Internal
+	"github.com/salsaflow/salsaflow-daemon/internal/jsonapi"
+)
+
+type Client struct {
+	*jsonapi.Client
+
+	Issues *IssueService
+}
+
+// New returns a client for the Jira instance running at baseURL.
+//
+// When username is not empty, HTTP basic authentication is used with token
+// being the API token or password. Otherwise token is sent as a bearer token.
+func New(baseURL, username, token string) (*Client, error) {
+	apiClient, err := jsonapi.NewClient("Jira", baseURL, func(req *http.Request) {
+		if username != "" {
+			req.SetBasicAuth(username, token)
+		} else {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	client := &Client{Client: apiClient}
+	client.Issues = &IssueService{client}
+	return client, nil
+}
@@ -0,0 +1,130 @@
+package client
+
+import (
+	// Stdlib
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+type Issue struct {
+	Id     string       `json:"id"`
+	Key    string       `json:"key"`
+	Self   string       `json:"self"`
+	Fields *IssueFields `json:"fields"`
+}
+
+type IssueFields struct {
+	Summary string   `json:"summary"`
+	Labels  []string `json:"labels"`
+	Status  *Status  `json:"status"`
+}
+
+type Status struct {
+	Id   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type Comment struct {
+	Id   string `json:"id,omitempty"`
+	Body string `json:"body"`
+}
+
+type Transition struct {
+	Id   string  `json:"id"`
+	Name string  `json:"name"`
+	To   *Status `json:"to"`
+}
+
+type IssueService struct {
+	client *Client
+}
+
+func issuePath(key string, rest ...string) string {
+	path := fmt.Sprintf("rest/api/2/issue/%v", url.PathEscape(key))
+	for _, part := range rest {
+		path += "/" + part
+	}
+	return path
+}
+
+// Get returns the issue with the given key.
+func (srv *IssueService) Get(key string) (*Issue, *http.Response, error) {
+	req, err := srv.client.NewRequest("GET", issuePath(key)+"?fields=summary,labels,status", nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var issue Issue
+	resp, err := srv.client.Do(req, &issue)
+	if err != nil {
+		return nil, resp, err
+	}
+	return &issue, resp, nil
+}
+
+// SetLabels replaces the labels of the given issue.
+func (srv *IssueService) SetLabels(key string, labels []string) (*http.Response, error) {
+	if labels == nil {
+		labels = []string{}
+	}
+
+	body := map[string]interface{}{
+		"update": map[string]interface{}{
+			"labels": []interface{}{
+				map[string]interface{}{"set": labels},
+			},
+		},
+	}
+
+	req, err := srv.client.NewRequest("PUT", issuePath(key), body)
+	if err != nil {
+		return nil, err
+	}
+	return srv.client.Do(req, nil)
+}
+
+// AddComment adds a comment to the given issue.
+func (srv *IssueService) AddComment(key string, comment *Comment) (*Comment, *http.Response, error) {
+	req, err := srv.client.NewRequest("POST", issuePath(key, "comment"), comment)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var c Comment
+	resp, err := srv.client.Do(req, &c)
+	if err != nil {
+		return nil, resp, err
+	}
+	return &c, resp, nil
+}
+
+// ListTransitions returns the transitions available for the given issue.
+func (srv *IssueService) ListTransitions(key string) ([]*Transition, *http.Response, error) {
+	req, err := srv.client.NewRequest("GET", issuePath(key, "transitions"), nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var body struct {
+		Transitions []*Transition `json:"transitions"`
+	}
+	resp, err := srv.client.Do(req, &body)
+	if err != nil {
+		return nil, resp, err
+	}
+	return body.Transitions, resp, nil
+}
+
+// DoTransition applies the given transition to the given issue.
+func (srv *IssueService) DoTransition(key, transitionId string) (*http.Response, error) {
+	body := map[string]interface{}{
+		"transition": map[string]string{"id": transitionId},
+	}
+
+	req, err := srv.client.NewRequest("POST", issuePath(key, "transitions"), body)
+	if err != nil {
+		return nil, err
+	}
+	return srv.client.Do(req, nil)
+}
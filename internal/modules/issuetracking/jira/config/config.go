@@ -0,0 +1,61 @@
+package config
+
+import (
+	// Stdlib
+	"log"
+	"strings"
+
+	// Vendor
+	"github.com/kelseyhightower/envconfig"
+)
+
+type Config struct {
+	WebhookSecret string `envconfig:"WEBHOOK_SECRET"`
+
+	// BaseURL is the Jira instance URL, e.g. https://example.atlassian.net
+	BaseURL string `envconfig:"BASE_URL"`
+
+	// Username and Token are used for authentication. When Username is set,
+	// HTTP basic authentication is used with Token being the API token.
+	// Otherwise Token is sent as a bearer token (personal access token).
+	Username string `envconfig:"USERNAME"`
+	Token    string `envconfig:"TOKEN"`
+
+	// Workflow labels.
+	ReviewedLabel       string `envconfig:"REVIEWED_LABEL"        default:"reviewed"`
+	ReviewSkippedLabel  string `envconfig:"REVIEW_SKIPPED_LABEL"  default:"no review"`
+	TestingPassedLabel  string `envconfig:"TESTING_PASSED_LABEL"  default:"qa+"`
+	TestingFailedLabel  string `envconfig:"TESTING_FAILED_LABEL"  default:"qa-"`
+	TestingSkippedLabel string `envconfig:"TESTING_SKIPPED_LABEL" default:"no qa"`
+
+	// Workflow transitions. These are optional, when set, the transition
+	// of the given name is applied when the relevant event occurs,
+	// provided that the transition is available for the issue at that moment.
+	ImplementedTransition string `envconfig:"IMPLEMENTED_TRANSITION"`
+	ReviewedTransition    string `envconfig:"REVIEWED_TRANSITION"`
+
+	// Issues moved into one of these statuses are considered rejected
+	// and their workflow labels are pruned.
+	RejectedStatusList string `envconfig:"REJECTED_STATUSES" default:"Rejected,Reopened"`
+
+	// RejectedStatuses contains parsed RejectedStatusList.
+	RejectedStatuses []string
+}
+
+var config Config
+
+func init() {
+	if err := envconfig.Process("SFD_JIRA", &config); err != nil {
+		log.Fatalln("Fatal error while parsing Jira config:", err)
+	}
+
+	for _, status := range strings.Split(config.RejectedStatusList, ",") {
+		if status = strings.TrimSpace(status); status != "" {
+			config.RejectedStatuses = append(config.RejectedStatuses, status)
+		}
+	}
+}
+
+func Get() Config {
+	return config
+}
@@ -0,0 +1,64 @@
+package endpoint
+
+import (
+	// Stdlib
+	stdLog "log"
+	"net/http"
+
+	// Internal
+	httputil "github.com/salsaflow/salsaflow-daemon/internal/http"
+	"github.com/salsaflow/salsaflow-daemon/internal/idempotency"
+	module "github.com/salsaflow/salsaflow-daemon/internal/modules/issuetracking/jira"
+	"github.com/salsaflow/salsaflow-daemon/internal/modules/issuetracking/jira/config"
+	"github.com/salsaflow/salsaflow-daemon/internal/modules/issuetracking/jira/util"
+
+	// Vendor
+	"github.com/codegangsta/negroni"
+)
+
+// Jira webhooks are not signed, so the secret is passed in the webhook URL.
+const SecretQueryParameter = "secret"
+
+type Endpoint struct{}
+
+func NewEndpoint() *Endpoint {
+	return &Endpoint{}
+}
+
+func (ep *Endpoint) ModuleId() string {
+	return module.ModuleId
+}
+
+func (ep *Endpoint) NewAuthMiddleware() (negroni.Handler, error) {
+	secret := config.Get().WebhookSecret
+	if secret == "" {
+		stdLog.Println("WARNING: SFD_JIRA_WEBHOOK_SECRET is not set")
+		return nil, nil
+	}
+	return newSecretMiddleware(secret), nil
+}
+
+func (ep *Endpoint) NewHandler() (http.Handler, error) {
+	// Create a new mux.
+	mux := http.NewServeMux()
+
+	// Get the store used to skip redelivered webhooks.
+	store, err := idempotency.Default()
+	if err != nil {
+		return nil, err
+	}
+
+	// Handle /events
+	n := negroni.New()
+	n.Use(idempotency.NewMiddleware(
+		store, idempotencyNamespace, idempotency.HeaderKey("X-Atlassian-Webhook-Identifier")))
+	n.UseHandler(&eventHandler{util.NewClient, config.Get()})
+	mux.Handle("/events", n)
+
+	// Return the mux.
+	return mux, nil
+}
+
+func newSecretMiddleware(secret string) negroni.HandlerFunc {
+	return httputil.NewTokenMiddleware("Jira", secret, httputil.QueryToken(SecretQueryParameter))
+}
@@ -0,0 +1,208 @@
+package endpoint
+
+import (
+	// Stdlib
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	// Internal
+	"github.com/salsaflow/salsaflow-daemon/internal/modules/issuetracking/jira/client"
+	"github.com/salsaflow/salsaflow-daemon/internal/modules/issuetracking/jira/config"
+
+	// Vendor
+	"github.com/codegangsta/negroni"
+)
+
+const testingIssueKey = "SF-42"
+
+// testingJira is a Jira stand-in serving a single issue.
+// It records the label updates.
+type testingJira struct {
+	*httptest.Server
+
+	mu           sync.Mutex
+	labels       []string
+	labelUpdates [][]string
+}
+
+func newTestingJira(labels ...string) *testingJira {
+	jira := &testingJira{labels: labels}
+	jira.Server = httptest.NewServer(jira)
+	return jira
+}
+
+func (jira *testingJira) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
+	jira.mu.Lock()
+	defer jira.mu.Unlock()
+
+	issuePath := "/rest/api/2/issue/" + testingIssueKey
+	switch {
+	case r.Method == "GET" && r.URL.Path == issuePath:
+		rw.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(rw).Encode(&client.Issue{
+			Key:    testingIssueKey,
+			Fields: &client.IssueFields{Labels: jira.labels},
+		})
+
+	case r.Method == "PUT" && r.URL.Path == issuePath:
+		var body struct {
+			Update struct {
+				Labels []struct {
+					Set []string `json:"set"`
+				} `json:"labels"`
+			} `json:"update"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil || len(body.Update.Labels) != 1 {
+			http.Error(rw, "Bad Request", http.StatusBadRequest)
+			return
+		}
+		jira.labels = body.Update.Labels[0].Set
+		jira.labelUpdates = append(jira.labelUpdates, jira.labels)
+		rw.WriteHeader(http.StatusNoContent)
+
+	default:
+		http.Error(rw, "Not Found", http.StatusNotFound)
+	}
+}
+
+func (jira *testingJira) newEventHandler() *eventHandler {
+	return &eventHandler{
+		newClient: func() (*client.Client, error) {
+			return client.New(jira.URL, "", "t0k3n")
+		},
+		config: config.Config{
+			ReviewedLabel:       "reviewed",
+			ReviewSkippedLabel:  "no review",
+			TestingPassedLabel:  "qa+",
+			TestingFailedLabel:  "qa-",
+			TestingSkippedLabel: "no qa",
+			RejectedStatuses:    []string{"Rejected", "Reopened"},
+		},
+	}
+}
+
+func newTestingEvent(webhookEvent, field, toString string) string {
+	return `{
+		"webhookEvent": "` + webhookEvent + `",
+		"issue": {"key": "` + testingIssueKey + `"},
+		"changelog": {"items": [{"field": "` + field + `", "toString": "` + toString + `"}]}
+	}`
+}
+
+func TestSecretMiddleware(t *testing.T) {
+	n := negroni.New()
+	n.Use(newSecretMiddleware("s3cr3t"))
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.WriteHeader(http.StatusAccepted)
+	})
+
+	data := []struct {
+		query  string
+		status int
+	}{
+		{"?secret=s3cr3t", http.StatusAccepted},
+		{"?secret=unknown", http.StatusUnauthorized},
+		{"?secret=", http.StatusUnauthorized},
+		{"", http.StatusUnauthorized},
+	}
+	for _, d := range data {
+		body := newTestingEvent("jira:issue_updated", "status", "Rejected")
+		r := httptest.NewRequest("POST", "/events"+d.query, strings.NewReader(body))
+		rw := httptest.NewRecorder()
+		n.ServeHTTP(rw, r)
+
+		if rw.Code != d.status {
+			t.Errorf("query %q: expected status %v, got %v", d.query, d.status, rw.Code)
+		}
+	}
+}
+
+func TestEventHandler(t *testing.T) {
+	data := []struct {
+		name     string
+		event    string
+		expected []string
+	}{
+		{
+			"issue rejected",
+			newTestingEvent("jira:issue_updated", "status", "Rejected"),
+			[]string{"backend"},
+		},
+		{
+			"issue reopened",
+			newTestingEvent("jira:issue_updated", "status", "reopened"),
+			[]string{"backend"},
+		},
+		{
+			"issue moved to another status",
+			newTestingEvent("jira:issue_updated", "status", "In Progress"),
+			nil,
+		},
+		{
+			"another field changed",
+			newTestingEvent("jira:issue_updated", "resolution", "Rejected"),
+			nil,
+		},
+		{
+			"another event",
+			newTestingEvent("jira:issue_created", "status", "Rejected"),
+			nil,
+		},
+	}
+
+	for _, d := range data {
+		jira := newTestingJira("reviewed", "backend", "qa+", "no review")
+
+		r := httptest.NewRequest("POST", "/events", strings.NewReader(d.event))
+		rw := httptest.NewRecorder()
+		jira.newEventHandler().ServeHTTP(rw, r)
+		jira.Close()
+
+		if rw.Code != http.StatusAccepted {
+			t.Errorf("%v: expected status %v, got %v", d.name, http.StatusAccepted, rw.Code)
+		}
+
+		switch {
+		case d.expected == nil && len(jira.labelUpdates) != 0:
+			t.Errorf("%v: expected no label updates, got %q", d.name, jira.labelUpdates)
+		case d.expected != nil && !reflect.DeepEqual(jira.labelUpdates, [][]string{d.expected}):
+			t.Errorf("%v: expected labels to be set to %q, got %q", d.name, d.expected, jira.labelUpdates)
+		}
+	}
+}
+
+func TestEventHandler_NoWorkflowLabels(t *testing.T) {
+	jira := newTestingJira("backend")
+	defer jira.Close()
+
+	event := newTestingEvent("jira:issue_updated", "status", "Rejected")
+	r := httptest.NewRequest("POST", "/events", strings.NewReader(event))
+	rw := httptest.NewRecorder()
+	jira.newEventHandler().ServeHTTP(rw, r)
+
+	if rw.Code != http.StatusAccepted {
+		t.Errorf("expected status %v, got %v", http.StatusAccepted, rw.Code)
+	}
+	if len(jira.labelUpdates) != 0 {
+		t.Errorf("expected no label updates, got %q", jira.labelUpdates)
+	}
+}
+
+func TestEventHandler_APIError(t *testing.T) {
+	jira := newTestingJira("reviewed")
+	jira.Close()
+
+	event := newTestingEvent("jira:issue_updated", "status", "Rejected")
+	r := httptest.NewRequest("POST", "/events", strings.NewReader(event))
+	rw := httptest.NewRecorder()
+	jira.newEventHandler().ServeHTTP(rw, r)
+
+	if rw.Code != http.StatusInternalServerError {
+		t.Errorf("expected status %v, got %v", http.StatusInternalServerError, rw.Code)
+	}
+}
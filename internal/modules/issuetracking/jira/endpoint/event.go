@@ -0,0 +1,83 @@
+package endpoint
+
+import (
+	// Stdlib
+	"encoding/json"
+	"net/http"
+
+	// Internal
+	httputil "github.com/salsaflow/salsaflow-daemon/internal/http"
+	"github.com/salsaflow/salsaflow-daemon/internal/log"
+	"github.com/salsaflow/salsaflow-daemon/internal/metrics"
+	"github.com/salsaflow/salsaflow-daemon/internal/modules/issuetracking/jira/client"
+	"github.com/salsaflow/salsaflow-daemon/internal/modules/issuetracking/jira/config"
+)
+
+// Event represents a Jira webhook payload.
+type Event struct {
+	WebhookEvent string        `json:"webhookEvent"`
+	Issue        *client.Issue `json:"issue"`
+	Changelog    *Changelog    `json:"changelog"`
+}
+
+type Changelog struct {
+	Items []*ChangelogItem `json:"items"`
+}
+
+type ChangelogItem struct {
+	Field      string `json:"field"`
+	FromString string `json:"fromString"`
+	ToString   string `json:"toString"`
+}
+
+// eventHandler handles Jira webhooks. The Jira API client is created
+// only for the events that need it, using newClient.
+type eventHandler struct {
+	newClient func() (*client.Client, error)
+	config    config.Config
+}
+
+type issueUpdatedHandlerFunc func(
+	handler *eventHandler, r *http.Request, issue *client.Issue, change *ChangelogItem) error
+
+var issueUpdatedHandlers = []issueUpdatedHandlerFunc{
+	(*eventHandler).handleRejectedIssues,
+}
+
+// idempotencyNamespace is the idempotency.Store namespace used for Jira webhook IDs.
+// Jira Cloud sends a unique identifier with every webhook.
+const idempotencyNamespace = "jira"
+
+func (handler *eventHandler) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
+	var errorOccured bool
+
+	// Decode the event object.
+	var event Event
+	if err := json.NewDecoder(r.Body).Decode(&event); err != nil {
+		httputil.Error(rw, r, err)
+		return
+	}
+
+	// We only care about issue updates.
+	if event.WebhookEvent != "jira:issue_updated" || event.Issue == nil || event.Changelog == nil {
+		metrics.Skip(r)
+		httputil.Status(rw, http.StatusAccepted)
+		return
+	}
+
+	// Process the changes.
+	for _, change := range event.Changelog.Items {
+		for _, handle := range issueUpdatedHandlers {
+			if err := handle(handler, r, event.Issue, change); err != nil {
+				log.Error(r, err)
+				errorOccured = true
+			}
+		}
+	}
+
+	if errorOccured {
+		httputil.Status(rw, http.StatusInternalServerError)
+	} else {
+		httputil.Status(rw, http.StatusAccepted)
+	}
+}
@@ -0,0 +1,76 @@
+package endpoint
+
+import (
+	// Stdlib
+	"net/http"
+	"strings"
+
+	// Internal
+	"github.com/salsaflow/salsaflow-daemon/internal/log"
+	"github.com/salsaflow/salsaflow-daemon/internal/modules/issuetracking/jira/client"
+	"github.com/salsaflow/salsaflow-daemon/internal/modules/issuetracking/jira/config"
+)
+
+func (handler *eventHandler) handleRejectedIssues(
+	r *http.Request,
+	issue *client.Issue,
+	change *ChangelogItem,
+) error {
+
+	// Check whether we want to process this change or not.
+	cfg := handler.config
+	if change.Field != "status" || !isRejectedStatus(change.ToString, cfg) {
+		return nil
+	}
+
+	// Fetch the issue resource, the labels in the payload can be outdated.
+	c, err := handler.newClient()
+	if err != nil {
+		return err
+	}
+
+	key := issue.Key
+	issue, _, err = c.Issues.Get(key)
+	if err != nil {
+		return err
+	}
+	if issue.Fields == nil {
+		return nil
+	}
+
+	// Drop relevant labels.
+	newLabels := make([]string, 0, len(issue.Fields.Labels))
+	for _, label := range issue.Fields.Labels {
+		switch label {
+		case cfg.ReviewedLabel:
+		case cfg.ReviewSkippedLabel:
+		case cfg.TestingPassedLabel:
+		case cfg.TestingFailedLabel:
+		case cfg.TestingSkippedLabel:
+		default:
+			newLabels = append(newLabels, label)
+		}
+	}
+
+	// No change, we are done.
+	if len(newLabels) == len(issue.Fields.Labels) {
+		return nil
+	}
+
+	// Update the issue.
+	if _, err := c.Issues.SetLabels(key, newLabels); err != nil {
+		return err
+	}
+
+	log.Info(r, "Jira: issue %v moved to %v, pruned the workflow labels", key, change.ToString)
+	return nil
+}
+
+func isRejectedStatus(status string, cfg config.Config) bool {
+	for _, rejected := range cfg.RejectedStatuses {
+		if strings.EqualFold(status, rejected) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,3 @@
+package module
+
+const ModuleId = "salsaflow.modules.issuetracking.jira"
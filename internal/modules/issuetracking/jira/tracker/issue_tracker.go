@@ -0,0 +1,68 @@
+package tracker
+
+import (
+	// Stdlib
+	"fmt"
+	"net/http"
+	"regexp"
+
+	// Internal
+	"github.com/salsaflow/salsaflow-daemon/internal/modules/common"
+	"github.com/salsaflow/salsaflow-daemon/internal/modules/issuetracking/jira/client"
+	"github.com/salsaflow/salsaflow-daemon/internal/modules/issuetracking/jira/config"
+	"github.com/salsaflow/salsaflow-daemon/internal/modules/issuetracking/jira/util"
+)
+
+const Id = "JIRA"
+
+type issueService interface {
+	Get(key string) (*client.Issue, *http.Response, error)
+	SetLabels(key string, labels []string) (*http.Response, error)
+	AddComment(key string, comment *client.Comment) (*client.Comment, *http.Response, error)
+	ListTransitions(key string) ([]*client.Transition, *http.Response, error)
+	DoTransition(key, transitionId string) (*http.Response, error)
+}
+
+type issueTracker struct {
+	issues issueService
+	config config.Config
+}
+
+func Factory() (common.IssueTracker, error) {
+	c, err := util.NewClient()
+	if err != nil {
+		return nil, err
+	}
+
+	return &issueTracker{
+		issues: c.Issues,
+		config: config.Get(),
+	}, nil
+}
+
+func (tracker *issueTracker) FindStoryByTag(storyTag string) (common.Story, error) {
+	key, err := parseStoryTag(storyTag)
+	if err != nil {
+		return nil, err
+	}
+
+	issue, _, err := tracker.issues.Get(key)
+	if err != nil {
+		return nil, err
+	}
+	if issue.Fields == nil {
+		issue.Fields = &client.IssueFields{}
+	}
+
+	return &commonStory{tracker.issues, &tracker.config, issue}, nil
+}
+
+// The format is PROJECT-123
+var storyTagRegexp = regexp.MustCompile("^[A-Z][A-Z0-9_]*-[1-9][0-9]*$")
+
+func parseStoryTag(storyTag string) (key string, err error) {
+	if !storyTagRegexp.MatchString(storyTag) {
+		return "", fmt.Errorf("Jira: malformed story tag: %v", storyTag)
+	}
+	return storyTag, nil
+}
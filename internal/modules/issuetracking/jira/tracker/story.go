@@ -0,0 +1,145 @@
+package tracker
+
+import (
+	// Stdlib
+	"fmt"
+	"strings"
+
+	// Internal
+	"github.com/salsaflow/salsaflow-daemon/internal/modules/issuetracking/jira/client"
+	"github.com/salsaflow/salsaflow-daemon/internal/modules/issuetracking/jira/config"
+)
+
+type commonStory struct {
+	issues issueService
+	config *config.Config
+	issue  *client.Issue
+}
+
+func (s *commonStory) OnReviewRequestOpened(rrID, rrURL string) error {
+	return s.addComment(fmt.Sprintf("Review request [#%v|%v] opened.", rrID, rrURL))
+}
+
+func (s *commonStory) OnReviewRequestClosed(rrID, rrURL string) error {
+	return nil
+}
+
+func (s *commonStory) OnReviewRequestReopened(rrID, rrURL string) error {
+	// Drop 'reviewed', 'no review' and 'qa-'.
+	labels, changed := filterLabels(s.issue.Fields.Labels, func(label string) bool {
+		switch label {
+		case s.config.ReviewedLabel:
+		case s.config.ReviewSkippedLabel:
+		case s.config.TestingFailedLabel:
+		default:
+			return true
+		}
+		return false
+	})
+	if changed {
+		if err := s.setLabels(labels); err != nil {
+			return err
+		}
+	}
+
+	// Move the issue back to implemented when configured to do so.
+	return s.transition(s.config.ImplementedTransition)
+}
+
+func (s *commonStory) MarkAsReviewed() error {
+	// Drop 'no review' and 'qa-' and append 'reviewed'.
+	var hasReviewed bool
+	labels, changed := filterLabels(s.issue.Fields.Labels, func(label string) bool {
+		switch label {
+		case s.config.ReviewedLabel:
+			hasReviewed = true
+			return true
+		case s.config.ReviewSkippedLabel:
+		case s.config.TestingFailedLabel:
+		default:
+			return true
+		}
+		return false
+	})
+	if !hasReviewed {
+		labels = append(labels, s.config.ReviewedLabel)
+		changed = true
+	}
+	if changed {
+		if err := s.setLabels(labels); err != nil {
+			return err
+		}
+	}
+
+	// Move the issue to reviewed when configured to do so.
+	return s.transition(s.config.ReviewedTransition)
+}
+
+func (s *commonStory) OnReviewBlockerOpened(blockerURL, summary string) error {
+	if err := s.addComment(fmt.Sprintf("Review blocker [opened|%v]: %v", blockerURL, summary)); err != nil {
+		return err
+	}
+
+	// The story is not reviewed any more, drop 'reviewed'.
+	labels, changed := filterLabels(s.issue.Fields.Labels, func(label string) bool {
+		return label != s.config.ReviewedLabel
+	})
+	if !changed {
+		return nil
+	}
+	if err := s.setLabels(labels); err != nil {
+		return err
+	}
+
+	// Move the issue back to implemented when configured to do so.
+	return s.transition(s.config.ImplementedTransition)
+}
+
+func (s *commonStory) OnReviewBlockerFixed(blockerURL, summary string) error {
+	return s.addComment(fmt.Sprintf("Review blocker [fixed|%v]: %v", blockerURL, summary))
+}
+
+func (s *commonStory) addComment(text string) error {
+	_, _, err := s.issues.AddComment(s.issue.Key, &client.Comment{Body: text})
+	return err
+}
+
+func (s *commonStory) setLabels(labels []string) error {
+	if _, err := s.issues.SetLabels(s.issue.Key, labels); err != nil {
+		return err
+	}
+	s.issue.Fields.Labels = labels
+	return nil
+}
+
+// transition applies the transition of the given name.
+// Nothing happens in case the name is empty or the transition is not available,
+// which is usually the case when the issue is in the target state already.
+func (s *commonStory) transition(name string) error {
+	if name == "" {
+		return nil
+	}
+
+	transitions, _, err := s.issues.ListTransitions(s.issue.Key)
+	if err != nil {
+		return err
+	}
+
+	for _, t := range transitions {
+		if strings.EqualFold(t.Name, name) {
+			_, err := s.issues.DoTransition(s.issue.Key, t.Id)
+			return err
+		}
+	}
+	return nil
+}
+
+func filterLabels(labels []string, filterFunc func(string) bool) ([]string, bool) {
+	ls := make([]string, 0, len(labels))
+	for _, label := range labels {
+		if filterFunc(label) {
+			ls = append(ls, label)
+		}
+	}
+	return ls, len(ls) != len(labels)
+}
@@ -0,0 +1,305 @@
+package tracker
+
+import (
+	// Stdlib
+	"fmt"
+
+	// Internal
+	"github.com/salsaflow/salsaflow-daemon/internal/modules/issuetracking/jira/client"
+	"github.com/salsaflow/salsaflow-daemon/internal/modules/issuetracking/jira/config"
+)
+
+var testingConfig = config.Config{
+	ReviewedLabel:       "reviewed",
+	ReviewSkippedLabel:  "no review",
+	TestingPassedLabel:  "qa+",
+	TestingFailedLabel:  "qa-",
+	TestingSkippedLabel: "no qa",
+}
+
+var _ = Describe("Finding a story by tag", func() {
+
+	var jira *testingJira
+
+	BeforeEach(func() {
+		jira = newTestingJira()
+	})
+
+	AfterEach(func() {
+		jira.Close()
+	})
+
+	It("returns the issue with the given key", func() {
+		story, err := jira.newIssueTracker(testingConfig).FindStoryByTag(testingIssueKey)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(story.(*commonStory).issue.Key).To(Equal(testingIssueKey))
+		Expect(jira.unauthorized).To(Equal(false))
+	})
+
+	It("fails for a malformed story tag", func() {
+		_, err := jira.newIssueTracker(testingConfig).FindStoryByTag("123/stories/456")
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("fails for an unknown issue", func() {
+		_, err := jira.newIssueTracker(testingConfig).FindStoryByTag("SF-43")
+		Expect(err).To(HaveOccurred())
+	})
+})
+
+var _ = Describe("Invoking OnReviewRequestOpened story event handler", func() {
+
+	var jira *testingJira
+
+	BeforeEach(func() {
+		jira = newTestingJira()
+	})
+
+	AfterEach(func() {
+		jira.Close()
+	})
+
+	It("should result in a comment being added to the relevant issue", func() {
+		story, err := jira.newIssueTracker(testingConfig).FindStoryByTag(testingIssueKey)
+		Expect(err).NotTo(HaveOccurred())
+
+		err = story.OnReviewRequestOpened(testingReviewRequestId, testingReviewRequestURL)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(jira.comments).To(Equal([]string{
+			fmt.Sprintf("Review request [#%v|%v] opened.",
+				testingReviewRequestId, testingReviewRequestURL),
+		}))
+	})
+})
+
+var _ = Describe("Invoking OnReviewRequestReopened story event handler", func() {
+
+	data := []struct {
+		labels      string
+		update      string
+		transitions bool
+	}{
+		{"", "", false},
+		{"other", "", false},
+		{"reviewed,other", "other", false},
+		{"no review,other", "other", false},
+		{"qa-,other", "other", false},
+		{"qa+,other", "", false},
+		{"reviewed,no qa", "no qa", true},
+		{"other", "", true},
+	}
+
+	for i := range data {
+		func(i int) {
+			td := data[i]
+			ctx := fmt.Sprintf("labels=%q, update=%q, transitions=%v", td.labels, td.update, td.transitions)
+
+			Context(ctx, func() {
+
+				var jira *testingJira
+
+				BeforeEach(func() {
+					jira = newTestingJira(labelList(td.labels)...)
+				})
+
+				AfterEach(func() {
+					jira.Close()
+				})
+
+				It("sends out the expected requests", func() {
+					cfg := testingConfig
+					if td.transitions {
+						cfg.ImplementedTransition = "Implemented"
+						jira.transitions = []*client.Transition{
+							{Id: "11", Name: "Start Progress"},
+							{Id: "21", Name: "implemented"},
+						}
+					}
+
+					story, err := jira.newIssueTracker(cfg).FindStoryByTag(testingIssueKey)
+					Expect(err).NotTo(HaveOccurred())
+
+					err = story.OnReviewRequestReopened(testingReviewRequestId, testingReviewRequestURL)
+					Expect(err).NotTo(HaveOccurred())
+
+					if td.update == "" {
+						Expect(jira.labelUpdates).To(BeEmpty())
+					} else {
+						Expect(jira.labelUpdates).To(Equal([][]string{labelList(td.update)}))
+					}
+
+					if td.transitions {
+						Expect(jira.appliedTransitions).To(Equal([]string{"21"}))
+					} else {
+						Expect(jira.appliedTransitions).To(BeEmpty())
+					}
+				})
+			})
+		}(i)
+	}
+})
+
+var _ = Describe("Calling commonStory.MarkAsReviewed", func() {
+
+	data := []struct {
+		labels      string
+		update      string
+		transitions bool
+	}{
+		{"", "reviewed", false},
+		{"reviewed", "", false},
+		{"other", "other,reviewed", false},
+		{"no review,other", "other,reviewed", false},
+		{"qa-,other", "other,reviewed", false},
+		{"qa+,other", "qa+,other,reviewed", false},
+		{"no qa,reviewed", "", true},
+	}
+
+	for i := range data {
+		func(i int) {
+			td := data[i]
+			ctx := fmt.Sprintf("labels=%q, update=%q, transitions=%v", td.labels, td.update, td.transitions)
+
+			Context(ctx, func() {
+
+				var jira *testingJira
+
+				BeforeEach(func() {
+					jira = newTestingJira(labelList(td.labels)...)
+				})
+
+				AfterEach(func() {
+					jira.Close()
+				})
+
+				It("sends out the expected requests", func() {
+					cfg := testingConfig
+					if td.transitions {
+						cfg.ReviewedTransition = "Reviewed"
+						jira.transitions = []*client.Transition{
+							{Id: "31", Name: "Reviewed"},
+						}
+					}
+
+					story, err := jira.newIssueTracker(cfg).FindStoryByTag(testingIssueKey)
+					Expect(err).NotTo(HaveOccurred())
+
+					err = story.MarkAsReviewed()
+					Expect(err).NotTo(HaveOccurred())
+
+					if td.update == "" {
+						Expect(jira.labelUpdates).To(BeEmpty())
+					} else {
+						Expect(jira.labelUpdates).To(Equal([][]string{labelList(td.update)}))
+					}
+
+					if td.transitions {
+						Expect(jira.appliedTransitions).To(Equal([]string{"31"}))
+					} else {
+						Expect(jira.appliedTransitions).To(BeEmpty())
+					}
+				})
+			})
+		}(i)
+	}
+})
+
+var _ = Describe("Invoking OnReviewBlockerOpened story event handler", func() {
+
+	const (
+		blockerURL     = "https://some-blocker-url"
+		blockerSummary = "Fix the typo"
+	)
+
+	data := []struct {
+		labels      string
+		update      string
+		transitions bool
+	}{
+		{"", "", false},
+		{"other", "", true},
+		{"reviewed,other", "other", false},
+		{"reviewed,qa+", "qa+", true},
+	}
+
+	for i := range data {
+		func(i int) {
+			td := data[i]
+			ctx := fmt.Sprintf("labels=%q, update=%q, transitions=%v", td.labels, td.update, td.transitions)
+
+			Context(ctx, func() {
+
+				var jira *testingJira
+
+				BeforeEach(func() {
+					jira = newTestingJira(labelList(td.labels)...)
+				})
+
+				AfterEach(func() {
+					jira.Close()
+				})
+
+				It("adds the comment and sends out the expected requests", func() {
+					cfg := testingConfig
+					if td.transitions {
+						cfg.ImplementedTransition = "Implemented"
+						jira.transitions = []*client.Transition{
+							{Id: "21", Name: "Implemented"},
+						}
+					}
+
+					story, err := jira.newIssueTracker(cfg).FindStoryByTag(testingIssueKey)
+					Expect(err).NotTo(HaveOccurred())
+
+					err = story.OnReviewBlockerOpened(blockerURL, blockerSummary)
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(jira.comments).To(Equal([]string{
+						fmt.Sprintf("Review blocker [opened|%v]: %v", blockerURL, blockerSummary),
+					}))
+
+					if td.update == "" {
+						Expect(jira.labelUpdates).To(BeEmpty())
+					} else {
+						Expect(jira.labelUpdates).To(Equal([][]string{labelList(td.update)}))
+					}
+
+					// The issue is moved back only when it was reviewed.
+					if td.transitions && td.update != "" {
+						Expect(jira.appliedTransitions).To(Equal([]string{"21"}))
+					} else {
+						Expect(jira.appliedTransitions).To(BeEmpty())
+					}
+				})
+			})
+		}(i)
+	}
+})
+
+var _ = Describe("Invoking OnReviewBlockerFixed story event handler", func() {
+
+	var jira *testingJira
+
+	BeforeEach(func() {
+		jira = newTestingJira("implemented")
+	})
+
+	AfterEach(func() {
+		jira.Close()
+	})
+
+	It("only adds the comment", func() {
+		story, err := jira.newIssueTracker(testingConfig).FindStoryByTag(testingIssueKey)
+		Expect(err).NotTo(HaveOccurred())
+
+		err = story.OnReviewBlockerFixed("https://some-blocker-url", "Fix the typo")
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(jira.comments).To(Equal([]string{
+			"Review blocker [fixed|https://some-blocker-url]: Fix the typo",
+		}))
+		Expect(jira.labelUpdates).To(BeEmpty())
+		Expect(jira.appliedTransitions).To(BeEmpty())
+	})
+})
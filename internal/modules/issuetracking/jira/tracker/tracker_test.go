@@ -0,0 +1,164 @@
+package tracker
+
+import (
+	// Stdlib
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	// Internal
+	"github.com/salsaflow/salsaflow-daemon/internal/modules/issuetracking/jira/client"
+	"github.com/salsaflow/salsaflow-daemon/internal/modules/issuetracking/jira/config"
+
+	// Vendor
+	"github.com/onsi/ginkgo"
+	"github.com/onsi/gomega"
+)
+
+// Set up Ginkgo and Gomega ----------------------------------------------------
+
+func TestIssueTracker(t *testing.T) {
+	gomega.RegisterFailHandler(ginkgo.Fail)
+	ginkgo.RunSpecs(t, "Jira Tracker Suite")
+}
+
+// Testing imports -------------------------------------------------------------
+
+var (
+	AfterEach  = ginkgo.AfterEach
+	BeforeEach = ginkgo.BeforeEach
+	Context    = ginkgo.Context
+	Describe   = ginkgo.Describe
+	It         = ginkgo.It
+
+	BeEmpty      = gomega.BeEmpty
+	BeNil        = gomega.BeNil
+	Equal        = gomega.Equal
+	Expect       = gomega.Expect
+	HaveOccurred = gomega.HaveOccurred
+)
+
+// Shared testing infrastructure -----------------------------------------------
+
+const (
+	testingIssueKey         = "SF-42"
+	testingUsername         = "salsaflow"
+	testingToken            = "t0k3n"
+	testingReviewRequestId  = "10"
+	testingReviewRequestURL = "https://some-review-request-url"
+)
+
+// testingJira is a Jira stand-in implementing the part of the REST API
+// that is used by the tracker. It records all modifying requests.
+type testingJira struct {
+	*httptest.Server
+
+	mu          sync.Mutex
+	issue       *client.Issue
+	transitions []*client.Transition
+
+	unauthorized       bool
+	comments           []string
+	labelUpdates       [][]string
+	appliedTransitions []string
+}
+
+func newTestingJira(labels ...string) *testingJira {
+	jira := &testingJira{
+		issue: &client.Issue{
+			Id:  "10042",
+			Key: testingIssueKey,
+			Fields: &client.IssueFields{
+				Summary: "Do the thing",
+				Labels:  labels,
+			},
+		},
+	}
+	jira.Server = httptest.NewServer(jira)
+	return jira
+}
+
+func (jira *testingJira) newIssueTracker(cfg config.Config) *issueTracker {
+	c, err := client.New(jira.URL, testingUsername, testingToken)
+	Expect(err).NotTo(HaveOccurred())
+	return &issueTracker{c.Issues, cfg}
+}
+
+func (jira *testingJira) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
+	jira.mu.Lock()
+	defer jira.mu.Unlock()
+
+	if username, token, ok := r.BasicAuth(); !ok || username != testingUsername || token != testingToken {
+		jira.unauthorized = true
+		http.Error(rw, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	issuePath := "/rest/api/2/issue/" + testingIssueKey
+	switch {
+	case r.Method == "GET" && r.URL.Path == issuePath:
+		writeJSON(rw, jira.issue)
+
+	case r.Method == "PUT" && r.URL.Path == issuePath:
+		var body struct {
+			Update struct {
+				Labels []struct {
+					Set []string `json:"set"`
+				} `json:"labels"`
+			} `json:"update"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil || len(body.Update.Labels) != 1 {
+			http.Error(rw, "Bad Request", http.StatusBadRequest)
+			return
+		}
+		labels := body.Update.Labels[0].Set
+		jira.issue.Fields.Labels = labels
+		jira.labelUpdates = append(jira.labelUpdates, labels)
+		rw.WriteHeader(http.StatusNoContent)
+
+	case r.Method == "POST" && r.URL.Path == issuePath+"/comment":
+		var comment client.Comment
+		if err := json.NewDecoder(r.Body).Decode(&comment); err != nil {
+			http.Error(rw, "Bad Request", http.StatusBadRequest)
+			return
+		}
+		jira.comments = append(jira.comments, comment.Body)
+		comment.Id = "1"
+		rw.WriteHeader(http.StatusCreated)
+		writeJSON(rw, &comment)
+
+	case r.Method == "GET" && r.URL.Path == issuePath+"/transitions":
+		writeJSON(rw, map[string]interface{}{"transitions": jira.transitions})
+
+	case r.Method == "POST" && r.URL.Path == issuePath+"/transitions":
+		var body struct {
+			Transition struct {
+				Id string `json:"id"`
+			} `json:"transition"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			http.Error(rw, "Bad Request", http.StatusBadRequest)
+			return
+		}
+		jira.appliedTransitions = append(jira.appliedTransitions, body.Transition.Id)
+		rw.WriteHeader(http.StatusNoContent)
+
+	default:
+		http.Error(rw, `{"errorMessages":["Issue Does Not Exist"]}`, http.StatusNotFound)
+	}
+}
+
+func writeJSON(rw http.ResponseWriter, v interface{}) {
+	rw.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(rw).Encode(v)
+}
+
+func labelList(labels string) []string {
+	if labels == "" {
+		return nil
+	}
+	return strings.Split(labels, ",")
+}
@@ -0,0 +1,24 @@
+package util
+
+import (
+	// Internal
+	"github.com/salsaflow/salsaflow-daemon/internal/errs"
+	"github.com/salsaflow/salsaflow-daemon/internal/modules/issuetracking/jira/client"
+	"github.com/salsaflow/salsaflow-daemon/internal/modules/issuetracking/jira/config"
+)
+
+// NewClient returns a new Jira API client
+// that uses the credentials read from the environment.
+//
+// An error is returned in case the relevant environment variables are not set.
+func NewClient() (*client.Client, error) {
+	c := config.Get()
+	switch {
+	case c.BaseURL == "":
+		return nil, &errs.ErrVarNotSet{VariableName: "SFD_JIRA_BASE_URL"}
+	case c.Token == "":
+		return nil, &errs.ErrVarNotSet{VariableName: "SFD_JIRA_TOKEN"}
+	}
+
+	return client.New(c.BaseURL, c.Username, c.Token)
+}
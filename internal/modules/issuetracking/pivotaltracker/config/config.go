@@ -3,15 +3,22 @@ package config
 import (
 	// Stdlib
 	"log"
+	"strings"
 
 	// Vendor
 	"github.com/kelseyhightower/envconfig"
 )
 
 type Config struct {
+	// WebhookSecret can contain a comma-separated list of secrets,
+	// all of them being accepted. This makes it possible to rotate secrets.
 	WebhookSecret string `envconfig:"WEBHOOK_SECRET"`
 	Token         string `envconfig:"TOKEN"`
 
+	// VerifyActivity makes the endpoint ignore the secret and verify
+	// every activity by fetching it from the Pivotal Tracker API instead.
+	VerifyActivity bool `envconfig:"VERIFY_ACTIVITY"`
+
 	ReviewedLabel       string `envconfig:"REVIEWED_LABEL"        default:"reviewed"`
 	ReviewSkippedLabel  string `envconfig:"EVIEW_SKIPPED_LABEL"  default:"no review"`
 	TestingPassedLabel  string `envconfig:"TESTING_PASSED_LABEL"  default:"qa+"`
@@ -30,3 +37,14 @@ func init() {
 func Get() Config {
 	return config
 }
+
+// WebhookSecrets returns the list of accepted webhook secrets.
+func (c Config) WebhookSecrets() []string {
+	var secrets []string
+	for _, secret := range strings.Split(c.WebhookSecret, ",") {
+		if secret = strings.TrimSpace(secret); secret != "" {
+			secrets = append(secrets, secret)
+		}
+	}
+	return secrets
+}
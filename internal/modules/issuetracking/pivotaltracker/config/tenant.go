@@ -0,0 +1,78 @@
+package config
+
+import (
+	// Internal
+	"github.com/salsaflow/salsaflow-daemon/internal/tenants"
+)
+
+// TenantSectionKey is the key of the tenant label scheme section
+// containing the Pivotal Tracker labels.
+const TenantSectionKey = "pivotaltracker"
+
+type tenantLabels struct {
+	ReviewedLabel       string `json:"reviewed"`
+	ReviewSkippedLabel  string `json:"review_skipped"`
+	TestingPassedLabel  string `json:"testing_passed"`
+	TestingFailedLabel  string `json:"testing_failed"`
+	TestingSkippedLabel string `json:"testing_skipped"`
+}
+
+// ForProject returns the configuration to be used for the given project.
+// The credentials and labels of the tenant the project belongs to take precedence,
+// the values read from the environment are used for the rest.
+func ForProject(projectId int) (Config, error) {
+	return forTenant(Get(), tenants.ForPivotalTrackerProject(projectId))
+}
+
+func forTenant(c Config, t *tenants.Tenant) (Config, error) {
+	if t == nil {
+		return c, nil
+	}
+
+	set := func(dst *string, src string) {
+		if src != "" {
+			*dst = src
+		}
+	}
+
+	set(&c.Token, t.PivotalTracker.Token)
+
+	var labels tenantLabels
+	ok, err := t.Section(TenantSectionKey, &labels)
+	if err != nil || !ok {
+		return c, err
+	}
+
+	set(&c.ReviewedLabel, labels.ReviewedLabel)
+	set(&c.ReviewSkippedLabel, labels.ReviewSkippedLabel)
+	set(&c.TestingPassedLabel, labels.TestingPassedLabel)
+	set(&c.TestingFailedLabel, labels.TestingFailedLabel)
+	set(&c.TestingSkippedLabel, labels.TestingSkippedLabel)
+	return c, nil
+}
+
+// WebhookSecretsForProject returns the webhook secrets accepted
+// for the activities of the given project.
+// ok is false when the activities are to be rejected, see webhookSecrets.
+func WebhookSecretsForProject(projectId int) (secrets []string, ok bool) {
+	return webhookSecrets(
+		Get(), tenants.ForPivotalTrackerProject(projectId), tenants.PivotalTrackerConfigured())
+}
+
+// webhookSecrets returns the secrets of the given tenant, falling back to the secrets
+// read from the environment. The activities are rejected in case the tenant is known
+// and there is no secret to check, the tenant token would be used otherwise,
+// or in case there are tenants configured and the project belongs to none of them.
+func webhookSecrets(c Config, t *tenants.Tenant, tenantsConfigured bool) (secrets []string, ok bool) {
+	switch {
+	case t != nil:
+		if secrets = t.PivotalTracker.Secrets(); len(secrets) == 0 {
+			secrets = c.WebhookSecrets()
+		}
+		return secrets, len(secrets) != 0
+	case tenantsConfigured:
+		return nil, false
+	default:
+		return c.WebhookSecrets(), true
+	}
+}
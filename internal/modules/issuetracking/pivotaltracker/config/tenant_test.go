@@ -0,0 +1,91 @@
+package config
+
+import (
+	// Stdlib
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	// Internal
+	"github.com/salsaflow/salsaflow-daemon/internal/tenants"
+)
+
+var testingConfig = Config{
+	WebhookSecret:       "global",
+	Token:               "global-token",
+	ReviewedLabel:       "reviewed",
+	ReviewSkippedLabel:  "no review",
+	TestingPassedLabel:  "qa+",
+	TestingFailedLabel:  "qa-",
+	TestingSkippedLabel: "no qa",
+}
+
+func TestForTenant(t *testing.T) {
+	// No tenant, the environment is used.
+	c, err := forTenant(testingConfig, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(c, testingConfig) {
+		t.Errorf("expected %+v, got %+v", testingConfig, c)
+	}
+
+	// The tenant token and labels take precedence.
+	tenant := &tenants.Tenant{
+		Id:             "acme",
+		PivotalTracker: &tenants.PivotalTracker{Token: "acme-token"},
+		Labels: map[string]json.RawMessage{
+			TenantSectionKey: json.RawMessage(`{"reviewed": "code reviewed", "testing_passed": "qa ok"}`),
+		},
+	}
+	c, err = forTenant(testingConfig, tenant)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := testingConfig
+	expected.Token = "acme-token"
+	expected.ReviewedLabel = "code reviewed"
+	expected.TestingPassedLabel = "qa ok"
+	if !reflect.DeepEqual(c, expected) {
+		t.Errorf("expected %+v, got %+v", expected, c)
+	}
+
+	// Invalid labels section.
+	tenant.Labels[TenantSectionKey] = json.RawMessage(`[]`)
+	if _, err := forTenant(testingConfig, tenant); err == nil {
+		t.Error("invalid labels section accepted")
+	}
+}
+
+func TestWebhookSecrets(t *testing.T) {
+	var (
+		noEnv   = Config{}
+		acme    = &tenants.Tenant{Id: "acme", PivotalTracker: &tenants.PivotalTracker{WebhookSecret: "acme"}}
+		initech = &tenants.Tenant{Id: "initech", PivotalTracker: &tenants.PivotalTracker{}}
+		comma   = &tenants.Tenant{Id: "comma", PivotalTracker: &tenants.PivotalTracker{WebhookSecret: "a,b"}}
+	)
+
+	data := []struct {
+		name       string
+		c          Config
+		t          *tenants.Tenant
+		configured bool
+		secrets    []string
+		ok         bool
+	}{
+		{"tenant secret", testingConfig, acme, true, []string{"acme"}, true},
+		{"tenant secret containing a comma", testingConfig, comma, true, []string{"a,b"}, true},
+		{"tenant falling back to the environment", testingConfig, initech, true, []string{"global"}, true},
+		{"tenant without any secret", noEnv, initech, true, nil, false},
+		{"unknown project", testingConfig, nil, true, nil, false},
+		{"single-tenant mode", testingConfig, nil, false, []string{"global"}, true},
+		{"single-tenant mode without secret", noEnv, nil, false, nil, true},
+	}
+
+	for _, d := range data {
+		secrets, ok := webhookSecrets(d.c, d.t, d.configured)
+		if !reflect.DeepEqual(secrets, d.secrets) || ok != d.ok {
+			t.Errorf("%v: expected (%v, %v), got (%v, %v)", d.name, d.secrets, d.ok, secrets, ok)
+		}
+	}
+}
@@ -11,8 +11,10 @@ import (
 )
 
 type Activity struct {
-	Changes []*Change `json:"changes"`
-	Project struct {
+	Guid           string    `json:"guid"`
+	ProjectVersion int       `json:"project_version"`
+	Changes        []*Change `json:"changes"`
+	Project        struct {
 		Id int `json:"id"`
 	} `json:"project"`
 }
@@ -27,6 +29,26 @@ type Values struct {
 	State string `json:"current_state"`
 }
 
+// idempotencyNamespace is the idempotency.Store namespace used for activity GUIDs.
+const idempotencyNamespace = "pivotaltracker"
+
+// activityGuid is the idempotency.KeyFunc used for activities.
+// Pivotal Tracker activity GUIDs are unique, so they can be used the same
+// way as GitHub delivery IDs. Activities without GUID are always processed.
+func activityGuid(r *http.Request) (string, error) {
+	body, err := httputil.ReadBody(r)
+	if err != nil {
+		return "", err
+	}
+
+	// Malformed activities are rejected by handleActivity.
+	var activity Activity
+	if err := json.Unmarshal(body, &activity); err != nil {
+		return "", nil
+	}
+	return activity.Guid, nil
+}
+
 type activityHandlerFunc func(r *http.Request, projectId int, change *Change) error
 
 var activityHandlers = []activityHandlerFunc{
@@ -34,6 +56,8 @@ var activityHandlers = []activityHandlerFunc{
 }
 
 func handleActivity(rw http.ResponseWriter, r *http.Request) {
+	var errorOccured bool
+
 	// Decode the activity object.
 	var activity Activity
 	if err := json.NewDecoder(r.Body).Decode(&activity); err != nil {
@@ -42,8 +66,6 @@ func handleActivity(rw http.ResponseWriter, r *http.Request) {
 	}
 
 	// Process the changes.
-	var errorOccured bool
-
 	pid := activity.Project.Id
 	for _, change := range activity.Changes {
 		for _, handler := range activityHandlers {
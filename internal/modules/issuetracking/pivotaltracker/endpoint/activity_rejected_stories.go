@@ -7,6 +7,7 @@ import (
 	// Internal
 	"github.com/salsaflow/salsaflow-daemon/internal/log"
 	"github.com/salsaflow/salsaflow-daemon/internal/modules/issuetracking/pivotaltracker/config"
+	"github.com/salsaflow/salsaflow-daemon/internal/modules/issuetracking/pivotaltracker/util"
 
 	// Vendor
 	"gopkg.in/salsita/go-pivotaltracker.v1/v5/pivotal"
@@ -23,11 +24,17 @@ func handleRejectedStories(r *http.Request, projectId int, change *Change) error
 
 	// Fetch the story resource.
 	var (
-		cfg = config.Get()
 		pid = projectId
 		sid = change.ResourceID
 	)
-	client := pivotal.NewClient(cfg.Token)
+	cfg, err := config.ForProject(pid)
+	if err != nil {
+		return err
+	}
+	client, err := util.NewClientForProject(pid)
+	if err != nil {
+		return err
+	}
 	story, _, err := client.Stories.Get(pid, sid)
 	if err != nil {
 		return err
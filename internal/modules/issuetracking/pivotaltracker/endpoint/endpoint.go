@@ -2,11 +2,14 @@ package endpoint
 
 import (
 	// Stdlib
+	"crypto/subtle"
+	"encoding/json"
 	stdLog "log"
 	"net/http"
 
 	// Internal
 	httputil "github.com/salsaflow/salsaflow-daemon/internal/http"
+	"github.com/salsaflow/salsaflow-daemon/internal/idempotency"
 	"github.com/salsaflow/salsaflow-daemon/internal/log"
 	module "github.com/salsaflow/salsaflow-daemon/internal/modules/issuetracking/pivotaltracker"
 	"github.com/salsaflow/salsaflow-daemon/internal/modules/issuetracking/pivotaltracker/config"
@@ -27,41 +30,96 @@ func (ep *Endpoint) ModuleId() string {
 	return module.ModuleId
 }
 
+// NewAuthMiddleware returns the middleware authenticating the activities.
+// In case the activities are verified using the Pivotal Tracker API,
+// the verified activity is what gets queued.
+func (ep *Endpoint) NewAuthMiddleware() (negroni.Handler, error) {
+	if config.Get().VerifyActivity {
+		return newVerificationMiddleware(fetchActivity), nil
+	}
+	if config.Get().WebhookSecret == "" {
+		stdLog.Println("WARNING: SFD_PIVOTALTRACKER_WEBHOOK_SECRET is not set")
+	}
+	return newSecretMiddleware(config.WebhookSecretsForProject), nil
+}
+
 func (ep *Endpoint) NewHandler() (http.Handler, error) {
 	// Create a new mux.
 	mux := http.NewServeMux()
 
-	// Handle /events
-	var activityHandler http.Handler
-	if secret := config.Get().WebhookSecret; secret != "" {
-		n := negroni.New()
-		n.Use(newSecretMiddleware(secret))
-		n.UseHandlerFunc(handleActivity)
-		activityHandler = n
-	} else {
-		activityHandler = http.HandlerFunc(handleActivity)
-		stdLog.Println("WARNING: SFD_PIVOTALTRACKER_WEBHOOK_SECRET is not set")
+	// Get the store used to skip redelivered activities.
+	store, err := idempotency.Default()
+	if err != nil {
+		return nil, err
 	}
-	mux.Handle("/events", activityHandler)
+
+	// Handle /events
+	n := negroni.New()
+	n.Use(idempotency.NewMiddleware(store, idempotencyNamespace, activityGuid))
+	n.UseHandlerFunc(handleActivity)
+	mux.Handle("/events", n)
 
 	// Return the mux.
 	return mux, nil
 }
 
-func newSecretMiddleware(secret string) negroni.HandlerFunc {
+// newSecretMiddleware checks the secret query parameter. The secrets are chosen
+// according to the project the activity belongs to since every tenant
+// can use its own secrets. The project is the one the activity is handled for,
+// so the activity is always handled with the credentials of the tenant
+// whose secret was sent. The request is accepted when any of the secrets matches.
+func newSecretMiddleware(secretsForProject func(projectId int) ([]string, bool)) negroni.HandlerFunc {
 	return negroni.HandlerFunc(
 		func(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
-			// Check the secret query parameter.
-			secretParam := r.URL.Query().Get(SecretQueryParameter)
+			bodyBytes, err := httputil.ReadBody(r)
+			if err != nil {
+				httputil.Error(rw, r, err)
+				return
+			}
+
+			// Get the secrets. There is nothing to check when no secret is configured.
+			var activity Activity
+			json.Unmarshal(bodyBytes, &activity)
 
-			if secretParam != secret {
-				log.Warn(r, "webhook secret mismatch: expected='%v', got='%v'\n",
-					secretParam, secret)
+			secrets, ok := secretsForProject(activity.Project.Id)
+			if !ok {
+				log.Warn(r, "No webhook secret available for project %v", activity.Project.Id)
 				httputil.Status(rw, http.StatusUnauthorized)
 				return
 			}
+			if len(secrets) == 0 {
+				next(rw, withoutSecret(r))
+				return
+			}
 
-			// Call the next handler.
-			next(rw, r)
+			// Check the secret query parameter.
+			secretParam := []byte(r.URL.Query().Get(SecretQueryParameter))
+			for _, secret := range secrets {
+				if subtle.ConstantTimeCompare(secretParam, []byte(secret)) == 1 {
+					// Call the next handler, the secret is not needed any more.
+					next(rw, withoutSecret(r))
+					return
+				}
+			}
+
+			log.Warn(r, "Webhook secret mismatch (project=%v)", activity.Project.Id)
+			httputil.Status(rw, http.StatusUnauthorized)
 		})
 }
+
+// withoutSecret returns a copy of r with the secret query parameter removed
+// so that the secret never gets to the delivery queue or the logs.
+func withoutSecret(r *http.Request) *http.Request {
+	query := r.URL.Query()
+	if _, ok := query[SecretQueryParameter]; !ok {
+		return r
+	}
+	query.Del(SecretQueryParameter)
+
+	u := *r.URL
+	u.RawQuery = query.Encode()
+	rc := r.WithContext(r.Context())
+	rc.URL = &u
+	rc.RequestURI = u.RequestURI()
+	return rc
+}
@@ -0,0 +1,121 @@
+package endpoint
+
+import (
+	// Stdlib
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	// Internal
+	httputil "github.com/salsaflow/salsaflow-daemon/internal/http"
+
+	// Vendor
+	"github.com/codegangsta/negroni"
+)
+
+const testingActivity = `{"guid":"123_10","project_version":10,"project":{"id":123},"changes":[]}`
+
+// serve runs the request through the middleware and returns the response status
+// together with the request body as seen by the next handler.
+func serve(middleware negroni.Handler, r *http.Request) (status int, body string) {
+	status, body, _ = serveURL(middleware, r)
+	return status, body
+}
+
+// serveURL is the same as serve, but it also returns the request URL
+// as seen by the next handler.
+func serveURL(middleware negroni.Handler, r *http.Request) (status int, body, url string) {
+	n := negroni.New()
+	n.Use(middleware)
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		b, _ := httputil.ReadBody(r)
+		body = string(b)
+		url = r.URL.String()
+		rw.WriteHeader(http.StatusAccepted)
+	})
+
+	rw := httptest.NewRecorder()
+	n.ServeHTTP(rw, r)
+	return rw.Code, body, url
+}
+
+func TestSecretMiddleware(t *testing.T) {
+	secretsForProject := func(projectId int) ([]string, bool) {
+		if projectId == 123 {
+			return []string{"old", "new"}, true
+		}
+		return nil, false
+	}
+	middleware := newSecretMiddleware(secretsForProject)
+
+	data := []struct {
+		query  string
+		status int
+	}{
+		{"?secret=new", http.StatusAccepted},
+		{"?secret=old", http.StatusAccepted},
+		{"?secret=unknown", http.StatusUnauthorized},
+		{"", http.StatusUnauthorized},
+	}
+	for _, d := range data {
+		r := httptest.NewRequest("POST", "/events"+d.query, strings.NewReader(testingActivity))
+		if status, _ := serve(middleware, r); status != d.status {
+			t.Errorf("query %q: expected status %v, got %v", d.query, d.status, status)
+		}
+	}
+	// The secret is stripped before the activity is passed on.
+	r := httptest.NewRequest("POST", "/events?secret=new&foo=bar", strings.NewReader(testingActivity))
+	if _, _, url := serveURL(middleware, r); url != "/events?foo=bar" {
+		t.Errorf("expected the secret to be stripped, got %v", url)
+	}
+
+	// Activities of unknown projects are rejected.
+	const unknownActivity = `{"guid":"456_10","project_version":10,"project":{"id":456},"changes":[]}`
+	r = httptest.NewRequest("POST", "/events?secret=new", strings.NewReader(unknownActivity))
+	if status, _ := serve(middleware, r); status != http.StatusUnauthorized {
+		t.Errorf("unknown project: expected status %v, got %v", http.StatusUnauthorized, status)
+	}
+}
+
+func TestVerificationMiddleware(t *testing.T) {
+	const fetched = `{"guid":"123_10","project_version":10,"project":{"id":123},"changes":[{"kind":"story"}]}`
+
+	fetch := func(activity *Activity) (json.RawMessage, error) {
+		if activity.Project.Id != 123 || activity.ProjectVersion != 10 {
+			t.Errorf("unexpected activity fetched: %+v", activity)
+		}
+		return findActivity([]json.RawMessage{
+			json.RawMessage(`{"guid":"123_11"}`),
+			json.RawMessage(fetched),
+		}, activity.Guid)
+	}
+	middleware := newVerificationMiddleware(fetch)
+
+	// A known activity is replaced by the fetched one.
+	r := httptest.NewRequest("POST", "/events", strings.NewReader(testingActivity))
+	status, body := serve(middleware, r)
+	if status != http.StatusAccepted {
+		t.Errorf("expected status %v, got %v", http.StatusAccepted, status)
+	}
+	if body != fetched {
+		t.Errorf("expected the fetched activity to be passed on, got %v", body)
+	}
+
+	// The body read by the previous middlewares is replaced as well.
+	r, err := httputil.WithBody(httptest.NewRequest("POST", "/events", strings.NewReader(testingActivity)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, body := serve(middleware, r); body != fetched {
+		t.Errorf("expected the fetched activity to be passed on, got %v", body)
+	}
+
+	// An unknown activity is rejected.
+	unknown := strings.Replace(testingActivity, "123_10", "123_12", 1)
+	r = httptest.NewRequest("POST", "/events", strings.NewReader(unknown))
+	if status, _ := serve(middleware, r); status != http.StatusUnauthorized {
+		t.Errorf("expected status %v, got %v", http.StatusUnauthorized, status)
+	}
+}
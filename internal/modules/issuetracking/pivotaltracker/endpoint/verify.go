@@ -0,0 +1,99 @@
+package endpoint
+
+import (
+	// Stdlib
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	// Internal
+	httputil "github.com/salsaflow/salsaflow-daemon/internal/http"
+	"github.com/salsaflow/salsaflow-daemon/internal/log"
+	"github.com/salsaflow/salsaflow-daemon/internal/modules/issuetracking/pivotaltracker/util"
+
+	// Vendor
+	"github.com/codegangsta/negroni"
+)
+
+// activityFetcher returns the activity of the given GUID as returned
+// by the Pivotal Tracker API, nil in case there is no such activity.
+type activityFetcher func(activity *Activity) (json.RawMessage, error)
+
+// newVerificationMiddleware makes sure the activity really happened
+// by fetching it from the Pivotal Tracker API. The activity sent in the request
+// is then replaced by the fetched one so that only verified data are processed.
+func newVerificationMiddleware(fetch activityFetcher) negroni.HandlerFunc {
+	return negroni.HandlerFunc(func(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+		// Decode the activity object.
+		body, err := httputil.ReadBody(r)
+		if err != nil {
+			httputil.Error(rw, r, err)
+			return
+		}
+
+		var activity Activity
+		if err := json.Unmarshal(body, &activity); err != nil {
+			httputil.Error(rw, r, err)
+			return
+		}
+
+		if activity.Guid == "" || activity.Project.Id == 0 {
+			log.Warn(r, "Activity verification failed: GUID or project ID missing")
+			httputil.Status(rw, http.StatusUnauthorized)
+			return
+		}
+
+		// Fetch the activity.
+		verified, err := fetch(&activity)
+		if err != nil {
+			httputil.Error(rw, r, err)
+			return
+		}
+		if verified == nil {
+			log.Warn(r, "Activity verification failed: activity %v not found (project=%v)",
+				activity.Guid, activity.Project.Id)
+			httputil.Status(rw, http.StatusUnauthorized)
+			return
+		}
+
+		// Continue with the verified activity.
+		next(rw, httputil.ReplaceBody(r, verified))
+	})
+}
+
+// fetchActivity is the activityFetcher using the Pivotal Tracker project activity API.
+// Activity GUIDs are not accepted by the API, so we list the activities
+// starting with the project version the activity claims to be and look for the GUID.
+func fetchActivity(activity *Activity) (json.RawMessage, error) {
+	client, err := util.NewClientForProject(activity.Project.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	u := fmt.Sprintf("projects/%v/activity?since_version=%v&limit=%v",
+		activity.Project.Id, activity.ProjectVersion-1, 20)
+	req, err := client.NewRequest("GET", u, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var activities []json.RawMessage
+	if _, err := client.Do(req, &activities); err != nil {
+		return nil, err
+	}
+
+	return findActivity(activities, activity.Guid)
+}
+
+func findActivity(activities []json.RawMessage, guid string) (json.RawMessage, error) {
+	for _, raw := range activities {
+		var a Activity
+		if err := json.Unmarshal(raw, &a); err != nil {
+			return nil, err
+		}
+		if a.Guid == guid {
+			return raw, nil
+		}
+	}
+	return nil, nil
+}
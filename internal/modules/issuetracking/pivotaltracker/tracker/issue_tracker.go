@@ -24,21 +24,16 @@ type storyService interface {
 	AddComment(projectId, storyId int, comment *pivotal.Comment) (*pivotal.Comment, *http.Response, error)
 }
 
-type issueTracker struct {
-	stories storyService
-	config  config.Config
-}
+// The client and the configuration are chosen per project
+// since every project can belong to a different tenant.
+type issueTracker struct{}
 
 func Factory() (common.IssueTracker, error) {
-	client, err := util.NewClient()
-	if err != nil {
+	if err := util.EnsureCredentials(); err != nil {
 		return nil, err
 	}
 
-	return &issueTracker{
-		stories: client.Stories,
-		config:  config.Get(),
-	}, nil
+	return &issueTracker{}, nil
 }
 
 func (tracker *issueTracker) FindStoryByTag(storyTag string) (common.Story, error) {
@@ -47,12 +42,22 @@ func (tracker *issueTracker) FindStoryByTag(storyTag string) (common.Story, erro
 		return nil, err
 	}
 
-	story, _, err := tracker.stories.Get(pid, sid)
+	cfg, err := config.ForProject(pid)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := util.NewClientForProject(pid)
+	if err != nil {
+		return nil, err
+	}
+
+	story, _, err := client.Stories.Get(pid, sid)
 	if err != nil {
 		return nil, err
 	}
 
-	return &commonStory{tracker.stories, &tracker.config, story}, nil
+	return &commonStory{client.Stories, &cfg, story}, nil
 }
 
 func parseStoryTag(storyTag string) (pid, sid int, err error) {
@@ -89,6 +89,30 @@ func (s *commonStory) MarkAsReviewed() error {
 	return s.update(state, labels)
 }
 
+func (s *commonStory) OnReviewBlockerOpened(blockerURL, summary string) error {
+	if err := s.addComment(fmt.Sprintf("Review blocker [opened](%v): %v", blockerURL, summary)); err != nil {
+		return err
+	}
+
+	// The story is not reviewed any more, drop 'reviewed'.
+	var changed bool
+	labels := filterLabels(s.story.Labels, func(label *pivotal.Label) bool {
+		if label.Name == s.config.ReviewedLabel {
+			changed = true
+			return false
+		}
+		return true
+	})
+	if !changed {
+		return nil
+	}
+	return s.updateLabels(labels)
+}
+
+func (s *commonStory) OnReviewBlockerFixed(blockerURL, summary string) error {
+	return s.addComment(fmt.Sprintf("Review blocker [fixed](%v): %v", blockerURL, summary))
+}
+
 func (s *commonStory) addComment(text string) error {
 	var (
 		pid = s.story.ProjectId
@@ -126,6 +150,22 @@ func (s *commonStory) update(state string, labels []*pivotal.Label) error {
 	return nil
 }
 
+// updateLabels replaces the story labels. Unlike update it sends
+// the labels even when the list is empty so that all labels can be removed.
+func (s *commonStory) updateLabels(labels []*pivotal.Label) error {
+	ls := mapLabels(labels, func(label *pivotal.Label) *pivotal.Label {
+		return &pivotal.Label{Name: label.Name}
+	})
+	story, _, err := s.stories.Update(s.story.ProjectId, s.story.Id, &pivotal.StoryRequest{
+		Labels: &ls,
+	})
+	if err != nil {
+		return err
+	}
+	s.story = story
+	return nil
+}
+
 func shouldUpdateState(state string) (string, bool) {
 	switch state {
 	case pivotal.StoryStateFinished:
@@ -430,3 +430,70 @@ var _ = Describe("Calling commonStory.MarkAsReviewed", func() {
 		}(i)
 	}
 })
+
+var _ = Describe("Invoking OnReviewBlockerOpened story event handler", func() {
+
+	const (
+		blockerURL     = "https://some-blocker-url"
+		blockerSummary = "Fix the typo"
+	)
+
+	var (
+		stories *testingStoryService
+		cfg     *config.Config
+		ptStory *pivotal.Story
+		story   *commonStory
+	)
+
+	BeforeEach(func() {
+		stories = &testingStoryService{}
+		cfg = &config.Config{ReviewedLabel: "reviewed"}
+		ptStory = &pivotal.Story{
+			Id:        testingStoryId,
+			ProjectId: testingProjectId,
+		}
+		story = &commonStory{stories, cfg, ptStory}
+
+		stories.AddCommentMock = func(
+			projectId int,
+			storyId int,
+			comment *pivotal.Comment,
+		) (*pivotal.Comment, *http.Response, error) {
+
+			expectedText := fmt.Sprintf("Review blocker [opened](%v): %v", blockerURL, blockerSummary)
+			Expect(comment).To(Equal(&pivotal.Comment{Text: expectedText}))
+			return &pivotal.Comment{}, nil, nil
+		}
+	})
+
+	It("drops the reviewed label, even when it is the only label", func() {
+
+		ptStory.Labels = []*pivotal.Label{{Name: "reviewed"}}
+
+		var updateCalled bool
+		stories.UpdateMock = func(
+			projectId int,
+			storyId int,
+			req *pivotal.StoryRequest,
+		) (*pivotal.Story, *http.Response, error) {
+
+			Expect(req).To(Equal(&pivotal.StoryRequest{Labels: &[]*pivotal.Label{}}))
+
+			updateCalled = true
+			return &pivotal.Story{}, nil, nil
+		}
+
+		err := story.OnReviewBlockerOpened(blockerURL, blockerSummary)
+
+		Expect(err).To(BeNil())
+		Expect(updateCalled).To(BeTrue())
+	})
+
+	It("only adds the comment when the story is not reviewed", func() {
+
+		ptStory.Labels = []*pivotal.Label{{Name: "other"}}
+
+		err := story.OnReviewBlockerOpened(blockerURL, blockerSummary)
+		Expect(err).To(BeNil())
+	})
+})
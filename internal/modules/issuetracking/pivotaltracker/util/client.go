@@ -1,9 +1,13 @@
 package util
 
 import (
+	// Stdlib
+	"fmt"
+
 	// Internal
 	"github.com/salsaflow/salsaflow-daemon/internal/errs"
 	"github.com/salsaflow/salsaflow-daemon/internal/modules/issuetracking/pivotaltracker/config"
+	"github.com/salsaflow/salsaflow-daemon/internal/tenants"
 
 	// Vendor
 	"gopkg.in/salsita/go-pivotaltracker.v1/v5/pivotal"
@@ -14,10 +18,64 @@ import (
 //
 // An error is returned in case the relevant environment variable is not set.
 func NewClient() (*pivotal.Client, error) {
-	token := config.Get().Token
-	if token == "" {
+	return newClient(config.Get())
+}
+
+// NewClientForProject returns a new Pivotal Tracker API client
+// that uses the access token of the tenant the project belongs to,
+// the token read from the environment is used when there is no such tenant.
+func NewClientForProject(projectId int) (*pivotal.Client, error) {
+	c, err := config.ForProject(projectId)
+	if err != nil {
+		return nil, err
+	}
+	return newClient(c)
+}
+
+// EnsureCredentials returns an error in case there is no Pivotal Tracker token
+// configured, neither in the environment nor for any of the tenants.
+func EnsureCredentials() error {
+	if config.Get().Token != "" {
+		return nil
+	}
+	for _, t := range tenants.All() {
+		if t.PivotalTracker != nil && t.PivotalTracker.Token != "" {
+			return nil
+		}
+	}
+	return &errs.ErrVarNotSet{"SFD_PIVOTALTRACKER_TOKEN"}
+}
+
+// CredentialChecks returns the checks verifying the configured Pivotal Tracker tokens,
+// one check for the token read from the environment and one for every tenant token,
+// so that the tokens of every tenant are reported separately.
+func CredentialChecks() map[string]func() error {
+	checks := make(map[string]func() error)
+
+	tokenCheck := func(source, token string) func() error {
+		return func() error {
+			if _, _, err := pivotal.NewClient(token).Me.Get(); err != nil {
+				return fmt.Errorf("Pivotal Tracker token (%v): %v", source, err)
+			}
+			return nil
+		}
+	}
+
+	if token := config.Get().Token; token != "" {
+		checks["pivotaltracker"] = tokenCheck("SFD_PIVOTALTRACKER_TOKEN", token)
+	}
+	for _, t := range tenants.All() {
+		if t.PivotalTracker != nil && t.PivotalTracker.Token != "" {
+			checks["pivotaltracker/tenant/"+t.Id] = tokenCheck("tenant "+t.Id, t.PivotalTracker.Token)
+		}
+	}
+	return checks
+}
+
+func newClient(c config.Config) (*pivotal.Client, error) {
+	if c.Token == "" {
 		return nil, &errs.ErrVarNotSet{"SFD_PIVOTALTRACKER_TOKEN"}
 	}
 
-	return pivotal.NewClient(token), nil
+	return pivotal.NewClient(c.Token), nil
 }
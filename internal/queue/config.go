@@ -0,0 +1,61 @@
+package queue
+
+import (
+	// Stdlib
+	"log"
+	"time"
+
+	// Vendor
+	"github.com/kelseyhightower/envconfig"
+)
+
+type Config struct {
+	// Dir is the directory where the deliveries are stored.
+	Dir string `envconfig:"QUEUE_DIR" default:"data/queue"`
+
+	// Workers is the number of deliveries being processed concurrently.
+	Workers int `envconfig:"QUEUE_WORKERS" default:"4"`
+
+	// MaxAttempts is the number of attempts after which
+	// a failing delivery is moved into the dead state.
+	MaxAttempts int `envconfig:"QUEUE_MAX_ATTEMPTS" default:"10"`
+
+	// Retry backoff, the delay is doubled after every failed attempt.
+	BackoffString    string `envconfig:"QUEUE_BACKOFF"     default:"5s"`
+	MaxBackoffString string `envconfig:"QUEUE_MAX_BACKOFF" default:"1h"`
+
+	// Retention specifies how long finished deliveries are kept around.
+	RetentionString string `envconfig:"QUEUE_RETENTION" default:"168h"`
+
+	// Backoff, MaxBackoff and Retention contain the parsed duration strings.
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+	Retention  time.Duration
+}
+
+var config Config
+
+func init() {
+	if err := envconfig.Process("SFD", &config); err != nil {
+		log.Fatalln("Fatal error while parsing queue config:", err)
+	}
+
+	for _, d := range []struct {
+		value string
+		dst   *time.Duration
+	}{
+		{config.BackoffString, &config.Backoff},
+		{config.MaxBackoffString, &config.MaxBackoff},
+		{config.RetentionString, &config.Retention},
+	} {
+		v, err := time.ParseDuration(d.value)
+		if err != nil {
+			log.Fatalln("Fatal error while parsing queue config:", err)
+		}
+		*d.dst = v
+	}
+}
+
+func GetConfig() Config {
+	return config
+}
@@ -0,0 +1,116 @@
+package queue
+
+import (
+	// Stdlib
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+type Status string
+
+const (
+	// StatusPending means that the delivery is waiting to be processed.
+	StatusPending Status = "pending"
+
+	// StatusProcessing means that the delivery is being processed right now.
+	StatusProcessing Status = "processing"
+
+	// StatusDone means that the delivery was processed successfully.
+	StatusDone Status = "done"
+
+	// StatusFailed means that the module handler rejected the delivery,
+	// i.e. it returned a 4xx status code. Such deliveries are not retried.
+	StatusFailed Status = "failed"
+
+	// StatusDead means that the delivery failed MaxAttempts times in a row.
+	StatusDead Status = "dead"
+)
+
+// Delivery represents a webhook delivery as received by a module endpoint.
+type Delivery struct {
+	Id       string      `json:"id"`
+	ModuleId string      `json:"module_id"`
+	Method   string      `json:"method"`
+	Path     string      `json:"path"`
+	RawQuery string      `json:"raw_query,omitempty"`
+	Header   http.Header `json:"header"`
+	Body     []byte      `json:"body"`
+
+	// ReplayOf is set to the ID of the original delivery for replayed deliveries.
+	ReplayOf string `json:"replay_of,omitempty"`
+
+	Status        Status    `json:"status"`
+	Attempts      int       `json:"attempts"`
+	StatusCode    int       `json:"status_code,omitempty"`
+	Outcome       string    `json:"outcome,omitempty"`
+	ReceivedAt    time.Time `json:"received_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+	NextAttemptAt time.Time `json:"next_attempt_at,omitempty"`
+}
+
+// Finished returns true when the delivery is not going to be processed again.
+func (d *Delivery) Finished() bool {
+	switch d.Status {
+	case StatusDone, StatusFailed, StatusDead:
+		return true
+	default:
+		return false
+	}
+}
+
+// The webhooks are authenticated before being queued, so the credentials
+// sent with the webhooks are not needed any more and they are never stored.
+var (
+	sensitiveHeaders = []string{
+		"Authorization",
+		"Cookie",
+		"X-Gitlab-Token",
+		"X-Hub-Signature",
+		"X-Hub-Signature-256",
+	}
+
+	sensitiveQueryParameters = []string{
+		"secret",
+		"token",
+	}
+)
+
+// Redact removes the webhook credentials from the delivery,
+// i.e. the signature and token headers and the secret query parameters.
+func (d *Delivery) Redact() {
+	header := make(http.Header, len(d.Header))
+	for k, vs := range d.Header {
+		header[k] = vs
+	}
+	for _, k := range sensitiveHeaders {
+		header.Del(k)
+	}
+	d.Header = header
+
+	if d.RawQuery == "" {
+		return
+	}
+	query, err := url.ParseQuery(d.RawQuery)
+	if err != nil {
+		// Better safe than sorry, drop the whole query when it cannot be parsed.
+		d.RawQuery = ""
+		return
+	}
+	for _, k := range sensitiveQueryParameters {
+		query.Del(k)
+	}
+	d.RawQuery = query.Encode()
+}
+
+// newDeliveryId returns a new delivery ID. The IDs sort chronologically.
+func newDeliveryId(now time.Time) (string, error) {
+	suffix := make([]byte, 4)
+	if _, err := rand.Read(suffix); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%020d-%v", now.UnixNano(), hex.EncodeToString(suffix)), nil
+}
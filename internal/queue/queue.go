@@ -0,0 +1,487 @@
+package queue
+
+import (
+	// Stdlib
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	stdLog "log"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	// Internal
+	httputil "github.com/salsaflow/salsaflow-daemon/internal/http"
+	"github.com/salsaflow/salsaflow-daemon/internal/idempotency"
+	"github.com/salsaflow/salsaflow-daemon/internal/log"
+	"github.com/salsaflow/salsaflow-daemon/internal/metrics"
+)
+
+// maxBodySize limits the size of the request bodies being stored.
+// GitHub caps webhook payloads at 25 MB, so let's use the same limit.
+const maxBodySize = 25 << 20
+
+// Queue makes webhook handling asynchronous and durable.
+//
+// Every incoming request is stored as a Delivery and acknowledged
+// with 202 Accepted right away. The deliveries are then passed to the module
+// handlers by a pool of workers. A delivery is retried with exponential backoff
+// until the module handler stops returning 5xx status codes, or until
+// MaxAttempts is reached, in which case the delivery is marked as dead.
+type Queue struct {
+	store  Store
+	config Config
+
+	handlersMu sync.RWMutex
+	handlers   map[string]http.Handler
+
+	jobs     chan job
+	quit     chan struct{}
+	quitOnce sync.Once
+	wg       sync.WaitGroup
+
+	// inFlight contains the deliveries being processed right now.
+	inFlightMu sync.Mutex
+	inFlight   map[string]*Delivery
+}
+
+type job struct {
+	moduleId string
+	id       string
+}
+
+func New(store Store, config Config) *Queue {
+	return &Queue{
+		store:    store,
+		config:   config,
+		handlers: make(map[string]http.Handler),
+		jobs:     make(chan job),
+		quit:     make(chan struct{}),
+		inFlight: make(map[string]*Delivery),
+	}
+}
+
+// Handler returns a http.Handler that stores every incoming request
+// as a delivery for the given module and responds with 202 Accepted.
+// The delivery is later passed to handler by one of the queue workers.
+func (q *Queue) Handler(moduleId string, handler http.Handler) http.Handler {
+	q.handlersMu.Lock()
+	q.handlers[moduleId] = handler
+	q.handlersMu.Unlock()
+
+	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		delivery, err := q.enqueue(moduleId, rw, r)
+		if err != nil {
+			httputil.Error(rw, r, err)
+			return
+		}
+
+		rw.Header().Set("X-SalsaFlow-Delivery", delivery.Id)
+		httputil.Status(rw, http.StatusAccepted)
+	})
+}
+
+func (q *Queue) enqueue(moduleId string, rw http.ResponseWriter, r *http.Request) (*Delivery, error) {
+	log.AddField(r, log.FieldModuleId, moduleId)
+
+	// Read the request body.
+	body, err := ioutil.ReadAll(http.MaxBytesReader(rw, r.Body, maxBodySize))
+	if err != nil {
+		return nil, err
+	}
+
+	// Store the delivery.
+	now := time.Now()
+	id, err := newDeliveryId(now)
+	if err != nil {
+		return nil, err
+	}
+
+	delivery := &Delivery{
+		Id:            id,
+		ModuleId:      moduleId,
+		Method:        r.Method,
+		Path:          r.URL.Path,
+		RawQuery:      r.URL.RawQuery,
+		Header:        r.Header,
+		Body:          body,
+		Status:        StatusPending,
+		ReceivedAt:    now,
+		UpdatedAt:     now,
+		NextAttemptAt: now,
+	}
+	delivery.Redact()
+	if err := q.store.Save(delivery); err != nil {
+		return nil, err
+	}
+
+	log.Info(r, "Delivery %v/%v queued", moduleId, id)
+	metrics.DeliveriesReceived.Inc(moduleId, metrics.EventType(r.Header))
+
+	// Schedule the delivery to be processed.
+	q.schedule(delivery)
+	return delivery, nil
+}
+
+// Replay creates a copy of the given delivery and it schedules the copy
+// to be processed again. The copy is marked as a replay so that the module
+// handlers do not skip it as a redelivery, see idempotency.IsReplay.
+func (q *Queue) Replay(moduleId, id string) (*Delivery, error) {
+	original, err := q.store.Load(moduleId, id)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	newId, err := newDeliveryId(now)
+	if err != nil {
+		return nil, err
+	}
+
+	delivery := &Delivery{
+		Id:            newId,
+		ModuleId:      original.ModuleId,
+		Method:        original.Method,
+		Path:          original.Path,
+		RawQuery:      original.RawQuery,
+		Header:        original.Header,
+		Body:          original.Body,
+		ReplayOf:      original.Id,
+		Status:        StatusPending,
+		ReceivedAt:    now,
+		UpdatedAt:     now,
+		NextAttemptAt: now,
+	}
+	if err := q.store.Save(delivery); err != nil {
+		return nil, err
+	}
+
+	stdLog.Printf("Queue: delivery %v/%v queued as a replay of %v\n", moduleId, newId, id)
+
+	q.schedule(delivery)
+	return delivery, nil
+}
+
+// Store returns the store used by the queue.
+func (q *Queue) Store() Store {
+	return q.store
+}
+
+// Start resumes the deliveries left unfinished in the store
+// and it starts the worker pool.
+func (q *Queue) Start() error {
+	// Start the workers.
+	for i := 0; i < q.config.Workers; i++ {
+		q.wg.Add(1)
+		go q.work()
+	}
+
+	// Start pruning finished deliveries.
+	q.wg.Add(1)
+	go q.pruneLoop()
+
+	// Schedule the deliveries that are not finished yet.
+	// That includes the deliveries that were being processed
+	// when the previous instance went down.
+	moduleIds, err := q.store.ModuleIds()
+	if err != nil {
+		return err
+	}
+	for _, moduleId := range moduleIds {
+		deliveries, err := q.store.List(moduleId)
+		if err != nil {
+			return err
+		}
+		for _, delivery := range deliveries {
+			if delivery.Finished() {
+				continue
+			}
+			stdLog.Printf("Queue: resuming delivery %v/%v\n", delivery.ModuleId, delivery.Id)
+			q.schedule(delivery)
+		}
+	}
+	return nil
+}
+
+// Stop stops the workers and waits for them to finish processing
+// the deliveries being handled right now. The deliveries that are
+// still pending are kept in the store and resumed on Start.
+func (q *Queue) Stop() {
+	q.Shutdown(context.Background())
+}
+
+// Shutdown works like Stop, but it stops waiting for the workers once ctx is done.
+// In that case ErrShutdownTimeout is returned, listing the deliveries
+// that were still being processed. These deliveries are resumed on Start.
+//
+// Shutdown and Stop can be called multiple times.
+func (q *Queue) Shutdown(ctx context.Context) error {
+	q.quitOnce.Do(func() {
+		close(q.quit)
+	})
+
+	done := make(chan struct{})
+	go func() {
+		q.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return &ErrShutdownTimeout{q.InFlight()}
+	}
+}
+
+// InFlight returns the deliveries being processed right now.
+func (q *Queue) InFlight() []*Delivery {
+	q.inFlightMu.Lock()
+	defer q.inFlightMu.Unlock()
+
+	deliveries := make([]*Delivery, 0, len(q.inFlight))
+	for _, delivery := range q.inFlight {
+		deliveries = append(deliveries, delivery)
+	}
+	sort.Slice(deliveries, func(i, j int) bool {
+		return deliveries[i].Id < deliveries[j].Id
+	})
+	return deliveries
+}
+
+// ErrShutdownTimeout is returned from Shutdown when the workers
+// do not finish processing the deliveries in time.
+type ErrShutdownTimeout struct {
+	InFlight []*Delivery
+}
+
+func (err *ErrShutdownTimeout) Error() string {
+	ids := make([]string, len(err.InFlight))
+	for i, delivery := range err.InFlight {
+		ids[i] = delivery.ModuleId + "/" + delivery.Id
+	}
+	return fmt.Sprintf("queue shutdown timed out, deliveries still being processed: [%v]",
+		strings.Join(ids, ", "))
+}
+
+func (q *Queue) schedule(delivery *Delivery) {
+	j := job{delivery.ModuleId, delivery.Id}
+	time.AfterFunc(delivery.NextAttemptAt.Sub(time.Now()), func() {
+		select {
+		case q.jobs <- j:
+		case <-q.quit:
+		}
+	})
+}
+
+func (q *Queue) work() {
+	defer q.wg.Done()
+	for {
+		select {
+		case j := <-q.jobs:
+			q.process(j)
+		case <-q.quit:
+			return
+		}
+	}
+}
+
+func (q *Queue) process(j job) {
+	// Load the delivery.
+	delivery, err := q.store.Load(j.moduleId, j.id)
+	if err != nil {
+		stdLog.Printf("ERROR: Queue: failed to load delivery %v/%v: %v\n", j.moduleId, j.id, err)
+		return
+	}
+	if delivery.Finished() {
+		return
+	}
+
+	// Rebuild the request.
+	r, err := delivery.newRequest()
+	if err != nil {
+		q.finish(delivery, nil, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	// Annotate the log records for the delivery.
+	r = log.WithRequest(r)
+	log.AddField(r, log.FieldModuleId, delivery.ModuleId)
+
+	// Get the module handler.
+	q.handlersMu.RLock()
+	handler, ok := q.handlers[delivery.ModuleId]
+	q.handlersMu.RUnlock()
+	if !ok {
+		q.finish(delivery, r, http.StatusNotFound, "no handler registered for module "+delivery.ModuleId)
+		return
+	}
+
+	// Mark the delivery as being processed.
+	delivery.Status = StatusProcessing
+	delivery.Attempts++
+	delivery.UpdatedAt = time.Now()
+	if err := q.store.Save(delivery); err != nil {
+		log.Error(r, err)
+		return
+	}
+
+	// Invoke the module handler.
+	q.inFlightMu.Lock()
+	q.inFlight[delivery.ModuleId+"/"+delivery.Id] = delivery
+	q.inFlightMu.Unlock()
+	defer func() {
+		q.inFlightMu.Lock()
+		delete(q.inFlight, delivery.ModuleId+"/"+delivery.Id)
+		q.inFlightMu.Unlock()
+	}()
+
+	r, skipped := metrics.TrackSkips(r)
+	rec := newRecorder()
+	start := time.Now()
+	serve(handler, rec, r)
+	observe(delivery, rec.statusCode(), skipped(), time.Since(start))
+	q.finish(delivery, r, rec.statusCode(), rec.outcome())
+}
+
+func (q *Queue) finish(delivery *Delivery, r *http.Request, statusCode int, outcome string) {
+	now := time.Now()
+	delivery.StatusCode = statusCode
+	delivery.Outcome = outcome
+	delivery.UpdatedAt = now
+
+	switch {
+	case statusCode < 400:
+		delivery.Status = StatusDone
+	case statusCode < 500:
+		delivery.Status = StatusFailed
+	case delivery.Attempts >= q.config.MaxAttempts:
+		delivery.Status = StatusDead
+	default:
+		delivery.Status = StatusPending
+		delivery.NextAttemptAt = now.Add(q.backoff(delivery.Attempts))
+	}
+
+	if err := q.store.Save(delivery); err != nil {
+		stdLog.Printf("ERROR: Queue: failed to save delivery %v/%v: %v\n",
+			delivery.ModuleId, delivery.Id, err)
+		return
+	}
+
+	msg := fmt.Sprintf("Delivery %v/%v %v (status code %v, attempt %v)",
+		delivery.ModuleId, delivery.Id, delivery.Status, statusCode, delivery.Attempts)
+	if delivery.Status == StatusPending {
+		msg += fmt.Sprintf(", next attempt at %v", delivery.NextAttemptAt.Format(time.RFC3339))
+	}
+	if r != nil {
+		log.Info(r, "%v", msg)
+	} else {
+		stdLog.Println("Queue:", msg)
+	}
+
+	if delivery.Status == StatusPending {
+		q.schedule(delivery)
+	}
+}
+
+// observe records the outcome of a delivery processing attempt.
+func observe(delivery *Delivery, statusCode int, skipped bool, d time.Duration) {
+	var (
+		moduleId  = delivery.ModuleId
+		eventType = metrics.EventType(delivery.Header)
+		outcome   = metrics.OutcomeHandled
+	)
+	switch {
+	case statusCode >= 400:
+		outcome = metrics.OutcomeFailed
+	case skipped:
+		outcome = metrics.OutcomeSkipped
+	}
+	metrics.DeliveriesProcessed.Inc(moduleId, eventType, outcome)
+	metrics.DeliveryDuration.Observe(d.Seconds(), moduleId, eventType)
+}
+
+func (q *Queue) backoff(attempts int) time.Duration {
+	d := q.config.Backoff
+	for i := 1; i < attempts; i++ {
+		d *= 2
+		if d >= q.config.MaxBackoff {
+			return q.config.MaxBackoff
+		}
+	}
+	return d
+}
+
+func (q *Queue) pruneLoop() {
+	defer q.wg.Done()
+
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+
+	for {
+		q.prune()
+
+		select {
+		case <-ticker.C:
+		case <-q.quit:
+			return
+		}
+	}
+}
+
+// prune deletes finished deliveries older than the configured retention.
+func (q *Queue) prune() {
+	threshold := time.Now().Add(-q.config.Retention)
+
+	moduleIds, err := q.store.ModuleIds()
+	if err != nil {
+		stdLog.Println("ERROR: Queue: failed to prune deliveries:", err)
+		return
+	}
+	for _, moduleId := range moduleIds {
+		deliveries, err := q.store.List(moduleId)
+		if err != nil {
+			stdLog.Println("ERROR: Queue: failed to prune deliveries:", err)
+			return
+		}
+		for _, delivery := range deliveries {
+			if !delivery.Finished() || delivery.UpdatedAt.After(threshold) {
+				continue
+			}
+			if err := q.store.Delete(delivery.ModuleId, delivery.Id); err != nil {
+				stdLog.Println("ERROR: Queue: failed to prune deliveries:", err)
+				return
+			}
+		}
+	}
+}
+
+func (delivery *Delivery) newRequest() (*http.Request, error) {
+	u := &url.URL{Path: delivery.Path, RawQuery: delivery.RawQuery}
+	r, err := http.NewRequest(delivery.Method, u.String(), bytes.NewReader(delivery.Body))
+	if err != nil {
+		return nil, err
+	}
+	for k, vs := range delivery.Header {
+		r.Header[k] = append([]string(nil), vs...)
+	}
+	if delivery.ReplayOf != "" {
+		r = r.WithContext(idempotency.WithReplay(r.Context()))
+	}
+	return r, nil
+}
+
+// serve invokes the handler, turning any panic into 500 Internal Server Error.
+func serve(handler http.Handler, rec *recorder, r *http.Request) {
+	defer func() {
+		if err := recover(); err != nil {
+			rec.panicked = fmt.Sprintf("panic: %v", err)
+			stdLog.Printf("ERROR: Queue: panic while processing %v %v: %v\n", r.Method, r.URL.Path, err)
+		}
+	}()
+	handler.ServeHTTP(rec, r)
+}
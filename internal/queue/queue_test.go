@@ -0,0 +1,259 @@
+package queue
+
+import (
+	// Stdlib
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+const testingModuleId = "salsaflow.modules.testing"
+
+func newTestingQueue(t *testing.T) (*Queue, Store, func()) {
+	dir, err := ioutil.TempDir("", "salsaflow-daemon-queue")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	store, err := NewFileStore(dir)
+	if err != nil {
+		os.RemoveAll(dir)
+		t.Fatal(err)
+	}
+
+	q := New(store, Config{
+		Workers:     2,
+		MaxAttempts: 3,
+		Backoff:     time.Millisecond,
+		MaxBackoff:  10 * time.Millisecond,
+		Retention:   time.Hour,
+	})
+	return q, store, func() { os.RemoveAll(dir) }
+}
+
+func postDelivery(t *testing.T, handler http.Handler, body string) string {
+	r, err := http.NewRequest("POST", "/events?project=1&secret=x", strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.Header.Set("X-GitHub-Event", "issues")
+	r.Header.Set("X-Hub-Signature-256", "sha256=abc")
+
+	rw := httptest.NewRecorder()
+	handler.ServeHTTP(rw, r)
+	if rw.Code != http.StatusAccepted {
+		t.Fatalf("expected 202 Accepted, got %v", rw.Code)
+	}
+
+	id := rw.Header().Get("X-SalsaFlow-Delivery")
+	if id == "" {
+		t.Fatal("delivery ID header not set")
+	}
+	return id
+}
+
+func waitForStatus(t *testing.T, store Store, id string, status Status) *Delivery {
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		delivery, err := store.Load(testingModuleId, id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if delivery.Status == status {
+			return delivery
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("delivery %v did not reach status %v", id, status)
+	return nil
+}
+
+func TestQueue_RetriesUntilSuccess(t *testing.T) {
+	q, store, cleanup := newTestingQueue(t)
+	defer cleanup()
+
+	var (
+		mu    sync.Mutex
+		calls int
+	)
+	handler := q.Handler(testingModuleId, http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		if string(body) != "payload" {
+			t.Errorf("unexpected body: %q", body)
+		}
+		if r.URL.Query().Get("project") != "1" || r.Header.Get("X-GitHub-Event") != "issues" {
+			t.Errorf("request not restored properly: %v %v", r.URL, r.Header)
+		}
+		if r.URL.Query().Get("secret") != "" || r.Header.Get("X-Hub-Signature-256") != "" {
+			t.Errorf("credentials not removed: %v %v", r.URL, r.Header)
+		}
+
+		mu.Lock()
+		calls++
+		n := calls
+		mu.Unlock()
+
+		if n == 1 {
+			http.Error(rw, "try again", http.StatusInternalServerError)
+			return
+		}
+		rw.WriteHeader(http.StatusAccepted)
+	}))
+
+	if err := q.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer q.Stop()
+
+	id := postDelivery(t, handler, "payload")
+	delivery := waitForStatus(t, store, id, StatusDone)
+
+	if delivery.Attempts != 2 {
+		t.Errorf("expected 2 attempts, got %v", delivery.Attempts)
+	}
+	if delivery.StatusCode != http.StatusAccepted {
+		t.Errorf("expected status code 202, got %v", delivery.StatusCode)
+	}
+}
+
+func TestQueue_DeadAfterMaxAttempts(t *testing.T) {
+	q, store, cleanup := newTestingQueue(t)
+	defer cleanup()
+
+	handler := q.Handler(testingModuleId, http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	}))
+
+	if err := q.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer q.Stop()
+
+	id := postDelivery(t, handler, "payload")
+	delivery := waitForStatus(t, store, id, StatusDead)
+
+	if delivery.Attempts != 3 {
+		t.Errorf("expected 3 attempts, got %v", delivery.Attempts)
+	}
+	if delivery.Outcome != "panic: boom" {
+		t.Errorf("unexpected outcome: %q", delivery.Outcome)
+	}
+}
+
+func TestQueue_ClientErrorsAreNotRetried(t *testing.T) {
+	q, store, cleanup := newTestingQueue(t)
+	defer cleanup()
+
+	handler := q.Handler(testingModuleId, http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		http.Error(rw, "Unauthorized", http.StatusUnauthorized)
+	}))
+
+	if err := q.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer q.Stop()
+
+	id := postDelivery(t, handler, "payload")
+	delivery := waitForStatus(t, store, id, StatusFailed)
+
+	if delivery.Attempts != 1 {
+		t.Errorf("expected 1 attempt, got %v", delivery.Attempts)
+	}
+	if delivery.Outcome != "Unauthorized" {
+		t.Errorf("unexpected outcome: %q", delivery.Outcome)
+	}
+}
+
+func TestQueue_ResumesPendingDeliveries(t *testing.T) {
+	q, store, cleanup := newTestingQueue(t)
+	defer cleanup()
+
+	// Store a delivery the way a previous instance would leave it behind.
+	now := time.Now()
+	delivery := &Delivery{
+		Id:         "00000000000000000001-abcdef00",
+		ModuleId:   testingModuleId,
+		Method:     "POST",
+		Path:       "/events",
+		Body:       []byte("payload"),
+		Status:     StatusProcessing,
+		Attempts:   1,
+		ReceivedAt: now,
+		UpdatedAt:  now,
+	}
+	if err := store.Save(delivery); err != nil {
+		t.Fatal(err)
+	}
+
+	q.Handler(testingModuleId, http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.WriteHeader(http.StatusAccepted)
+	}))
+
+	if err := q.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer q.Stop()
+
+	delivery = waitForStatus(t, store, delivery.Id, StatusDone)
+	if delivery.Attempts != 2 {
+		t.Errorf("expected 2 attempts, got %v", delivery.Attempts)
+	}
+}
+
+func TestQueue_ShutdownTimeout(t *testing.T) {
+	q, _, cleanup := newTestingQueue(t)
+	defer cleanup()
+
+	var (
+		started = make(chan struct{})
+		release = make(chan struct{})
+	)
+	handler := q.Handler(testingModuleId, http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+		rw.WriteHeader(http.StatusAccepted)
+	}))
+	defer close(release)
+
+	if err := q.Start(); err != nil {
+		t.Fatal(err)
+	}
+
+	id := postDelivery(t, handler, "payload")
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	err := q.Shutdown(ctx)
+	timeoutErr, ok := err.(*ErrShutdownTimeout)
+	if !ok {
+		t.Fatalf("expected ErrShutdownTimeout, got %v", err)
+	}
+	if len(timeoutErr.InFlight) != 1 || timeoutErr.InFlight[0].Id != id {
+		t.Errorf("expected delivery %v to be reported as cut off, got %v", id, timeoutErr)
+	}
+}
+
+func TestQueue_ShutdownTwice(t *testing.T) {
+	q, _, cleanup := newTestingQueue(t)
+	defer cleanup()
+
+	if err := q.Start(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := q.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if err := q.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	q.Stop()
+}
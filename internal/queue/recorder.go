@@ -0,0 +1,65 @@
+package queue
+
+import (
+	// Stdlib
+	"bytes"
+	"net/http"
+	"strings"
+)
+
+// maxOutcomeSize limits how much of the response body is kept as the outcome.
+const maxOutcomeSize = 4096
+
+// recorder is a http.ResponseWriter that records the response
+// written by a module handler when processing a delivery.
+type recorder struct {
+	header   http.Header
+	status   int
+	body     bytes.Buffer
+	panicked string
+}
+
+func newRecorder() *recorder {
+	return &recorder{header: make(http.Header)}
+}
+
+func (rec *recorder) Header() http.Header {
+	return rec.header
+}
+
+func (rec *recorder) WriteHeader(status int) {
+	if rec.status == 0 {
+		rec.status = status
+	}
+}
+
+func (rec *recorder) Write(p []byte) (int, error) {
+	if rec.status == 0 {
+		rec.status = http.StatusOK
+	}
+	if n := maxOutcomeSize - rec.body.Len(); n > 0 {
+		if len(p) < n {
+			n = len(p)
+		}
+		rec.body.Write(p[:n])
+	}
+	return len(p), nil
+}
+
+func (rec *recorder) statusCode() int {
+	switch {
+	case rec.panicked != "":
+		return http.StatusInternalServerError
+	case rec.status == 0:
+		return http.StatusOK
+	default:
+		return rec.status
+	}
+}
+
+func (rec *recorder) outcome() string {
+	if rec.panicked != "" {
+		return rec.panicked
+	}
+	return strings.TrimSpace(rec.body.String())
+}
@@ -0,0 +1,203 @@
+package queue
+
+import (
+	// Stdlib
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+var (
+	// ErrNotFound is returned by Store.Load when there is no such delivery.
+	ErrNotFound = errors.New("delivery not found")
+
+	// ErrInvalidId is returned when the module ID or the delivery ID is malformed.
+	ErrInvalidId = errors.New("invalid module ID or delivery ID")
+)
+
+// Store is the persistence layer used by Queue.
+type Store interface {
+
+	// Save stores the given delivery, overwriting the previous version.
+	Save(delivery *Delivery) error
+
+	// Load returns the delivery with the given ID.
+	Load(moduleId, id string) (*Delivery, error)
+
+	// List returns all deliveries for the given module, oldest first.
+	List(moduleId string) ([]*Delivery, error)
+
+	// Delete removes the given delivery.
+	Delete(moduleId, id string) error
+
+	// ModuleIds returns the IDs of the modules that have any deliveries stored.
+	ModuleIds() ([]string, error)
+}
+
+// fileStore is a Store that keeps every delivery in a separate JSON file.
+// Files are written into a temporary file first and then renamed,
+// so a crash never leaves a half-written delivery behind.
+type fileStore struct {
+	dir string
+	mu  sync.RWMutex
+}
+
+// NewFileStore returns a Store that keeps the deliveries in the given directory.
+func NewFileStore(dir string) (Store, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	return &fileStore{dir: dir}, nil
+}
+
+// Module and delivery IDs are used as path components, hence the restriction.
+var safeIdRegexp = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9._-]*$`)
+
+func (store *fileStore) path(moduleId, id string) (string, error) {
+	if !safeIdRegexp.MatchString(moduleId) || !safeIdRegexp.MatchString(id) {
+		return "", ErrInvalidId
+	}
+	return filepath.Join(store.dir, moduleId, id+".json"), nil
+}
+
+func (store *fileStore) Save(delivery *Delivery) error {
+	path, err := store.path(delivery.ModuleId, delivery.Id)
+	if err != nil {
+		return err
+	}
+
+	content, err := json.Marshal(delivery)
+	if err != nil {
+		return err
+	}
+
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+
+	tmp, err := ioutil.TempFile(filepath.Dir(path), ".tmp-")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(content); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+func (store *fileStore) Load(moduleId, id string) (*Delivery, error) {
+	path, err := store.path(moduleId, id)
+	if err != nil {
+		return nil, err
+	}
+
+	store.mu.RLock()
+	defer store.mu.RUnlock()
+
+	return readDelivery(path)
+}
+
+func (store *fileStore) List(moduleId string) ([]*Delivery, error) {
+	if !safeIdRegexp.MatchString(moduleId) {
+		return nil, ErrInvalidId
+	}
+
+	store.mu.RLock()
+	defer store.mu.RUnlock()
+
+	infos, err := ioutil.ReadDir(filepath.Join(store.dir, moduleId))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	// ReadDir returns the entries sorted by name,
+	// which means that the deliveries are sorted chronologically.
+	deliveries := make([]*Delivery, 0, len(infos))
+	for _, info := range infos {
+		name := info.Name()
+		if info.IsDir() || strings.HasPrefix(name, ".") || !strings.HasSuffix(name, ".json") {
+			continue
+		}
+
+		delivery, err := readDelivery(filepath.Join(store.dir, moduleId, name))
+		if err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, delivery)
+	}
+	return deliveries, nil
+}
+
+func (store *fileStore) Delete(moduleId, id string) error {
+	path, err := store.path(moduleId, id)
+	if err != nil {
+		return err
+	}
+
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+func (store *fileStore) ModuleIds() ([]string, error) {
+	store.mu.RLock()
+	defer store.mu.RUnlock()
+
+	infos, err := ioutil.ReadDir(store.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, 0, len(infos))
+	for _, info := range infos {
+		if info.IsDir() && safeIdRegexp.MatchString(info.Name()) {
+			ids = append(ids, info.Name())
+		}
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
+
+func readDelivery(path string) (*Delivery, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	var delivery Delivery
+	if err := json.Unmarshal(content, &delivery); err != nil {
+		return nil, fmt.Errorf("failed to parse %v: %v", path, err)
+	}
+	return &delivery, nil
+}
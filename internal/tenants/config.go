@@ -0,0 +1,37 @@
+package tenants
+
+import (
+	// Stdlib
+	"log"
+
+	// Vendor
+	"github.com/kelseyhightower/envconfig"
+)
+
+type Config struct {
+	// File is the path to the JSON file defining the tenants.
+	// The daemon runs in the single-tenant mode when not set.
+	File string `envconfig:"FILE"`
+}
+
+var config Config
+
+func init() {
+	if err := envconfig.Process("SFD_TENANTS", &config); err != nil {
+		log.Fatalln("Fatal error while parsing tenants config:", err)
+	}
+
+	if config.File == "" {
+		return
+	}
+
+	r, err := LoadFile(config.File)
+	if err != nil {
+		log.Fatalln("Fatal error while loading tenants:", err)
+	}
+	registry = r
+}
+
+func GetConfig() Config {
+	return config
+}
@@ -0,0 +1,230 @@
+// Package tenants makes it possible for a single daemon instance
+// to serve several organizations, each with its own credentials.
+//
+// The tenants are defined in a JSON file:
+//
+//	{
+//	  "tenants": [
+//	    {
+//	      "id": "acme",
+//	      "github": {
+//	        "owners": ["acme", "acme-labs"],
+//	        "token": "...",
+//	        "webhook_secret": "..."
+//	      },
+//	      "pivotaltracker": {
+//	        "project_ids": [123456],
+//	        "token": "...",
+//	        "webhook_secret": "..."
+//	      },
+//	      "labels": {
+//	        "salsaflow.modules.issuetracking.github": {"story_labels": ["feature", "bug"]},
+//	        "salsaflow.modules.codereview.github": {"review_issue_label": "code review"},
+//	        "pivotaltracker": {"reviewed": "code reviewed"}
+//	      }
+//	    }
+//	  ]
+//	}
+//
+// A tenant is selected by the repository owner or the Pivotal Tracker project ID.
+// The environment configuration is used for anything not covered by a tenant.
+// The webhooks sent for the owners or projects not belonging to any tenant
+// are rejected once there are tenants configured for the service.
+package tenants
+
+import (
+	// Stdlib
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"strings"
+)
+
+type Tenant struct {
+	Id             string          `json:"id"`
+	GitHub         *GitHub         `json:"github"`
+	PivotalTracker *PivotalTracker `json:"pivotaltracker"`
+
+	// Labels contains the label scheme overrides, the sections have
+	// the same format as the sections in .salsaflow/config.json.
+	Labels map[string]json.RawMessage `json:"labels"`
+}
+
+type GitHub struct {
+	Owners        []string `json:"owners"`
+	Token         string   `json:"token"`
+	WebhookSecret string   `json:"webhook_secret"`
+
+	// WebhookSecrets can be used to accept several secrets at once,
+	// which is handy when the secrets are being rotated.
+	WebhookSecrets []string `json:"webhook_secrets"`
+}
+
+// Secrets returns all webhook secrets accepted for the tenant.
+func (gh *GitHub) Secrets() []string {
+	return joinSecrets(gh.WebhookSecret, gh.WebhookSecrets)
+}
+
+type PivotalTracker struct {
+	ProjectIds    []int  `json:"project_ids"`
+	Token         string `json:"token"`
+	WebhookSecret string `json:"webhook_secret"`
+
+	// WebhookSecrets can be used to accept several secrets at once,
+	// which is handy when the secrets are being rotated.
+	WebhookSecrets []string `json:"webhook_secrets"`
+}
+
+// Secrets returns all webhook secrets accepted for the tenant.
+func (pt *PivotalTracker) Secrets() []string {
+	return joinSecrets(pt.WebhookSecret, pt.WebhookSecrets)
+}
+
+func joinSecrets(secret string, secrets []string) []string {
+	var all []string
+	if secret != "" {
+		all = append(all, secret)
+	}
+	for _, s := range secrets {
+		if s != "" {
+			all = append(all, s)
+		}
+	}
+	return all
+}
+
+// Section decodes the label scheme section of the given key into v.
+// ok is false when the tenant is nil or the section is missing.
+func (t *Tenant) Section(key string, v interface{}) (ok bool, err error) {
+	if t == nil {
+		return false, nil
+	}
+	raw, ok := t.Labels[key]
+	if !ok {
+		return false, nil
+	}
+	if err := json.Unmarshal(raw, v); err != nil {
+		return false, fmt.Errorf("tenant %v: invalid labels section %v: %v", t.Id, key, err)
+	}
+	return true, nil
+}
+
+// Registry ---------------------------------------------------------------------
+
+type Registry struct {
+	tenants   []*Tenant
+	byOwner   map[string]*Tenant
+	byProject map[int]*Tenant
+}
+
+// NewRegistry indexes the given tenants. It fails in case
+// a repository owner or a project is assigned to multiple tenants.
+func NewRegistry(tenants []*Tenant) (*Registry, error) {
+	r := &Registry{
+		tenants:   tenants,
+		byOwner:   make(map[string]*Tenant),
+		byProject: make(map[int]*Tenant),
+	}
+
+	for _, t := range tenants {
+		if t.Id == "" {
+			return nil, fmt.Errorf("tenant ID not set")
+		}
+
+		if t.GitHub != nil {
+			for _, owner := range t.GitHub.Owners {
+				key := strings.ToLower(owner)
+				if other, ok := r.byOwner[key]; ok {
+					return nil, fmt.Errorf(
+						"GitHub owner %v assigned to tenants %v and %v", owner, other.Id, t.Id)
+				}
+				r.byOwner[key] = t
+			}
+		}
+
+		if t.PivotalTracker != nil {
+			for _, pid := range t.PivotalTracker.ProjectIds {
+				if other, ok := r.byProject[pid]; ok {
+					return nil, fmt.Errorf(
+						"Pivotal Tracker project %v assigned to tenants %v and %v", pid, other.Id, t.Id)
+				}
+				r.byProject[pid] = t
+			}
+		}
+	}
+
+	return r, nil
+}
+
+// LoadFile reads the tenants definition file and returns the registry.
+func LoadFile(filename string) (*Registry, error) {
+	content, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	var file struct {
+		Tenants []*Tenant `json:"tenants"`
+	}
+	if err := json.Unmarshal(content, &file); err != nil {
+		return nil, fmt.Errorf("%v: %v", filename, err)
+	}
+
+	return NewRegistry(file.Tenants)
+}
+
+// All returns all the tenants.
+func (r *Registry) All() []*Tenant {
+	ts := make([]*Tenant, len(r.tenants))
+	copy(ts, r.tenants)
+	return ts
+}
+
+// ForGitHubOwner returns the tenant the given repository owner belongs to.
+// nil is returned when there is no such tenant.
+func (r *Registry) ForGitHubOwner(owner string) *Tenant {
+	return r.byOwner[strings.ToLower(owner)]
+}
+
+// ForPivotalTrackerProject returns the tenant the given project belongs to.
+// nil is returned when there is no such tenant.
+func (r *Registry) ForPivotalTrackerProject(projectId int) *Tenant {
+	return r.byProject[projectId]
+}
+
+// GitHubConfigured returns true when any of the tenants serves GitHub owners.
+// The webhooks sent for the owners not belonging to any tenant are then rejected.
+func (r *Registry) GitHubConfigured() bool {
+	return len(r.byOwner) != 0
+}
+
+// PivotalTrackerConfigured returns true when any of the tenants serves Pivotal Tracker projects.
+// The activities of the projects not belonging to any tenant are then rejected.
+func (r *Registry) PivotalTrackerConfigured() bool {
+	return len(r.byProject) != 0
+}
+
+// Default registry -------------------------------------------------------------
+
+// registry is replaced in init in case the tenants file is configured.
+var registry, _ = NewRegistry(nil)
+
+func All() []*Tenant {
+	return registry.All()
+}
+
+func ForGitHubOwner(owner string) *Tenant {
+	return registry.ForGitHubOwner(owner)
+}
+
+func ForPivotalTrackerProject(projectId int) *Tenant {
+	return registry.ForPivotalTrackerProject(projectId)
+}
+
+func GitHubConfigured() bool {
+	return registry.GitHubConfigured()
+}
+
+func PivotalTrackerConfigured() bool {
+	return registry.PivotalTrackerConfigured()
+}
@@ -0,0 +1,104 @@
+package tenants
+
+import (
+	// Stdlib
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const testingTenants = `{
+	"tenants": [
+		{
+			"id": "acme",
+			"github": {"owners": ["Acme", "acme-labs"], "token": "acme-token"},
+			"pivotaltracker": {"project_ids": [123], "token": "acme-pt-token"},
+			"labels": {
+				"salsaflow.modules.codereview.github": {"review_issue_label": "code review"}
+			}
+		},
+		{
+			"id": "initech",
+			"github": {"owners": ["initech"], "token": "initech-token"}
+		}
+	]
+}`
+
+func loadTestingRegistry(t *testing.T, content string) (*Registry, error) {
+	dir, err := ioutil.TempDir("", "tenants")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	filename := filepath.Join(dir, "tenants.json")
+	if err := ioutil.WriteFile(filename, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return LoadFile(filename)
+}
+
+func TestRegistry_Lookup(t *testing.T) {
+	r, err := loadTestingRegistry(t, testingTenants)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if tenant := r.ForGitHubOwner("acme"); tenant == nil || tenant.Id != "acme" {
+		t.Errorf("expected tenant acme for owner acme, got %+v", tenant)
+	}
+	if tenant := r.ForGitHubOwner("initech"); tenant == nil || tenant.Id != "initech" {
+		t.Errorf("expected tenant initech for owner initech, got %+v", tenant)
+	}
+	if tenant := r.ForGitHubOwner("globex"); tenant != nil {
+		t.Errorf("expected no tenant for owner globex, got %+v", tenant)
+	}
+	if tenant := r.ForPivotalTrackerProject(123); tenant == nil || tenant.Id != "acme" {
+		t.Errorf("expected tenant acme for project 123, got %+v", tenant)
+	}
+	if tenant := r.ForPivotalTrackerProject(456); tenant != nil {
+		t.Errorf("expected no tenant for project 456, got %+v", tenant)
+	}
+	if !r.GitHubConfigured() || !r.PivotalTrackerConfigured() {
+		t.Error("expected both GitHub and Pivotal Tracker tenants to be configured")
+	}
+
+	empty, _ := NewRegistry([]*Tenant{{Id: "empty"}})
+	if empty.GitHubConfigured() || empty.PivotalTrackerConfigured() {
+		t.Error("expected no GitHub or Pivotal Tracker tenants to be configured")
+	}
+}
+
+func TestRegistry_Duplicates(t *testing.T) {
+	_, err := loadTestingRegistry(t, `{
+		"tenants": [
+			{"id": "a", "github": {"owners": ["acme"]}},
+			{"id": "b", "github": {"owners": ["ACME"]}}
+		]
+	}`)
+	if err == nil {
+		t.Error("expected an error for an owner assigned to multiple tenants")
+	}
+}
+
+func TestTenant_Section(t *testing.T) {
+	r, err := loadTestingRegistry(t, testingTenants)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var section struct {
+		ReviewIssueLabel string `json:"review_issue_label"`
+	}
+
+	ok, err := r.ForGitHubOwner("acme").Section("salsaflow.modules.codereview.github", &section)
+	if err != nil || !ok || section.ReviewIssueLabel != "code review" {
+		t.Errorf("unexpected section: ok=%v, err=%v, section=%+v", ok, err, section)
+	}
+
+	// A missing tenant has no sections.
+	if ok, err := r.ForGitHubOwner("globex").Section("salsaflow.modules.codereview.github", &section); ok || err != nil {
+		t.Errorf("expected no section, got ok=%v, err=%v", ok, err)
+	}
+}
@@ -2,40 +2,127 @@ package main
 
 import (
 	// Stdlib
-	"log"
+	"errors"
+	stdLog "log"
 	"net/http"
 	"os"
+	"sync/atomic"
 
 	// Internal
+	"github.com/salsaflow/salsaflow-daemon/internal/admin"
+	"github.com/salsaflow/salsaflow-daemon/internal/github"
+	"github.com/salsaflow/salsaflow-daemon/internal/health"
+	"github.com/salsaflow/salsaflow-daemon/internal/log"
+	"github.com/salsaflow/salsaflow-daemon/internal/metrics"
 	"github.com/salsaflow/salsaflow-daemon/internal/modules/endpoints"
+	ptutil "github.com/salsaflow/salsaflow-daemon/internal/modules/issuetracking/pivotaltracker/util"
+	"github.com/salsaflow/salsaflow-daemon/internal/queue"
 
 	// Vendor
 	"github.com/codegangsta/negroni"
 )
 
 func main() {
+	// Set up the delivery queue.
+	queueConfig := queue.GetConfig()
+	store, err := queue.NewFileStore(queueConfig.Dir)
+	if err != nil {
+		stdLog.Fatalln("Failed to open the delivery store:", err)
+	}
+	q := queue.New(store, queueConfig)
+
 	// Register the module endpoints with the main mux.
+	// Every module handler is placed behind the delivery queue.
+	// The webhooks are authenticated before being queued,
+	// so unauthenticated requests are rejected right away and never stored.
 	var nuked bool
 	mux := http.NewServeMux()
 	for _, endpoint := range endpoints.Endpoints() {
+		auth, err := endpoint.NewAuthMiddleware()
+		if err != nil {
+			stdLog.Println(err)
+			nuked = true
+		}
+
 		handler, err := endpoint.NewHandler()
 		if err != nil {
-			log.Println(err)
+			stdLog.Println(err)
 			nuked = true
 		}
 
+		n := negroni.New()
+		if auth != nil {
+			n.Use(auth)
+		}
+		n.UseHandler(q.Handler(endpoint.ModuleId(), handler))
+
 		prefix := "/modules/" + endpoint.ModuleId()
-		mux.Handle(prefix+"/", http.StripPrefix(prefix, handler))
+		mux.Handle(prefix+"/", http.StripPrefix(prefix, n))
 	}
 	if nuked {
 		os.Exit(1)
 	}
 
-	// Set up Negroni and start listening.
+	// Register the health endpoints.
+	// The daemon is ready once all the module handlers are set up.
+	// The API credentials are checked as well, but invalid credentials only make
+	// the daemon degraded since they usually affect just one of the tenants.
+	var modulesReady int32
+	healthConfig := health.GetConfig()
+	checker := health.NewChecker(healthConfig.CheckTTL, healthConfig.CheckFailureTTL)
+	checker.Add("modules", func() error {
+		if atomic.LoadInt32(&modulesReady) == 0 {
+			return errors.New("module handlers not set up")
+		}
+		return nil
+	})
+	for name, check := range github.CredentialChecks() {
+		checker.AddNonCritical(name, check)
+	}
+	for name, check := range ptutil.CredentialChecks() {
+		checker.AddNonCritical(name, check)
+	}
+
+	mux.Handle("/healthz", health.LivenessHandler())
+	mux.Handle("/readyz", health.ReadinessHandler(checker))
+	mux.Handle("/version", health.VersionHandler())
+
+	// Export the metrics.
+	// All the API clients use http.DefaultClient, directly or as the base
+	// transport of the OAuth2 client, so instrumenting it covers all outbound calls.
+	http.DefaultClient.Transport = &metrics.Transport{Base: http.DefaultClient.Transport}
+	mux.Handle("/metrics", metrics.Handler())
+
+	// Register the admin API.
+	if token := admin.GetConfig().Token; token != "" {
+		mux.Handle("/admin/", admin.NewHandler(q, token))
+	} else {
+		stdLog.Println("WARNING: SFD_ADMIN_TOKEN is not set, admin API disabled")
+	}
+
+	// Start processing the deliveries.
+	if err := q.Start(); err != nil {
+		stdLog.Fatalln("Failed to start the delivery queue:", err)
+	}
+	atomic.StoreInt32(&modulesReady, 1)
+
+	// Set up Negroni.
+	tracker := newRequestTracker()
 	n := negroni.Classic()
+	n.Use(tracker)
+	n.Use(log.NewMiddleware())
 	n.Use(newRewriteObsoletePathsMiddleware())
 	n.UseHandler(mux)
-	n.Run(":" + os.Getenv("PORT"))
+
+	// Start listening, shut down gracefully on SIGINT or SIGTERM.
+	// The daemon is not reported as ready any more once the shutdown starts.
+	server := &http.Server{
+		Addr:    ":" + os.Getenv("PORT"),
+		Handler: n,
+	}
+	serve(server, tracker, q, config.ShutdownTimeout, func() {
+		atomic.StoreInt32(&modulesReady, 0)
+	})
 }
 
 func newRewriteObsoletePathsMiddleware() negroni.Handler {
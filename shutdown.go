@@ -0,0 +1,112 @@
+package main
+
+import (
+	// Stdlib
+	"context"
+	stdLog "log"
+	"net/http"
+	"os"
+	"os/signal"
+	"sort"
+	"sync"
+	"syscall"
+	"time"
+
+	// Internal
+	"github.com/salsaflow/salsaflow-daemon/internal/queue"
+)
+
+// requestTracker is a middleware keeping track of the requests being served,
+// so that we know what was cut off in case the shutdown times out.
+type requestTracker struct {
+	mu       sync.Mutex
+	next     int
+	requests map[int]string
+}
+
+func newRequestTracker() *requestTracker {
+	return &requestTracker{requests: make(map[int]string)}
+}
+
+func (tracker *requestTracker) ServeHTTP(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	tracker.mu.Lock()
+	id := tracker.next
+	tracker.next++
+	tracker.requests[id] = r.Method + " " + r.URL.Path
+	tracker.mu.Unlock()
+
+	defer func() {
+		tracker.mu.Lock()
+		delete(tracker.requests, id)
+		tracker.mu.Unlock()
+	}()
+
+	next(rw, r)
+}
+
+func (tracker *requestTracker) InFlight() []string {
+	tracker.mu.Lock()
+	defer tracker.mu.Unlock()
+
+	requests := make([]string, 0, len(tracker.requests))
+	for _, request := range tracker.requests {
+		requests = append(requests, request)
+	}
+	sort.Strings(requests)
+	return requests
+}
+
+// serve runs the server until SIGINT or SIGTERM is received.
+// Then it stops accepting new connections and waits for the requests
+// and the queued deliveries being processed, at most for the given timeout.
+// onShutdown is called as soon as the shutdown starts.
+func serve(
+	server *http.Server,
+	tracker *requestTracker,
+	q *queue.Queue,
+	timeout time.Duration,
+	onShutdown func(),
+) {
+	// Start listening.
+	serverErr := make(chan error, 1)
+	go func() {
+		stdLog.Printf("Listening on %v\n", server.Addr)
+		serverErr <- server.ListenAndServe()
+	}()
+
+	// Wait for a signal.
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+
+	select {
+	case err := <-serverErr:
+		stdLog.Fatalln("Server failed:", err)
+	case sig := <-signals:
+		stdLog.Printf("Received %v, shutting down (timeout %v)\n", sig, timeout)
+	}
+	onShutdown()
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	// Stop accepting connections and wait for the requests being served.
+	if err := server.Shutdown(ctx); err != nil {
+		stdLog.Println("WARNING: Failed to drain HTTP requests:", err)
+		for _, request := range tracker.InFlight() {
+			stdLog.Println("WARNING: Request cut off:", request)
+		}
+	}
+
+	// Wait for the deliveries being processed.
+	if err := q.Shutdown(ctx); err != nil {
+		stdLog.Println("WARNING: Failed to drain the delivery queue:", err)
+		if timeoutErr, ok := err.(*queue.ErrShutdownTimeout); ok {
+			for _, delivery := range timeoutErr.InFlight {
+				stdLog.Printf("WARNING: Delivery %v/%v cut off, it will be resumed on the next start\n",
+					delivery.ModuleId, delivery.Id)
+			}
+		}
+	}
+
+	stdLog.Println("Shutdown complete")
+}